@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math/rand"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// slotLine is one batting slot's average contribution per game.
+type slotLine struct {
+	Slot          int     `json:"slot"`
+	Name          string  `json:"name"`
+	RBI           float64 `json:"rbi"`
+	Runs          float64 `json:"runs"`
+	TimesOnBase   float64 `json:"times_on_base"`
+	ExtraBaseHits float64 `json:"extra_base_hits"`
+}
+
+// slotBreakdown replays lineup for games games with slot tracking on and
+// returns each slot's average RBI, runs, times on base and extra-base hits,
+// and the average runs per game that scored with no RBI, on wild pitches
+// and dropped third strikes. Every other run is credited to exactly one
+// RBI, so the slots' RBI plus noRBI sum to the replay's mean runs.
+func slotBreakdown(lineup []baseball.Player, cfg baseball.GameConfig, games int, r *rand.Rand) (lines []slotLine, noRBI float64) {
+	cfg.TrackSlots = true
+	totals := make([]baseball.SlotStats, len(lineup))
+	var unbatted int
+	for g := 0; g < games; g++ {
+		game := baseball.SimulateGame(lineup, cfg, r)
+		unbatted += game.NoRBI
+		for i, s := range game.Slots {
+			totals[i].RBI += s.RBI
+			totals[i].Runs += s.Runs
+			totals[i].TimesOnBase += s.TimesOnBase
+			totals[i].ExtraBaseHits += s.ExtraBaseHits
+		}
+	}
+	n := float64(games)
+	lines = make([]slotLine, len(lineup))
+	for i, t := range totals {
+		lines[i] = slotLine{
+			Slot:          i + 1,
+			Name:          lineup[i].FirstName + " " + lineup[i].LastName,
+			RBI:           float64(t.RBI) / n,
+			Runs:          float64(t.Runs) / n,
+			TimesOnBase:   float64(t.TimesOnBase) / n,
+			ExtraBaseHits: float64(t.ExtraBaseHits) / n,
+		}
+	}
+	return lines, float64(unbatted) / n
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestSlotRBIAddUpToRuns(t *testing.T) {
+	p := testPlayer("Hitter", 0.340, 0.450)
+	p.KRate = 0.22
+	lineup := nineOf(p)
+	cfg := baseball.DefaultGameConfig()
+	// Runs with no RBI, to be accounted for apart from the slots.
+	cfg.WildPitchRate, cfg.DroppedThirdStrike = 0.05, 0.3
+	const games = 400
+
+	res := lineupResult{Hash: lineupHash(lineup)}
+	res.Players, res.NoRBIRuns = slotBreakdown(lineup, cfg, games, rand.New(rand.NewSource(7)))
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got lineupResult
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same seed replays the same games.
+	cfg.TrackSlots = true
+	r := rand.New(rand.NewSource(7))
+	runs := 0
+	for g := 0; g < games; g++ {
+		runs += baseball.SimulateGame(lineup, cfg, r).Runs
+	}
+	mean := float64(runs) / games
+
+	rbi := got.NoRBIRuns
+	for _, l := range got.Players {
+		rbi += l.RBI
+	}
+	if got.NoRBIRuns == 0 {
+		t.Error("no runs scored without an RBI")
+	}
+	if len(got.Players) != 9 || math.Abs(rbi-mean) > 1e-9 {
+		t.Errorf("%d slots' RBI and %.3f unbatted runs sum to %.4f, want the mean %.4f", len(got.Players), got.NoRBIRuns, rbi, mean)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"sort"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// baselineResult compares the top lineup with a naive ordering.
+type baselineResult struct {
+	Method  string   `json:"method"`
+	Order   []string `json:"order"`
+	Mean    float64  `json:"mean"`
+	TopMean float64  `json:"top_mean"`
+	Gain    float64  `json:"gain"`
+	GainPct float64  `json:"gain_pct"`
+	// Wins is Gain as wins over a season, with -runs-per-win.
+	Wins float64 `json:"wins,omitempty"`
+}
+
+// baselineLineup returns the naive lineup for method: "file" is the first
+// -lineup-size players in file order, "obp" the best on-base players (LHP/RHP
+// weighted by lhpShare) sorted by OBP.
+func baselineLineup(players []baseball.Player, method string, lhpShare float64) []baseball.Player {
+	if method == "obp" {
+		players = append([]baseball.Player(nil), players...)
+		obp := func(p baseball.Player) float64 { return lhpShare*p.LHP.OBP + (1-lhpShare)*p.RHP.OBP }
+		sort.SliceStable(players, func(i, j int) bool { return obp(players[i]) > obp(players[j]) })
+	}
+	return players[:*lineupSize]
+}
+
+// compareBaseline replays base and top over the same games, seeded from the
+// baseline's hash, and reports top's gain over base.
+func compareBaseline(method string, base, top []baseball.Player, cfg baseball.GameConfig, games int) baselineResult {
+	seed := lineupRandSeed(lineupHash(base))
+	mean := func(lineup []baseball.Player) float64 {
+		return EvaluateLineup(lineup, cfg, games, seed).Mean
+	}
+	b := baselineResult{Method: method, Mean: mean(base), TopMean: mean(top)}
+	for _, p := range base {
+		b.Order = append(b.Order, p.LastName)
+	}
+	b.Gain = b.TopMean - b.Mean
+	b.Wins = seasonWins(b.Gain)
+	if b.Mean > 0 {
+		b.GainPct = 100 * b.Gain / b.Mean
+	}
+	return b
+}
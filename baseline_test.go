@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestCompareBaseline(t *testing.T) {
+	withInt(t, lineupSize, 9)
+	withInt64(t, seed, 3)
+	roster := testRoster(12)
+	// Reverse the file so its first nine are the weakest hitters.
+	for i, j := 0, len(roster)-1; i < j; i, j = i+1, j-1 {
+		roster[i], roster[j] = roster[j], roster[i]
+	}
+	base := baselineLineup(roster, "file", 0.3)
+	if base[0].LastName != "P12" || len(base) != 9 {
+		t.Fatalf("file baseline = %d players from %s, want nine from P12", len(base), base[0].LastName)
+	}
+	top := baselineLineup(roster, "obp", 0.3)
+	if top[0].LastName != "P1" || top[8].LastName != "P9" {
+		t.Fatalf("obp baseline runs %s to %s, want P1 to P9", top[0].LastName, top[8].LastName)
+	}
+
+	cfg := baseball.DefaultGameConfig()
+	b := compareBaseline("file", base, top, cfg, 400)
+	if again := compareBaseline("file", base, top, cfg, 400); again.Mean != b.Mean || again.TopMean != b.TopMean {
+		t.Errorf("baseline %v/%v, then %v/%v", b.Mean, b.TopMean, again.Mean, again.TopMean)
+	}
+	if b.Gain <= 0 || b.Gain != b.TopMean-b.Mean {
+		t.Errorf("gain %v from %v to %v", b.Gain, b.Mean, b.TopMean)
+	}
+	if want := 100 * b.Gain / b.Mean; math.Abs(b.GainPct-want) > 1e-9 {
+		t.Errorf("gain %.3f%%, want %.3f%%", b.GainPct, want)
+	}
+}
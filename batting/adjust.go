@@ -0,0 +1,80 @@
+package baseball
+
+import "math"
+
+// effectiveStats returns the split p bats with against the current pitcher
+// and base state, adjusted for the game's context.
+func (g *Game) effectiveStats(p *Player, cfg GameConfig) Stats {
+	risp := g.Field.SecondBase != nil || g.Field.ThirdBase != nil
+	s := p.SituationalSplit(g.PitcherHand, risp).shrunk(cfg)
+	if w := cfg.RecentWeight; w > 0 {
+		if recent := p.RecentSplit(g.PitcherHand); recent != nil {
+			s = s.blend(recent.shrunk(cfg), w)
+		}
+	}
+	if g.pitcher != nil {
+		s = g.pitcher.adjust(s)
+	}
+	if f := cfg.Model.StatScale; f > 0 && f != 1 {
+		s = s.scaled(f)
+	}
+	if e := cfg.RunEnvironment; e > 0 && e != 1 {
+		s = s.environment(e)
+	}
+	if g.Home && cfg.HomeFieldFactor > 0 && cfg.HomeFieldFactor != 1 {
+		s = s.scaled(cfg.HomeFieldFactor)
+	}
+	if f := cfg.MinWalkRate; f > 0 && s.OBP-s.AVG < f {
+		s.OBP = math.Min(1, s.AVG+f)
+	}
+	return s
+}
+
+// blend mixes weight w of recent into s. Each rate is a weighted mean of
+// two valid splits, so the result keeps AVG <= OBP <= 1. The season's
+// observed hit mix, if any, is kept.
+func (s Stats) blend(recent Stats, w float64) Stats {
+	s.AVG = (1-w)*s.AVG + w*recent.AVG
+	s.OBP = (1-w)*s.OBP + w*recent.OBP
+	s.SLUG = (1-w)*s.SLUG + w*recent.SLUG
+	return s
+}
+
+// shrunk regresses s toward cfg.ShrinkTo, or league average, by its sample
+// size; see GameConfig.ShrinkPA.
+func (s Stats) shrunk(cfg GameConfig) Stats {
+	k := cfg.ShrinkPA
+	if k <= 0 || s.PA <= 0 {
+		return s
+	}
+	to := Stats{AVG: LeagueAVG, OBP: LeagueOBP, SLUG: LeagueSLUG}
+	if cfg.ShrinkTo != nil {
+		to = *cfg.ShrinkTo
+	}
+	return s.blend(to, k/(float64(s.PA)+k))
+}
+
+// environment shifts s into a run environment e: AVG and OBP scale by e
+// and SLUG by e squared, so the extra-base share of hits scales by e too.
+// OBP is capped at 1 and AVG at OBP as in scaled.
+func (s Stats) environment(e float64) Stats {
+	slug := s.SLUG * e * e
+	s = s.scaled(e)
+	s.SLUG = slug
+	return s
+}
+
+// scaled multiplies AVG, OBP and SLUG by f, keeping OBP at most 1 and AVG at
+// most OBP so the outcome thresholds stay ordered.
+func (s Stats) scaled(f float64) Stats {
+	s.AVG *= f
+	s.OBP *= f
+	s.SLUG *= f
+	if s.OBP > 1 {
+		s.OBP = 1
+	}
+	if s.AVG > s.OBP {
+		s.AVG = s.OBP
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package baseball
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRecentWeightBlendsToMidpoint(t *testing.T) {
+	p := hitter("Streaky", 0.320, 0.400)
+	p.RecentRHP = &Stats{AVG: 0.310, OBP: 0.380, SLUG: 0.560}
+	cfg := DefaultGameConfig()
+	cfg.RecentWeight = 0.5
+	g := Game{PitcherHand: "right"}
+
+	got := g.effectiveStats(&p, cfg)
+	want := Stats{AVG: 0.280, OBP: 0.350, SLUG: 0.480}
+	if math.Abs(got.AVG-want.AVG) > 1e-12 || math.Abs(got.OBP-want.OBP) > 1e-12 || math.Abs(got.SLUG-want.SLUG) > 1e-12 {
+		t.Errorf("50/50 blend = %+v, want %+v", got, want)
+	}
+
+	// Against a lefty there's no recent split, so the season stands.
+	g.PitcherHand = "left"
+	if got := g.effectiveStats(&p, cfg); got != p.LHP {
+		t.Errorf("blend without a recent split = %+v, want %+v", got, p.LHP)
+	}
+}
+
+func TestMinWalkRateFloorsWalks(t *testing.T) {
+	p := hitter("Free", 0.300, 0.420)
+	p.LHP.OBP, p.RHP.OBP = p.LHP.AVG, p.RHP.AVG
+	lineup := nineOf(p)
+	cfg := DefaultGameConfig()
+	cfg.MinWalkRate = 0.05
+	r := rand.New(rand.NewSource(1))
+	walks, pa := 0, 0
+	for i := 0; i < 2000; i++ {
+		g := SimulateGame(lineup, cfg, r)
+		walks += g.Walks + g.HBP
+		pa += g.PA
+	}
+	if rate := float64(walks) / float64(pa); rate < 0.045 || rate > 0.055 {
+		t.Errorf("walked %.4f of %d plate appearances, want about 0.05", rate, pa)
+	}
+}
+
+func TestShrinkPAPullsThinSplits(t *testing.T) {
+	cfg := DefaultGameConfig()
+	cfg.ShrinkPA = 200
+	thin := Stats{AVG: 0.300, OBP: 0.380, SLUG: 0.800, PA: 40}
+	full := Stats{AVG: 0.300, OBP: 0.380, SLUG: 0.800, PA: 4000}
+
+	// 200/(40+200) of the way to league average for the thin split, about
+	// 5% for the full one.
+	if got, want := thin.shrunk(cfg).SLUG, 0.800+(LeagueSLUG-0.800)*200/240; math.Abs(got-want) > 1e-12 {
+		t.Errorf("40-PA SLUG .800 shrank to %.3f, want %.3f", got, want)
+	}
+	if got := full.shrunk(cfg).SLUG; got < 0.780 || got >= 0.800 {
+		t.Errorf("4000-PA SLUG .800 shrank to %.3f, want it barely moved", got)
+	}
+	unknown := thin
+	unknown.PA = 0
+	if unknown.shrunk(cfg) != unknown {
+		t.Error("a split without a PA count was shrunk")
+	}
+}
+
+func TestRISPSplitReachesMoreWithRunnerOnSecond(t *testing.T) {
+	p := hitter("Clutch", 0.300, 0.400)
+	p.RISPRHP = &Stats{AVG: 0.320, OBP: 0.420, SLUG: 0.500}
+	cfg := DefaultGameConfig()
+	reached := func(bases string) int {
+		g := Game{Field: fieldOf(bases), PitcherHand: "right"}
+		s := g.effectiveStats(&p, cfg)
+		r := rand.New(rand.NewSource(1))
+		n := 0
+		for i := 0; i < 10000; i++ {
+			if plateAppearance(s, p.Speed, cfg, r) != HIT_OUT {
+				n++
+			}
+		}
+		return n
+	}
+	if risp, empty := reached("2"), reached(""); risp <= empty {
+		t.Errorf("reached %d of 10000 with a runner on second, %d with the bases empty", risp, empty)
+	}
+}
@@ -0,0 +1,41 @@
+package baseball
+
+import "math/rand"
+
+// AutomaticOut stands in for a player who sits out a game with no backup
+// in GameConfig.Backups. With no hitting line, every plate appearance is an
+// out.
+var AutomaticOut = Player{LastName: "(automatic out)"}
+
+// available reports whether p plays a game, drawing from r only when
+// p.Availability is strictly between 0 and 1.
+func (p *Player) available(r *rand.Rand) bool {
+	if p.Availability <= 0 || p.Availability >= 1 {
+		return true
+	}
+	return r.Float64() < p.Availability
+}
+
+// sitOut returns lineup with every player who's unavailable this game
+// replaced by their backup in cfg.Backups, or AutomaticOut without one or
+// when the backup is already in the lineup,
+// logging each in g.Subs as an inning-0 substitution. It returns lineup
+// itself when everyone plays and a copy otherwise.
+func (g *Game) sitOut(lineup []Player, cfg GameConfig, r *rand.Rand) []Player {
+	out := lineup
+	for i := range lineup {
+		if lineup[i].available(r) {
+			continue
+		}
+		if &out[0] == &lineup[0] {
+			out = append([]Player(nil), lineup...)
+		}
+		in, ok := cfg.Backups[lineup[i].LastName]
+		if !ok || inLineup(out, in) {
+			in = AutomaticOut
+		}
+		g.Subs = append(g.Subs, Substitution{Slot: i, Out: lineup[i].LastName, In: in.LastName})
+		out[i] = in
+	}
+	return out
+}
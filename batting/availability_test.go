@@ -0,0 +1,31 @@
+package baseball
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHalfAvailablePlayerPlaysHalfTheGames(t *testing.T) {
+	lineup := nineOf(hitter("Starter", 0.330, 0.420))
+	lineup[4].Availability = 0.5
+	cfg := DefaultGameConfig()
+	cfg.Backups = map[string]Player{"Starter5": hitter("Backup", 0.300, 0.380)}
+	r := rand.New(rand.NewSource(1))
+	played := 0
+	const games = 1000
+	for i := 0; i < games; i++ {
+		g := SimulateGame(lineup, cfg, r)
+		switch {
+		case len(g.Subs) == 0:
+			played++
+		case len(g.Subs) != 1 || g.Subs[0] != (Substitution{Slot: 4, Out: "Starter5", In: "Backup"}):
+			t.Fatalf("game %d: substitutions %+v", i+1, g.Subs)
+		}
+	}
+	if played < 450 || played > 550 {
+		t.Errorf("a half-available player played %d of %d games", played, games)
+	}
+	if lineup[4].LastName != "Starter5" {
+		t.Error("sitting out changed the caller's lineup")
+	}
+}
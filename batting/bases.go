@@ -0,0 +1,79 @@
+package baseball
+
+import "fmt"
+
+// CheckBases turns on Field's base-state assertions: placing a runner on
+// an occupied base, or on a second base at once, panics where it happens
+// instead of silently losing a runner. The package's tests turn it on, as
+// does the CLI's -strict.
+var CheckBases bool
+
+// MinLineupSize is the fewest batters a lineup can have. With fewer, a
+// runner can still be on base when their turn comes round again: one on
+// third holds through a walk, a double play that erases the runner from
+// first, and two more walks, so a five-batter order would send them up while
+// they stand on third.
+const MinLineupSize = 6
+
+// checkLineup panics when lineup is shorter than MinLineupSize.
+func checkLineup(lineup []Player) {
+	if len(lineup) < MinLineupSize {
+		panic(fmt.Sprintf("baseball: a lineup needs at least %d batters, got %d", MinLineupSize, len(lineup)))
+	}
+}
+
+// base returns base n's slot (1-3).
+func (f *Field) base(n int) **Player {
+	switch n {
+	case 1:
+		return &f.FirstBase
+	case 2:
+		return &f.SecondBase
+	case 3:
+		return &f.ThirdBase
+	}
+	panic(fmt.Sprintf("baseball: no base %d", n))
+}
+
+// placeRunner puts p on base n (1-3). With CheckBases it panics if the
+// base is taken or p is already on another base, so a baserunning bug
+// fails where it happens instead of losing a runner.
+func (f *Field) placeRunner(n int, p *Player) {
+	b := f.base(n)
+	if CheckBases {
+		if *b != nil {
+			panic(fmt.Sprintf("baseball: placing a runner on occupied base %d (%v)", n, f))
+		}
+		for m := 1; m <= 3; m++ {
+			if m != n && p != nil && *f.base(m) == p {
+				panic(fmt.Sprintf("baseball: runner on base %d placed on base %d too", m, n))
+			}
+		}
+	}
+	*b = p
+}
+
+// clear empties base n (1-3) and returns the runner who was there, or nil.
+func (f *Field) clear(n int) *Player {
+	b := f.base(n)
+	p := *b
+	*b = nil
+	return p
+}
+
+// clearBases empties all three bases.
+func (f *Field) clearBases() {
+	for n := 1; n <= 3; n++ {
+		f.clear(n)
+	}
+}
+
+// onBase reports whether p is one of the runners.
+func (f *Field) onBase(p *Player) bool {
+	return f.FirstBase == p || f.SecondBase == p || f.ThirdBase == p
+}
+
+// moveRunner sends the runner on base from to base to.
+func (f *Field) moveRunner(from, to int) {
+	f.placeRunner(to, f.clear(from))
+}
@@ -0,0 +1,67 @@
+package baseball
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// The package's tests run with the base-state assertions on, so a
+// baserunning bug panics in the test that causes it.
+func init() {
+	CheckBases = true
+}
+
+// mustPanic fails t unless f panics.
+func mustPanic(t *testing.T, what string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s didn't panic", what)
+		}
+	}()
+	f()
+}
+
+func TestPlaceRunnerOnAnOccupiedBasePanics(t *testing.T) {
+	f := fieldOf("1")
+	mustPanic(t, "placing a runner on occupied first", func() { f.placeRunner(1, &onSecond) })
+}
+
+func TestPlaceRunnerOnTwoBasesPanics(t *testing.T) {
+	f := fieldOf("1")
+	mustPanic(t, "placing the runner on first on second too", func() { f.placeRunner(2, &onFirst) })
+}
+
+func TestPlaceRunnerChecksOnlyWhenAsked(t *testing.T) {
+	defer func(on bool) { CheckBases = on }(CheckBases)
+	CheckBases = false
+	f := fieldOf("1")
+	f.placeRunner(2, &onFirst)
+	if f.SecondBase != &onFirst {
+		t.Errorf("second base = %v, want the runner from first", f.SecondBase)
+	}
+}
+
+func TestShortLineupPanics(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))[:MinLineupSize-1]
+	cfg := DefaultGameConfig()
+	r := rand.New(rand.NewSource(1))
+	mustPanic(t, "an inning with a short lineup", func() {
+		var g Game
+		SimulateInning(&g, lineup, 0, cfg, r)
+	})
+	mustPanic(t, "a game with a short lineup", func() { SimulateGame(lineup, cfg, r) })
+}
+
+func TestAdvanceAllMovesEveryRunner(t *testing.T) {
+	f := fieldOf("13")
+	if runs := f.AdvanceAll(1); runs != 1 {
+		t.Errorf("a single with runners on first and third scored %d, want 1", runs)
+	}
+	if got := occupants(f); got != "Batter First -" {
+		t.Errorf("after a single the bases are %q, want %q", got, "Batter First -")
+	}
+	if f.AtBat != nil {
+		t.Errorf("the batter is still up after reaching: %v", f.AtBat)
+	}
+}
@@ -0,0 +1,39 @@
+package baseball
+
+import (
+	"math"
+	"math/rand"
+)
+
+// chooseWeighted draws an index of weights with probability proportional to
+// its weight. Weights that sum to 1 are used as is, so callers that already
+// have probabilities get exactly one uniform draw walked down the list;
+// anything else is normalized. Negative weights count as zero. It panics if
+// no weight is positive.
+func chooseWeighted(r *rand.Rand, weights []float64) int {
+	total, last := 0.0, -1
+	for i, w := range weights {
+		if w > 0 {
+			total += w
+			last = i
+		}
+	}
+	if last < 0 {
+		panic("chooseWeighted: no positive weight")
+	}
+	u := r.Float64()
+	if math.Abs(total-1) > 1e-9 {
+		u *= total
+	}
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if u < w {
+			return i
+		}
+		u -= w
+	}
+	// Rounding left u just past the end.
+	return last
+}
@@ -0,0 +1,39 @@
+package baseball
+
+import "testing"
+
+func TestChooseWeighted(t *testing.T) {
+	for _, tc := range []struct {
+		weights []float64
+		draw    float64
+		want    int
+	}{
+		// Probabilities are walked as given; a draw on a boundary belongs
+		// to the next positive weight.
+		{[]float64{0.25, 0, 0.5, 0.25}, 0, 0},
+		{[]float64{0.25, 0, 0.5, 0.25}, 0.2499, 0},
+		{[]float64{0.25, 0, 0.5, 0.25}, 0.25, 2},
+		{[]float64{0.25, 0, 0.5, 0.25}, 0.75, 3},
+		{[]float64{0.25, 0, 0.5, 0.25}, 0.9999, 3},
+		// Anything else is normalized: 1 and 3 are a quarter and three.
+		{[]float64{1, 3}, 0.2499, 0},
+		{[]float64{1, 3}, 0.25, 1},
+		{[]float64{-2, 1, 3}, 0.1, 1},
+		// A single positive weight always wins.
+		{[]float64{0, 5, -1}, 0, 1},
+		{[]float64{0, 5, -1}, 0.9999, 1},
+	} {
+		if got := chooseWeighted(NewScripted(tc.draw), tc.weights); got != tc.want {
+			t.Errorf("chooseWeighted(%v) with draw %v = %d, want %d", tc.weights, tc.draw, got, tc.want)
+		}
+	}
+}
+
+func TestChooseWeightedNeedsAPositiveWeight(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic with every weight zero or negative")
+		}
+	}()
+	chooseWeighted(NewScripted(0.5), []float64{0, -1})
+}
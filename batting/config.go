@@ -0,0 +1,383 @@
+package baseball
+
+import "fmt"
+
+// GameConfig holds the rule and model parameters for a simulated game.
+type GameConfig struct {
+	// OutsPerInning ends a half-inning once this many outs are recorded.
+	OutsPerInning int
+	// PitcherHand fixes every pitcher to "left" or "right"; empty picks
+	// the starter and any reliever at random.
+	PitcherHand string
+	// PitcherHandByInning scripts the pitcher's hand for each inning, the
+	// last entry holding for any innings after it. It overrides PitcherHand
+	// and the random starter and relievers; a named starter keeps their
+	// rates but throws with the scripted hand.
+	PitcherHandByInning []string
+	// MaxPitcherChanges caps the relievers MaybeChangePitcher brings in per
+	// game.
+	MaxPitcherChanges int
+	// GIDPRate is the chance an out with a runner on first and room for
+	// two more outs becomes a double play. Zero disables double plays.
+	GIDPRate float64
+	// HomeFieldFactor scales the home team's AVG, OBP and SLUG in a
+	// matchup. 1 is neutral.
+	HomeFieldFactor float64
+	// ProductiveOutRate is the chance an out that isn't a double play or the
+	// last of the inning moves the lead runner up a base when there's a
+	// runner in scoring position. A runner on third scores. Zero disables it.
+	ProductiveOutRate float64
+	// SecondToThirdOnOut is the chance an out moves a runner on second, with
+	// first and third empty and the inning not over, to third: a ground ball
+	// to the right side. It applies to outs ProductiveOutRate didn't already
+	// advance, never to strikeouts or double plays. Zero disables it.
+	SecondToThirdOnOut float64
+	// DroppedThirdStrike is the chance a strikeout, with first base open or
+	// two outs, is a dropped third strike on which the batter reaches first
+	// instead of being out. With two outs and first taken, the runners are
+	// forced up as on a walk. Zero disables it.
+	DroppedThirdStrike float64
+	// HBPShare is the fraction of non-hit times on base that are
+	// hit-by-pitches rather than walks.
+	HBPShare float64
+	// ScoreFromThirdOnSingle is the chance an unforced runner on third
+	// scores on a single; otherwise the runner holds. 1 always sends them.
+	ScoreFromThirdOnSingle float64
+	// ScoreFromSecondOnDouble is the chance a runner on second scores on a
+	// double; otherwise a good relay holds them at third. Only an unforced
+	// runner, with first base empty, can be held. 1 always sends them.
+	ScoreFromSecondOnDouble float64
+	// OutStretching is the chance a double is really a single with the
+	// batter thrown out trying for second: the runners still advance as on
+	// a double, but the batter is out and second base stays empty. Zero
+	// disables it.
+	OutStretching float64
+	// ScoreFromFirstOnSingle is the chance a faster-than-average runner on
+	// first, with nobody on second or third, scores on a single, scaled up
+	// with sprint speed the way triples are and by the runner's aggression.
+	// Slower runners and those of unknown speed never try. Zero disables it.
+	ScoreFromFirstOnSingle float64
+	// ExtraBaseOnThrow is the chance that, when a single or double scores a
+	// run, the trailing runner takes one more base on the throw home: a
+	// runner stopping at second goes to third, or the batter stopping at
+	// first goes to second. Only one runner moves, and only to an empty
+	// base. Zero disables it.
+	ExtraBaseOnThrow float64
+	// ExtraInningRunner puts the batter due up last on this base (1-3) to
+	// start each extra half-inning in SimulateMatchup. Zero disables it.
+	ExtraInningRunner int
+	// MaxExtraInnings calls a matchup still tied after this many extra
+	// innings a tie. Zero plays on until someone wins.
+	MaxExtraInnings int
+	// ExtraInningRunnerHalves picks which halves get one: "both" (or
+	// empty), "top" or "bottom".
+	ExtraInningRunnerHalves string
+	// WildPitchRate is the chance of a wild pitch before each plate
+	// appearance with a runner on; every runner with an open base ahead
+	// moves up one. Zero disables them.
+	WildPitchRate float64
+	// ScoreFromThirdOnWildPitch is the chance the runner on third scores on
+	// a wild pitch; otherwise the catcher blocks it and they hold.
+	ScoreFromThirdOnWildPitch float64
+	// IntentionalWalk puts dangerous hitters on late in close games; off by
+	// default.
+	IntentionalWalk IntentionalWalk
+	// Steals is the running game on the bases; the zero value never runs.
+	Steals StealModel
+	// InfieldIn draws the infield in late in close games; off by default.
+	InfieldIn InfieldIn
+	// MinWalkRate is the least OBP - AVG gap any batter gets, raising OBP
+	// to cover it, so a split whose OBP barely clears its AVG (often thin
+	// data) still walks. Zero leaves the splits alone.
+	MinWalkRate float64
+	// RunEnvironment is one dial for the league's scoring level: it scales
+	// every batter's chance of reaching base and, more steeply, of getting
+	// extra bases. 1 (or zero) is neutral; runs per game move roughly with
+	// its cube, so 0.9 plays about 30% lower and 1.1 about 30% higher.
+	RunEnvironment float64
+	// RecentWeight blends each player's recent splits, where they have
+	// them, into their season splits: 0 uses the season only, 1 recent form
+	// only.
+	RecentWeight float64
+	// ShrinkPA regresses each split with a known PA toward ShrinkTo (league
+	// average when nil), keeping PA / (PA + ShrinkPA) of the split's own
+	// rates: a split of ShrinkPA plate appearances lands halfway. Zero
+	// leaves the splits alone.
+	ShrinkPA float64
+	ShrinkTo *Stats
+	// OpponentDefense scales the chance a ball in play (anything but a
+	// walk, HBP or home run) falls for a hit: 0.95 is a defense that takes
+	// away one in twenty such hits, 1.05 one that allows 5% more, all of
+	// them singles. 1 (or zero) is neutral. Both teams face it in matchups.
+	OpponentDefense float64
+	// PinchHits sends bench players up in SimulateGame; each is used at
+	// most once a game and the player stays in the lineup afterward.
+	// Matchups ignore them.
+	PinchHits []PinchHit
+	// DoubleSwitches reorder the lineup between innings in SimulateGame,
+	// each bringing in a player who bats in a different slot from the one
+	// leaving. Matchups ignore them.
+	DoubleSwitches []DoubleSwitch
+	// PinchRunning replaces slow runners late in close games in
+	// SimulateGame; off while its Runners is empty.
+	PinchRunning PinchRunning
+	// FirstInning, when set, starts SimulateGame's first inning with its
+	// outs already recorded and its runners on base, for "one out, runner
+	// on second" questions; its inning, half and score are ignored.
+	// Matchups resume from a state with SimulateMatchupFrom instead.
+	FirstInning *GameState
+	// Backups, keyed by last name, bat for players who sit out a game by
+	// their Availability; one without a backup, or whose backup is already
+	// batting, is an AutomaticOut.
+	Backups map[string]Player
+	// Model is the engine's calibration; the zero value is DefaultModel.
+	Model Model
+	// Park scales the extra-base share of hits.
+	Park ParkFactors
+	// HomeStarter and AwayStarter are each team's starting pitcher in
+	// SimulateMatchup, pitching the whole game. Nil is a league-average
+	// staff with a random starter hand and a possible reliever.
+	HomeStarter, AwayStarter *Pitcher
+	// TrackSlots records per-slot batting lines in Game.Slots and the runs
+	// per inning in Game.LineScore. It costs time, so leave it off for searches.
+	TrackSlots bool
+	// OutcomeOverride, when set, is asked before each plate appearance with
+	// the 0-based slot and inning; if it returns true, its outcome is used
+	// instead of the random draw (and any intentional walk). It's for
+	// scripting exact games in tests. Baserunning still draws from the
+	// game's source. An invalid outcome panics in Hit.
+	OutcomeOverride func(slot, inning int) (PlateOutcome, bool)
+	// Trace, when set, is called after every plate appearance.
+	Trace func(Play)
+	// RunCap is a sanity check, not a rule: when an inning scores more than
+	// RunCap runs, OverRunCap is called with the inning and its plays, to
+	// surface baserunning bugs or suspect data. Zero or a nil OverRunCap
+	// disables it.
+	RunCap     int
+	OverRunCap func(inning int, plays []Play)
+}
+
+// DefaultGameConfig returns the standard nine-inning, three-out rules.
+func DefaultGameConfig() GameConfig {
+	return GameConfig{
+		OutsPerInning:             3,
+		MaxPitcherChanges:         1,
+		GIDPRate:                  0.11,
+		HomeFieldFactor:           1,
+		HBPShare:                  0.09,
+		ScoreFromThirdOnSingle:    1,
+		ScoreFromSecondOnDouble:   1,
+		ScoreFromThirdOnWildPitch: 1,
+		MaxExtraInnings:           15,
+		Park:                      NeutralPark,
+	}
+}
+
+// Validate reports the first invalid setting in cfg.
+func (cfg GameConfig) Validate() error {
+	if cfg.OutsPerInning < 1 {
+		return fmt.Errorf("outs per inning must be positive, got %d", cfg.OutsPerInning)
+	}
+	if cfg.MaxPitcherChanges < 0 {
+		return fmt.Errorf("max pitcher changes must not be negative, got %d", cfg.MaxPitcherChanges)
+	}
+	if cfg.GIDPRate < 0 || cfg.GIDPRate > 1 {
+		return fmt.Errorf("GIDP rate must be between 0 and 1, got %v", cfg.GIDPRate)
+	}
+	if cfg.ProductiveOutRate < 0 || cfg.ProductiveOutRate > 1 {
+		return fmt.Errorf("productive out rate must be between 0 and 1, got %v", cfg.ProductiveOutRate)
+	}
+	if cfg.SecondToThirdOnOut < 0 || cfg.SecondToThirdOnOut > 1 {
+		return fmt.Errorf("second-to-third-on-out probability must be between 0 and 1, got %v", cfg.SecondToThirdOnOut)
+	}
+	if cfg.DroppedThirdStrike < 0 || cfg.DroppedThirdStrike > 1 {
+		return fmt.Errorf("dropped-third-strike probability must be between 0 and 1, got %v", cfg.DroppedThirdStrike)
+	}
+	if cfg.HBPShare < 0 || cfg.HBPShare > 1 {
+		return fmt.Errorf("HBP share must be between 0 and 1, got %v", cfg.HBPShare)
+	}
+	if cfg.ScoreFromThirdOnSingle < 0 || cfg.ScoreFromThirdOnSingle > 1 {
+		return fmt.Errorf("score-from-third probability must be between 0 and 1, got %v", cfg.ScoreFromThirdOnSingle)
+	}
+	if cfg.ScoreFromSecondOnDouble < 0 || cfg.ScoreFromSecondOnDouble > 1 {
+		return fmt.Errorf("score-from-second-on-double probability must be between 0 and 1, got %v", cfg.ScoreFromSecondOnDouble)
+	}
+	if cfg.OutStretching < 0 || cfg.OutStretching > 1 {
+		return fmt.Errorf("out-stretching probability must be between 0 and 1, got %v", cfg.OutStretching)
+	}
+	if cfg.ScoreFromFirstOnSingle < 0 || cfg.ScoreFromFirstOnSingle > 1 {
+		return fmt.Errorf("score-from-first probability must be between 0 and 1, got %v", cfg.ScoreFromFirstOnSingle)
+	}
+	if cfg.ExtraBaseOnThrow < 0 || cfg.ExtraBaseOnThrow > 1 {
+		return fmt.Errorf("extra-base-on-throw probability must be between 0 and 1, got %v", cfg.ExtraBaseOnThrow)
+	}
+	if cfg.WildPitchRate < 0 || cfg.WildPitchRate > 1 || cfg.ScoreFromThirdOnWildPitch < 0 || cfg.ScoreFromThirdOnWildPitch > 1 {
+		return fmt.Errorf("wild pitch probabilities must be between 0 and 1, got %v and %v", cfg.WildPitchRate, cfg.ScoreFromThirdOnWildPitch)
+	}
+	if cfg.RunCap < 0 {
+		return fmt.Errorf("run cap must not be negative, got %d", cfg.RunCap)
+	}
+	if cfg.MaxExtraInnings < 0 {
+		return fmt.Errorf("max extra innings must not be negative, got %d", cfg.MaxExtraInnings)
+	}
+	if cfg.ExtraInningRunner != 0 && (cfg.ExtraInningRunner < 1 || cfg.ExtraInningRunner > 3) {
+		return fmt.Errorf("extra-inning runner base must be 1, 2 or 3, got %d", cfg.ExtraInningRunner)
+	}
+	switch cfg.ExtraInningRunnerHalves {
+	case "", "both", "top", "bottom":
+	default:
+		return fmt.Errorf(`extra-inning runner halves must be "both", "top" or "bottom", got %q`, cfg.ExtraInningRunnerHalves)
+	}
+	if cfg.Steals.AttemptRate < 0 || cfg.Steals.AttemptRate > 1 || cfg.Steals.SuccessRate < 0 || cfg.Steals.SuccessRate > 1 {
+		return fmt.Errorf("steal rates must be between 0 and 1, got %+v", cfg.Steals)
+	}
+	if in := cfg.InfieldIn; in.Enabled {
+		if in.ScoreFromThird < 0 || in.ScoreFromThird > 1 || in.SingleBoost < 0 || in.SingleBoost > 1 {
+			return fmt.Errorf("infield-in probabilities must be between 0 and 1, got %+v", in)
+		}
+	}
+	if cfg.MinWalkRate < 0 || cfg.MinWalkRate > 1 {
+		return fmt.Errorf("minimum walk rate must be between 0 and 1, got %v", cfg.MinWalkRate)
+	}
+	if cfg.RunEnvironment < 0 {
+		return fmt.Errorf("run environment must not be negative, got %v", cfg.RunEnvironment)
+	}
+	if cfg.RecentWeight < 0 || cfg.RecentWeight > 1 {
+		return fmt.Errorf("recent weight must be between 0 and 1, got %v", cfg.RecentWeight)
+	}
+	if cfg.OpponentDefense < 0 {
+		return fmt.Errorf("opponent defense must not be negative, got %v", cfg.OpponentDefense)
+	}
+	if cfg.ShrinkPA < 0 {
+		return fmt.Errorf("shrinkage plate appearances must not be negative, got %v", cfg.ShrinkPA)
+	}
+	if t := cfg.ShrinkTo; t != nil && !(t.AVG >= 0 && t.AVG <= t.OBP && t.OBP <= 1 && t.SLUG >= t.AVG) {
+		return fmt.Errorf("shrinkage target must have 0 <= AVG <= OBP <= 1 and SLUG >= AVG, got %+v", *t)
+	}
+	for _, ph := range cfg.PinchHits {
+		if ph.Inning < 1 || ph.Slot < 0 {
+			return fmt.Errorf("pinch hit for slot %d in inning %d is out of range", ph.Slot+1, ph.Inning)
+		}
+	}
+	for _, ds := range cfg.DoubleSwitches {
+		if ds.Inning < 1 || ds.Out < 0 || ds.Slot < 0 {
+			return fmt.Errorf("double switch out of slot %d into slot %d in inning %d is out of range", ds.Out+1, ds.Slot+1, ds.Inning)
+		}
+	}
+	if st := cfg.FirstInning; st != nil && (st.Outs < 0 || st.Outs >= cfg.OutsPerInning) {
+		return fmt.Errorf("first-inning outs must be between 0 and %d, got %d", cfg.OutsPerInning-1, st.Outs)
+	}
+	if pr := cfg.PinchRunning; len(pr.Runners) > 0 && (pr.FromInning < 1 || pr.MaxMargin < 0 || pr.MaxSpeed <= 0) {
+		return fmt.Errorf("pinch running needs a first inning of at least 1, a non-negative margin and a positive speed, got %+v", pr)
+	}
+	if err := cfg.Model.orDefault().Validate(); err != nil {
+		return err
+	}
+	if cfg.HomeFieldFactor <= 0 {
+		return fmt.Errorf("home field factor must be positive, got %v", cfg.HomeFieldFactor)
+	}
+	if cfg.Park.Double <= 0 || cfg.Park.Triple <= 0 || cfg.Park.HomeRun <= 0 {
+		return fmt.Errorf("park factors must be positive, got %+v", cfg.Park)
+	}
+	for _, p := range []*Pitcher{cfg.HomeStarter, cfg.AwayStarter} {
+		if p == nil {
+			continue
+		}
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+	for i, hand := range cfg.PitcherHandByInning {
+		if hand != "left" && hand != "right" {
+			return fmt.Errorf(`pitcher hand for inning %d must be "left" or "right", got %q`, i+1, hand)
+		}
+	}
+	switch cfg.PitcherHand {
+	case "", "left", "right":
+	default:
+		return fmt.Errorf(`pitcher hand must be "left" or "right", got %q`, cfg.PitcherHand)
+	}
+	return nil
+}
+
+// handForInning returns the scripted pitcher hand for inning.
+func (cfg GameConfig) handForInning(inning int) string {
+	if inning > len(cfg.PitcherHandByInning) {
+		return cfg.PitcherHandByInning[len(cfg.PitcherHandByInning)-1]
+	}
+	return cfg.PitcherHandByInning[inning-1]
+}
+
+// InfieldIn is the late-game defense against a runner on third with fewer
+// than two outs, or OutsPerInning-1 in a shorter or longer inning. While
+// it's on, the runner scores on a single with probability ScoreFromThird
+// instead of cfg.ScoreFromThirdOnSingle, and SingleBoost of the batter's
+// outs get through the drawn-in infield for singles.
+type InfieldIn struct {
+	Enabled bool
+	// FromInning is the first inning it's used in.
+	FromInning int
+	// MaxMargin is the largest lead either way that still counts as close.
+	// SimulateGame has no opponent score, so every late inning is close.
+	MaxMargin      int
+	ScoreFromThird float64
+	SingleBoost    float64
+}
+
+// IntentionalWalk is the late-game decision to put a slugger on first when
+// it's open and there's a runner in scoring position, setting up a force
+// and a double play. The batter is walked instead of batting.
+type IntentionalWalk struct {
+	Enabled bool
+	// FromInning is the first inning it's used in.
+	FromInning int
+	// MaxMargin is the largest lead either way that still counts as close,
+	// as for InfieldIn.
+	MaxMargin int
+	// MinSLUG is the batter's slugging, against the current pitcher, that
+	// earns a walk.
+	MinSLUG float64
+}
+
+// DefaultIntentionalWalk walks .500 sluggers from the 8th with the game
+// within a run.
+var DefaultIntentionalWalk = IntentionalWalk{Enabled: true, FromInning: 8, MaxMargin: 1, MinSLUG: 0.5}
+
+// DefaultInfieldIn plays the infield in from the 7th with the game within a
+// run.
+var DefaultInfieldIn = InfieldIn{Enabled: true, FromInning: 7, MaxMargin: 1, ScoreFromThird: 0.55, SingleBoost: 0.04}
+
+// ParkFactors multiply the share of hits that go for doubles, triples and
+// home runs; singles absorb the difference. 1 is neutral.
+type ParkFactors struct {
+	Double  float64 `json:"double"`
+	Triple  float64 `json:"triple"`
+	HomeRun float64 `json:"home_run"`
+}
+
+// NeutralPark leaves hit-type rates unchanged.
+var NeutralPark = ParkFactors{Double: 1, Triple: 1, HomeRun: 1}
+
+// apply scales the extra-base shares, clamping each to [0, 1] and scaling
+// them down together if they'd leave no room for singles. Unset (zero)
+// factors are treated as neutral.
+func (pf ParkFactors) apply(p2, p3, pHR float64) (float64, float64, float64) {
+	if pf == NeutralPark || pf == (ParkFactors{}) {
+		return p2, p3, pHR
+	}
+	scale := func(p, f float64) float64 {
+		if f <= 0 {
+			return p
+		}
+		p *= f
+		if p > 1 {
+			p = 1
+		}
+		return p
+	}
+	p2, p3, pHR = scale(p2, pf.Double), scale(p3, pf.Triple), scale(pHR, pf.HomeRun)
+	if sum := p2 + p3 + pHR; sum > 1 {
+		p2, p3, pHR = p2/sum, p3/sum, pHR/sum
+	}
+	return p2, p3, pHR
+}
@@ -0,0 +1,115 @@
+package baseball
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestParkFactorRaisesHomeRuns(t *testing.T) {
+	s := Stats{AVG: 0.270, OBP: 0.340, SLUG: 0.480}
+	neutral, hitters := DefaultGameConfig(), DefaultGameConfig()
+	hitters.Park = ParkFactors{Double: 1, Triple: 1, HomeRun: 1.5}
+
+	for _, cfg := range []GameConfig{neutral, hitters} {
+		mix := HitMix(s, 0, cfg)
+		if sum := mix[0] + mix[1] + mix[2] + mix[3]; math.Abs(sum-1) > 1e-12 {
+			t.Errorf("park %+v: hit mix %v sums to %v", cfg.Park, mix, sum)
+		}
+	}
+	if n, h := HitMix(s, 0, neutral)[3], HitMix(s, 0, hitters)[3]; h <= n {
+		t.Errorf("home run share %.3f in the park, %.3f in a neutral one", h, n)
+	}
+
+	homers := func(cfg GameConfig) int {
+		r := rand.New(rand.NewSource(1))
+		n := 0
+		for i := 0; i < 20000; i++ {
+			if plateAppearance(s, 0, cfg, r) == HIT_HOMERUN {
+				n++
+			}
+		}
+		return n
+	}
+	if n, h := homers(neutral), homers(hitters); h <= n {
+		t.Errorf("%d home runs in the park, %d in a neutral one", h, n)
+	}
+}
+
+func TestSpeedRaisesTriples(t *testing.T) {
+	s := Stats{AVG: 0.270, OBP: 0.340, SLUG: 0.430}
+	cfg := DefaultGameConfig()
+	const slow, fast = LeagueSprintSpeed - 2, LeagueSprintSpeed + 3
+	if sl, fa := HitMix(s, slow, cfg)[2], HitMix(s, fast, cfg)[2]; fa <= sl {
+		t.Errorf("triple share %.4f at %v ft/s, %.4f at %v", fa, fast, sl, slow)
+	}
+
+	triples := func(speed float64) int {
+		r := rand.New(rand.NewSource(1))
+		n := 0
+		for i := 0; i < 20000; i++ {
+			if plateAppearance(s, speed, cfg, r) == HIT_TRIPLE {
+				n++
+			}
+		}
+		return n
+	}
+	if sl, fa := triples(slow), triples(fast); fa <= sl {
+		t.Errorf("%d triples from the fast hitter, %d from the slow one", fa, sl)
+	}
+}
+
+func TestRunEnvironmentScalesScoring(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.320, 0.410))
+	runs := func(env float64) int {
+		cfg := DefaultGameConfig()
+		cfg.RunEnvironment = env
+		r := rand.New(rand.NewSource(1))
+		n := 0
+		for i := 0; i < 2000; i++ {
+			n += SimulateGame(lineup, cfg, r).Runs
+		}
+		return n
+	}
+	duel, slugfest := runs(0.7), runs(1.3)
+	if float64(slugfest) < 1.3*float64(duel) {
+		t.Errorf("%d runs in 2000 games at 1.3, %d at 0.7", slugfest, duel)
+	}
+}
+
+func TestObservedHitMixGivesDoubles(t *testing.T) {
+	s := Stats{AVG: 0.270, OBP: 0.340, SLUG: 0.430, Double: 0.7, Triple: 0.05, HomeRun: 0.05}
+	cfg := DefaultGameConfig()
+	r := rand.New(rand.NewSource(1))
+	counts := map[PlateOutcome]int{}
+	hits := 0
+	for i := 0; i < 20000; i++ {
+		o := plateAppearance(s, 0, cfg, r)
+		if o == HIT_SINGLE || o == HIT_DOUBLE || o == HIT_TRIPLE || o == HIT_HOMERUN {
+			counts[o]++
+			hits++
+		}
+	}
+	if share := float64(counts[HIT_DOUBLE]) / float64(hits); share < 0.67 || share > 0.73 {
+		t.Errorf("doubles were %.3f of %d hits (%v), want about 0.7", share, hits, counts)
+	}
+}
+
+func TestStrongDefenseLowersAVG(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	avg := func(defense float64) float64 {
+		cfg := DefaultGameConfig()
+		cfg.OpponentDefense = defense
+		r := rand.New(rand.NewSource(1))
+		hits, ab := 0, 0
+		for i := 0; i < 1000; i++ {
+			g := SimulateGame(lineup, cfg, r)
+			hits += g.Hits
+			ab += g.PA - g.Walks - g.HBP
+		}
+		return float64(hits) / float64(ab)
+	}
+	if strong, neutral := avg(0.9), avg(1); strong >= neutral-0.01 {
+		t.Errorf("team AVG %.3f against a strong defense, %.3f against a neutral one", strong, neutral)
+	}
+}
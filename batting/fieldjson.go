@@ -0,0 +1,45 @@
+package baseball
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// runnerName is how Field and Game marshal a runner: the player's full
+// name, or nil for an empty base.
+func runnerName(p *Player) *string {
+	if p == nil {
+		return nil
+	}
+	name := strings.TrimSpace(p.FirstName + " " + p.LastName)
+	return &name
+}
+
+// MarshalJSON encodes f with each base, and the batter, as the player's
+// name or null, rather than the whole Player: the bases point into the
+// lineup, so a full copy of every runner would be both verbose and stale.
+func (f Field) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		AtBat      *string `json:"at_bat"`
+		FirstBase  *string `json:"first_base"`
+		SecondBase *string `json:"second_base"`
+		ThirdBase  *string `json:"third_base"`
+	}{runnerName(f.AtBat), runnerName(f.FirstBase), runnerName(f.SecondBase), runnerName(f.ThirdBase)})
+}
+
+// MarshalJSON encodes g as a compact snapshot of the batting side: the
+// inning, outs and bases in progress, and its line so far.
+func (g Game) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Inning      int    `json:"inning"`
+		Home        bool   `json:"home,omitempty"`
+		Outs        int    `json:"outs"`
+		Bases       Field  `json:"bases"`
+		Runs        int    `json:"runs"`
+		Hits        int    `json:"hits"`
+		LOB         int    `json:"lob"`
+		PA          int    `json:"pa"`
+		PitcherHand string `json:"pitcher_hand,omitempty"`
+		LineScore   []int  `json:"line_score,omitempty"`
+	}{g.Inning, g.Home, g.Outs, g.Field, g.Runs, g.Hits, g.LOB, g.PA, g.PitcherHand, g.LineScore})
+}
@@ -0,0 +1,37 @@
+package baseball
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFieldJSONNamesRunners(t *testing.T) {
+	for _, tc := range []struct {
+		bases string
+		want  string
+	}{
+		{"123", `{"at_bat":"Batter","first_base":"First","second_base":"Second","third_base":"Third"}`},
+		{"", `{"at_bat":"Batter","first_base":null,"second_base":null,"third_base":null}`},
+		{"13", `{"at_bat":"Batter","first_base":"First","second_base":null,"third_base":"Third"}`},
+	} {
+		data, err := json.Marshal(fieldOf(tc.bases))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tc.want {
+			t.Errorf("%q on: marshaled %s, want %s", tc.bases, data, tc.want)
+		}
+	}
+
+	runner := hitter("Runner", 0.330, 0.420)
+	g := Game{Inning: 3, Outs: 1, Runs: 2, Field: Field{SecondBase: &runner}}
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"bases":{"at_bat":null,"first_base":null,"second_base":"Test Runner","third_base":null}`) ||
+		!strings.HasPrefix(string(data), `{"inning":3,"outs":1,`) {
+		t.Errorf("game marshaled to %s", data)
+	}
+}
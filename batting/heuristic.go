@@ -0,0 +1,32 @@
+package baseball
+
+// slotPA is roughly the plate appearances per nine-inning game each batting
+// slot gets; each slot down the order bats about 0.11 times less.
+var slotPA = [9]float64{4.65, 4.55, 4.44, 4.33, 4.22, 4.12, 4.01, 3.90, 3.79}
+
+// HeuristicScore is a cheap stand-in for simulating order: each hitter's
+// OBP, averaged over both pitcher hands, weighted by the plate appearances
+// their slot gets. Putting high-OBP hitters where the trips to the plate
+// are scores higher. It ignores power and baserunning, so use it to prune
+// clearly weak orders, not to rank close ones.
+func HeuristicScore(order []Player) float64 {
+	return WeightedHeuristicScore(order, nil)
+}
+
+// WeightedHeuristicScore is HeuristicScore with slot i's plate appearances
+// scaled by weights[i], to value some slots more than their trips to the
+// plate alone do. A nil weights scales every slot by 1.
+func WeightedHeuristicScore(order []Player, weights []float64) float64 {
+	score := 0.0
+	for i, p := range order {
+		w := slotPA[len(slotPA)-1] - 0.11*float64(i-len(slotPA)+1)
+		if i < len(slotPA) {
+			w = slotPA[i]
+		}
+		if weights != nil {
+			w *= weights[i]
+		}
+		score += w * (p.LHP.OBP + p.RHP.OBP) / 2
+	}
+	return score
+}
@@ -0,0 +1,36 @@
+package baseball
+
+import "testing"
+
+func TestHeuristicScorePrefersOBPUpTop(t *testing.T) {
+	better := make([]Player, 9)
+	for i := range better {
+		better[i] = hitter(string(rune('A'+i)), 0.400-0.020*float64(i), 0.450)
+	}
+	worse := make([]Player, 9)
+	for i := range worse {
+		worse[i] = better[8-i]
+	}
+	if b, w := HeuristicScore(better), HeuristicScore(worse); b <= w {
+		t.Errorf("best OBP first scores %.4f, worst first %.4f", b, w)
+	}
+}
+
+func TestSlotWeightsChangeThePreferredOrder(t *testing.T) {
+	better := make([]Player, 9)
+	for i := range better {
+		better[i] = hitter(string(rune('A'+i)), 0.400-0.020*float64(i), 0.450)
+	}
+	worse := make([]Player, 9)
+	for i := range worse {
+		worse[i] = better[8-i]
+	}
+	if b, w := WeightedHeuristicScore(better, nil), HeuristicScore(better); b != w {
+		t.Errorf("nil weights score %.4f, unweighted %.4f", b, w)
+	}
+	// Valuing only the bottom of the order puts the best hitters there.
+	bottom := []float64{0, 0, 0, 0, 0, 1, 1, 1, 1}
+	if b, w := WeightedHeuristicScore(better, bottom), WeightedHeuristicScore(worse, bottom); b >= w {
+		t.Errorf("with only the bottom four weighted, best OBP first scores %.4f, worst first %.4f", b, w)
+	}
+}
@@ -0,0 +1,24 @@
+package baseball
+
+// Log5 is Bill James's estimate of the rate at which a batter with rate
+// batterRate succeeds against a pitcher who allows pitcherRate, in a league
+// where the rate is leagueRate. All three are probabilities: an average
+// pitcher (pitcherRate == leagueRate) leaves the batter's rate alone, and
+// a .300 hitter against a pitcher holding hitters to .250 in a .265 league
+// comes out at about .284.
+//
+// A rate of 0 or 1 on either side is certain and wins out; when the two
+// sides are certain in opposite directions the batter's rate is returned.
+// A leagueRate outside (0, 1) gives nothing to measure against, so the
+// batter's rate is returned as is.
+func Log5(batterRate, pitcherRate, leagueRate float64) float64 {
+	if leagueRate <= 0 || leagueRate >= 1 {
+		return batterRate
+	}
+	yes := batterRate * pitcherRate / leagueRate
+	no := (1 - batterRate) * (1 - pitcherRate) / (1 - leagueRate)
+	if yes+no == 0 {
+		return batterRate
+	}
+	return yes / (yes + no)
+}
@@ -0,0 +1,28 @@
+package baseball
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLog5(t *testing.T) {
+	for _, tc := range []struct {
+		batter, pitcher, league, want float64
+	}{
+		{0.300, 0.250, 0.265, 0.2838},
+		{0.300, 0.265, 0.265, 0.300}, // an average pitcher changes nothing
+		{0.250, 0.300, 0.250, 0.300}, // nor does an average batter
+		{0, 0.300, 0.265, 0},
+		{1, 0.300, 0.265, 1},
+		{0.300, 0, 0.265, 0},
+		{0.300, 1, 0.265, 1},
+		{0, 1, 0.265, 0}, // certain both ways: the batter wins
+		{1, 0, 0.265, 1},
+		{0.300, 0.250, 0, 0.300}, // no league to measure against
+		{0.300, 0.250, 1, 0.300},
+	} {
+		if got := Log5(tc.batter, tc.pitcher, tc.league); math.Abs(got-tc.want) > 0.0005 {
+			t.Errorf("Log5(%v, %v, %v) = %.4f, want %.4f", tc.batter, tc.pitcher, tc.league, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package baseball
+
+import "math/rand"
+
+// MatchupResult is the outcome of a game between two lineups. Each Game holds
+// one team's offense against the other team's pitching.
+type MatchupResult struct {
+	Home    Game
+	Away    Game
+	Innings int
+}
+
+// HomeWon reports whether the home team won.
+func (m MatchupResult) HomeWon() bool {
+	return m.Home.Runs > m.Away.Runs
+}
+
+// Tie reports whether the game was called level at cfg.MaxExtraInnings.
+func (m MatchupResult) Tie() bool {
+	return m.Home.Runs == m.Away.Runs
+}
+
+// SimulateMatchup plays away against home. The home team bats in the bottom
+// of each inning, skips the bottom of the ninth (or later) when already ahead,
+// and wins as soon as it takes the lead there. Tied games go to extra innings
+// until one side leads after a complete inning, with cfg.ExtraInningRunner
+// placed to start them, or are called a tie after cfg.MaxExtraInnings.
+// cfg.HomeStarter pitches to the away lineup and cfg.AwayStarter to the home
+// lineup.
+func SimulateMatchup(home, away []Player, cfg GameConfig, r *rand.Rand) MatchupResult {
+	return simulateMatchup(home, away, nil, cfg, r)
+}
+
+// SimulateMatchupFrom plays the rest of a matchup from st. The team batting
+// in st starts the half-inning with the top of its lineup due up, and its
+// runners are the batters before that, last slot on first base; the other
+// team leads off its next half-inning. Innings and runs before st aren't
+// replayed, so LineScore covers only the innings played.
+func SimulateMatchupFrom(home, away []Player, st GameState, cfg GameConfig, r *rand.Rand) MatchupResult {
+	return simulateMatchup(home, away, &st, cfg, r)
+}
+
+// simulateMatchup is SimulateMatchupFrom, or a whole game when st is nil.
+func simulateMatchup(home, away []Player, st *GameState, cfg GameConfig, r *rand.Rand) MatchupResult {
+	m := MatchupResult{Home: newGame(home, cfg, r), Away: newGame(away, cfg, r)}
+	home, away = m.Home.sitOut(home, cfg, r), m.Away.sitOut(away, cfg, r)
+	m.Home.Home = true
+	m.Home.vsOpponent, m.Away.vsOpponent = true, true
+	m.Home.pitcher, m.Away.pitcher = cfg.AwayStarter, cfg.HomeStarter
+	m.Home.StartPitcher(cfg, r)
+	m.Away.StartPitcher(cfg, r)
+	first := 1
+	if st != nil {
+		m.Home.Runs, m.Away.Runs = st.HomeRuns, st.AwayRuns
+		first = st.Inning
+	}
+	homeNext, awayNext := 0, 0
+	// resume is set until the half-inning in progress at st is played; it
+	// starts with st's outs and runners instead of a fresh inning's.
+	resume := st != nil
+	for inning := first; ; inning++ {
+		m.Innings = inning
+		m.Away.Inning, m.Home.Inning = inning, inning
+		var runs int
+		if !(resume && st.Bottom) {
+			m.Away.MaybeChangePitcher(cfg, inning, r)
+			m.Away.oppRuns = m.Home.Runs
+			outs := 0
+			if resume {
+				outs = st.place(&m.Away, away)
+				resume = false
+			} else if inning > 9 && cfg.ExtraInningRunnerHalves != "bottom" {
+				m.Away.placeRunner(away, awayNext, cfg.ExtraInningRunner)
+			}
+			runs, awayNext, _ = simulateInning(&m.Away, away, awayNext, cfg, r, -1, outs)
+			if cfg.TrackSlots {
+				m.Away.LineScore = append(m.Away.LineScore, runs)
+			}
+		}
+		late := inning >= 9
+		if late && m.Home.Runs > m.Away.Runs {
+			break
+		}
+		m.Home.MaybeChangePitcher(cfg, inning, r)
+		walkOff := -1
+		if late {
+			walkOff = m.Away.Runs
+		}
+		m.Home.oppRuns = m.Away.Runs
+		outs := 0
+		if resume {
+			outs = st.place(&m.Home, home)
+			resume = false
+		} else if inning > 9 && cfg.ExtraInningRunnerHalves != "top" {
+			m.Home.placeRunner(home, homeNext, cfg.ExtraInningRunner)
+		}
+		runs, homeNext, _ = simulateInning(&m.Home, home, homeNext, cfg, r, walkOff, outs)
+		if cfg.TrackSlots {
+			m.Home.LineScore = append(m.Home.LineScore, runs)
+		}
+		if late && m.Home.Runs != m.Away.Runs {
+			break
+		}
+		if cfg.MaxExtraInnings > 0 && inning >= 9+cfg.MaxExtraInnings {
+			break
+		}
+	}
+	return m
+}
+
+// placeRunner puts the batter before next in lineup on base (1-3) to start
+// an extra half-inning. Zero places no one.
+func (g *Game) placeRunner(lineup []Player, next, base int) {
+	if base > 0 {
+		g.Field.placeRunner(base, &lineup[(next+len(lineup)-1)%len(lineup)])
+	}
+}
@@ -0,0 +1,74 @@
+package baseball
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHomeFieldFactorFavorsHome(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	cfg := DefaultGameConfig()
+	cfg.HomeFieldFactor = 1.2
+	r := rand.New(rand.NewSource(1))
+	var home, away int
+	for i := 0; i < 1000; i++ {
+		m := SimulateMatchup(lineup, lineup, cfg, r)
+		home += m.Home.Runs
+		away += m.Away.Runs
+	}
+	if home <= away {
+		t.Errorf("with a home factor of 1.2 the home side scored %d, the road side %d", home, away)
+	}
+}
+
+func TestGhostRunnerOnThirdScoresMoreExtras(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	// share is the fraction of extra top halves with a run.
+	share := func(base int) float64 {
+		cfg := DefaultGameConfig()
+		cfg.ExtraInningRunner = base
+		var halves, scored map[int]bool
+		cfg.Trace = func(p Play) {
+			if p.Inning > 9 && !p.Home {
+				halves[p.Inning] = true
+				if p.Runs > 0 {
+					scored[p.Inning] = true
+				}
+			}
+		}
+		r := rand.New(rand.NewSource(1))
+		var n, s int
+		for i := 0; i < 3000; i++ {
+			halves, scored = map[int]bool{}, map[int]bool{}
+			SimulateMatchup(lineup, lineup, cfg, r)
+			n += len(halves)
+			s += len(scored)
+		}
+		return float64(s) / float64(n)
+	}
+	none, third := share(0), share(3)
+	if third < none+0.2 {
+		t.Errorf("%.2f of extra halves scored with a runner on third, %.2f with none", third, none)
+	}
+}
+
+func TestExtraInningCapCallsATie(t *testing.T) {
+	lineup := nineOf(hitter("Slugger", 0.400, 0.700))
+	cfg := DefaultGameConfig()
+	cfg.MaxExtraInnings = 3
+	cfg.ExtraInningRunner = 2
+	// Every other slot homers, so both sides score the same each inning.
+	cfg.OutcomeOverride = func(slot, _ int) (PlateOutcome, bool) {
+		if slot%2 == 0 {
+			return HIT_HOMERUN, true
+		}
+		return HIT_OUT, true
+	}
+	m := SimulateMatchup(lineup, lineup, cfg, rand.New(rand.NewSource(1)))
+	if !m.Tie() || m.HomeWon() || m.Innings != 12 {
+		t.Errorf("%d-%d after %d innings, want a tie called after 12", m.Home.Runs, m.Away.Runs, m.Innings)
+	}
+	if m.Home.Runs < 30 {
+		t.Errorf("only %d runs each; the lineups should slug", m.Home.Runs)
+	}
+}
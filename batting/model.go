@@ -0,0 +1,54 @@
+package baseball
+
+import "fmt"
+
+// Model holds the engine's calibration constants, for tuning the simulated
+// scoring level to a league. The zero value is DefaultModel.
+type Model struct {
+	// ScoreFromSecond is the [low, high] chance a runner on second scores on
+	// a single, and ScoreFromFirstOnDouble a runner on first on a double,
+	// mapped linearly over batter SLUG .350 to .600.
+	ScoreFromSecond        [2]float64 `json:"score_from_second"`
+	ScoreFromFirstOnDouble [2]float64 `json:"score_from_first_on_double"`
+	// SinglesFloor is the least share of hits that are singles before park
+	// and speed adjustments.
+	SinglesFloor float64 `json:"singles_floor"`
+	// HomeRunShare bounds the share of hits that are home runs before park
+	// adjustments.
+	HomeRunShare [2]float64 `json:"home_run_share"`
+	// StatScale multiplies every batter's AVG, OBP and SLUG; 0 is 1.
+	StatScale float64 `json:"stat_scale"`
+}
+
+// DefaultModel is the engine's stock calibration.
+var DefaultModel = Model{
+	ScoreFromSecond:        [2]float64{0.38, 0.72},
+	ScoreFromFirstOnDouble: [2]float64{0.32, 0.62},
+	SinglesFloor:           0.55,
+	HomeRunShare:           [2]float64{0.03, 0.12},
+	StatScale:              1,
+}
+
+// orDefault returns DefaultModel for the zero Model.
+func (m Model) orDefault() Model {
+	if m == (Model{}) {
+		return DefaultModel
+	}
+	return m
+}
+
+// Validate reports the first invalid constant in m.
+func (m Model) Validate() error {
+	for _, b := range [][2]float64{m.ScoreFromSecond, m.ScoreFromFirstOnDouble, m.HomeRunShare} {
+		if b[0] < 0 || b[1] > 1 || b[0] > b[1] {
+			return fmt.Errorf("model bands must be ordered within [0, 1], got %+v", m)
+		}
+	}
+	if m.SinglesFloor < 0 || m.SinglesFloor > 1 {
+		return fmt.Errorf("model singles floor must be between 0 and 1, got %v", m.SinglesFloor)
+	}
+	if m.StatScale < 0 {
+		return fmt.Errorf("model stat scale must not be negative, got %v", m.StatScale)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package baseball
+
+import "fmt"
+
+// League-average batting rates that pitcher rates are measured against.
+const (
+	LeagueAVG  = 0.248
+	LeagueOBP  = 0.317
+	LeagueSLUG = 0.411
+)
+
+// Pitcher is a starting pitcher described by the AVG, OBP and SLUG batters
+// hit against them. A zero rate is treated as league average.
+type Pitcher struct {
+	Name string  `json:"name"`
+	Hand string  `json:"hand"` // "left" or "right"; empty picks at random
+	AVG  float64 `json:"avg"`
+	OBP  float64 `json:"obp"`
+	SLUG float64 `json:"slug"`
+}
+
+// Validate reports the first invalid field of p.
+func (p Pitcher) Validate() error {
+	switch p.Hand {
+	case "", "left", "right":
+	default:
+		return fmt.Errorf(`pitcher %s: hand must be "left" or "right", got %q`, p.Name, p.Hand)
+	}
+	if p.AVG < 0 || p.OBP < 0 || p.SLUG < 0 {
+		return fmt.Errorf("pitcher %s: rates must not be negative", p.Name)
+	}
+	return nil
+}
+
+// adjust combines a batter's split with p's allowed rates. AVG and OBP are
+// probabilities and combine by Log5; SLUG isn't, so it scales by how far
+// p's allowed SLUG sits from league average, e.g. a pitcher allowing .370
+// cuts every batter's SLUG by .370/.411.
+func (p Pitcher) adjust(s Stats) Stats {
+	if p.AVG > 0 {
+		s.AVG = Log5(s.AVG, p.AVG, LeagueAVG)
+	}
+	if p.OBP > 0 {
+		s.OBP = Log5(s.OBP, p.OBP, LeagueOBP)
+	}
+	if p.SLUG > 0 {
+		s.SLUG *= p.SLUG / LeagueSLUG
+	}
+	if s.OBP > 1 {
+		s.OBP = 1
+	}
+	if s.AVG > s.OBP {
+		s.AVG = s.OBP
+	}
+	return s
+}
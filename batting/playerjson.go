@@ -0,0 +1,43 @@
+package baseball
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PlayerFormatVersion is the players-file format Player marshals to. Files
+// without a version predate it and load with every newer field unset, which
+// the engine treats as its default (neutral speed and aggression, no
+// position).
+const PlayerFormatVersion = 1
+
+// playerFields is Player without its methods, so the codec doesn't recurse.
+type playerFields Player
+
+// MarshalJSON encodes p with the current format version. Optional fields
+// are omitted when unset.
+func (p Player) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Version int `json:"version"`
+		playerFields
+	}{PlayerFormatVersion, playerFields(p)})
+}
+
+// UnmarshalJSON decodes a player written by this or any earlier format
+// version, and rejects one from a newer version rather than silently
+// dropping fields it doesn't know.
+func (p *Player) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Version int `json:"version"`
+		playerFields
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Version > PlayerFormatVersion {
+		return fmt.Errorf("player %s %s has format version %d; this build reads up to %d",
+			v.FirstName, v.LastName, v.Version, PlayerFormatVersion)
+	}
+	*p = Player(v.playerFields)
+	return nil
+}
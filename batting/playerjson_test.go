@@ -0,0 +1,75 @@
+package baseball
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlayerJSONRoundTrip(t *testing.T) {
+	full := Player{
+		FirstName: "Bryce", LastName: "Harper", Position: "1B/RF",
+		LHP:          Stats{AVG: 0.265, OBP: 0.360, SLUG: 0.470, Double: 0.22, Triple: 0.01, HomeRun: 0.17, PA: 210},
+		RHP:          Stats{AVG: 0.290, OBP: 0.395, SLUG: 0.540, PA: 480},
+		Speed:        27.1,
+		Aggression:   1.2,
+		KRate:        0.22,
+		Availability: 0.95,
+		RecentLHP:    &Stats{AVG: 0.240, OBP: 0.330, SLUG: 0.410},
+		RecentRHP:    &Stats{AVG: 0.310, OBP: 0.420, SLUG: 0.600},
+		RISPLHP:      &Stats{AVG: 0.250, OBP: 0.370, SLUG: 0.450},
+		RISPRHP:      &Stats{AVG: 0.300, OBP: 0.410, SLUG: 0.560},
+	}
+	old := `{"first_name": "Old", "last_name": "Timer",
+		"LHP": {"avg": 0.250, "obp": 0.320, "slug": 0.400},
+		"RHP": {"avg": 0.260, "obp": 0.330, "slug": 0.410}}`
+	var minimal Player
+	if err := json.Unmarshal([]byte(old), &minimal); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []Player{full, minimal} {
+		data, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"version":1`) {
+			t.Errorf("%s marshaled without a version: %s", p.LastName, data)
+		}
+		var got Player
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: %v", p.LastName, err)
+		}
+		if !reflect.DeepEqual(got, p) {
+			t.Errorf("%s reloaded as %+v, want %+v", p.LastName, got, p)
+		}
+	}
+
+	data, _ := json.Marshal(minimal)
+	for _, field := range []string{"position", "speed", "aggression", "k_rate", "availability", "recent_lhp", "risp_rhp", "double", "pa"} {
+		if strings.Contains(string(data), `"`+field+`"`) {
+			t.Errorf("unset %s written: %s", field, data)
+		}
+	}
+}
+
+func TestPlayerJSONDefaults(t *testing.T) {
+	var p Player
+	if err := json.Unmarshal([]byte(`{"first_name": "Old", "last_name": "Timer", "LHP": {"avg": 0.25, "obp": 0.32, "slug": 0.4}}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Position != "" || p.Speed != 0 || p.KRate != 0 || p.RecentLHP != nil || p.RISPRHP != nil || p.LHP.HasHitMix() {
+		t.Errorf("old player loaded with new fields set: %+v", p)
+	}
+	if p.aggression() != 1 {
+		t.Errorf("old player's aggression is %v, want neutral", p.aggression())
+	}
+	if p.RHP != (Stats{}) {
+		t.Errorf("missing RHP split loaded as %+v", p.RHP)
+	}
+
+	if err := json.Unmarshal([]byte(`{"version": 2, "last_name": "Future"}`), &p); err == nil {
+		t.Error("a player from a newer format version loaded")
+	}
+}
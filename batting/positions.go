@@ -0,0 +1,68 @@
+package baseball
+
+import "strings"
+
+// FieldingPositions are the nine lineup spots a valid alignment must fill
+// exactly once. Any player can DH.
+var FieldingPositions = []string{"C", "1B", "2B", "3B", "SS", "LF", "CF", "RF", "DH"}
+
+// CanPlay reports whether p is eligible at pos. Position lists alternatives
+// separated by "/" (e.g. "SS/2B"), and "OF" covers all three outfield spots.
+func (p Player) CanPlay(pos string) bool {
+	if pos == "DH" {
+		return true
+	}
+	for _, tok := range strings.Split(p.Position, "/") {
+		tok = strings.ToUpper(strings.TrimSpace(tok))
+		if tok == pos || (tok == "OF" && (pos == "LF" || pos == "CF" || pos == "RF")) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidAlignment reports whether players can be assigned one-to-one to
+// FieldingPositions given each player's eligibility.
+func ValidAlignment(players []Player) bool {
+	_, ok := Alignment(players)
+	return ok
+}
+
+// Alignment assigns players one-to-one to FieldingPositions given each
+// player's eligibility, returning each player's position in order, or false
+// if no valid alignment exists.
+func Alignment(players []Player) ([]string, bool) {
+	if len(players) != len(FieldingPositions) {
+		return nil, false
+	}
+	// Bipartite matching by augmenting paths; holder[pos] is the player index
+	// currently assigned to that position, or -1.
+	holder := make([]int, len(FieldingPositions))
+	for i := range holder {
+		holder[i] = -1
+	}
+	var assign func(p int, seen []bool) bool
+	assign = func(p int, seen []bool) bool {
+		for pos, name := range FieldingPositions {
+			if seen[pos] || !players[p].CanPlay(name) {
+				continue
+			}
+			seen[pos] = true
+			if holder[pos] < 0 || assign(holder[pos], seen) {
+				holder[pos] = p
+				return true
+			}
+		}
+		return false
+	}
+	for p := range players {
+		if !assign(p, make([]bool, len(FieldingPositions))) {
+			return nil, false
+		}
+	}
+	pos := make([]string, len(players))
+	for i, p := range holder {
+		pos[p] = FieldingPositions[i]
+	}
+	return pos, true
+}
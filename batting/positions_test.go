@@ -0,0 +1,40 @@
+package baseball
+
+import "testing"
+
+// fielders returns one player for each of positions, in order.
+func fielders(positions ...string) []Player {
+	players := make([]Player, len(positions))
+	for i, pos := range positions {
+		players[i] = Player{LastName: pos, Position: pos}
+	}
+	return players
+}
+
+func TestAlignment(t *testing.T) {
+	players := fielders("C", "1B", "2B/SS", "SS", "3B", "OF", "OF", "OF", "1B")
+	pos, ok := Alignment(players)
+	if !ok {
+		t.Fatal("no alignment for a full defense")
+	}
+	taken := map[string]bool{}
+	for i, p := range pos {
+		if !players[i].CanPlay(p) {
+			t.Errorf("%s placed at %s", players[i].Position, p)
+		}
+		if taken[p] {
+			t.Errorf("%s filled twice", p)
+		}
+		taken[p] = true
+	}
+	if len(taken) != len(FieldingPositions) {
+		t.Errorf("filled %v, want all of %v", pos, FieldingPositions)
+	}
+}
+
+func TestAlignmentNeedsACatcher(t *testing.T) {
+	players := fielders("1B", "1B", "2B", "SS", "3B", "LF", "CF", "RF", "SS/2B")
+	if ValidAlignment(players) {
+		t.Error("a nine without a catcher has a valid alignment")
+	}
+}
@@ -0,0 +1,46 @@
+package baseball
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// ScriptedSource is a rand.Source that replays a fixed sequence of values so
+// callers can force exact outcomes: each Float64 drawn from a rand.Rand built
+// on it returns the next scripted value. It panics when the script runs out,
+// which surfaces any draw the caller didn't plan for.
+type ScriptedSource struct {
+	vals []float64
+	next int
+}
+
+// NewScripted returns a rand.Rand whose successive Float64 calls return vals,
+// each of which must be in [0, 1).
+func NewScripted(vals ...float64) *rand.Rand {
+	for _, v := range vals {
+		if v < 0 || v >= 1 {
+			panic(fmt.Sprintf("scripted value %v outside [0, 1)", v))
+		}
+	}
+	return rand.New(&ScriptedSource{vals: vals})
+}
+
+// Int63 returns the next scripted value scaled so rand.Rand.Float64 recovers it.
+func (s *ScriptedSource) Int63() int64 {
+	if s.next >= len(s.vals) {
+		panic(fmt.Sprintf("scripted source exhausted after %d draws", len(s.vals)))
+	}
+	v := s.vals[s.next]
+	s.next++
+	return int64(v * (1 << 63))
+}
+
+// Seed rewinds the script to the start.
+func (s *ScriptedSource) Seed(int64) {
+	s.next = 0
+}
+
+// Drawn reports how many values have been consumed.
+func (s *ScriptedSource) Drawn() int {
+	return s.next
+}
@@ -0,0 +1,72 @@
+package baseball
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// mixHitter hits .270/.340 with every hit type equally likely, so a
+// scripted draw picks the type: below .25 a single, then a double, a
+// triple and a home run.
+var mixHitter = Player{
+	LastName: "Mix",
+	RHP:      Stats{AVG: 0.270, OBP: 0.340, SLUG: 0.675, Double: 0.25, Triple: 0.25, HomeRun: 0.25},
+}
+
+// Each plate appearance draws once for its outcome and once more for a
+// hit's type; a runner on first draws to decide whether they score on a
+// double.
+func ExampleNewScripted() {
+	lineup := nineOf(mixHitter)
+	cfg := DefaultGameConfig()
+	cfg.PitcherHand = "right"
+	r := NewScripted(
+		0.1, 0.1, // single
+		0.1, 0.3, 0.99, // double; the runner from first stops at third
+		0.9,      // out
+		0.1, 0.9, // home run
+		0.9, 0.9, // two outs
+	)
+	g := NewGame(cfg, r)
+	runs, next, lob := SimulateInning(g, lineup, 0, cfg, r)
+	fmt.Printf("runs=%d hits=%d next=%d lob=%d\n", runs, g.Hits, next, lob)
+	// Output: runs=3 hits=3 next=6 lob=0
+}
+
+func TestScriptedInningUsesEveryDraw(t *testing.T) {
+	src := &ScriptedSource{vals: []float64{0.1, 0.1, 0.1, 0.3, 0.99, 0.9, 0.1, 0.9, 0.9, 0.9}}
+	r := rand.New(src)
+	cfg := DefaultGameConfig()
+	cfg.PitcherHand = "right"
+	var plays []Play
+	cfg.Trace = func(p Play) { plays = append(plays, p) }
+	g := Game{Rand: r}
+	SimulateInning(&g, nineOf(mixHitter), 0, cfg, r)
+	if src.Drawn() != 10 {
+		t.Errorf("drew %d of the 10 scripted values", src.Drawn())
+	}
+	want := []PlateOutcome{HIT_SINGLE, HIT_DOUBLE, HIT_OUT, HIT_HOMERUN, HIT_OUT, HIT_OUT}
+	if len(plays) != len(want) {
+		t.Fatalf("%d plays, want %d", len(plays), len(want))
+	}
+	for i, p := range plays {
+		if p.Outcome != want[i] {
+			t.Errorf("play %d: %s, want %s", i+1, p.Outcome, want[i])
+		}
+	}
+	if after := plays[1].After; after.SecondBase == nil || after.ThirdBase == nil || after.FirstBase != nil {
+		t.Errorf("after the double the bases are %v, want 2B 3B", after)
+	}
+}
+
+func TestScriptedSourcePanicsWhenExhausted(t *testing.T) {
+	r := NewScripted(0.5)
+	r.Float64()
+	defer func() {
+		if recover() == nil {
+			t.Error("a draw past the script didn't panic")
+		}
+	}()
+	r.Float64()
+}
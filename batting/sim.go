@@ -0,0 +1,271 @@
+package baseball
+
+import "math/rand"
+
+// SimulateInning plays one half-inning for lineup, starting with the batter at
+// startIndex, until cfg.OutsPerInning outs are recorded. It returns the runs
+// scored in the inning, the index of the batter due up next, and the number of
+// runners left on base. The bases are cleared before returning.
+func SimulateInning(g *Game, lineup []Player, startIndex int, cfg GameConfig, r *rand.Rand) (runs, next, lob int) {
+	return simulateInning(g, lineup, startIndex, cfg, r, -1, 0)
+}
+
+// simulateInning is SimulateInning with a walk-off, resumed with outs already
+// recorded: when walkOff is non-negative the inning also ends as soon as
+// g.Runs exceeds it.
+func simulateInning(g *Game, lineup []Player, startIndex int, cfg GameConfig, r *rand.Rand, walkOff, outs int) (runs, next, lob int) {
+	checkLineup(lineup)
+	startRuns := g.Runs
+	g.lineup = lineup
+	g.model = cfg.Model
+	if cfg.TrackSlots && g.Slots == nil {
+		g.Slots = make([]SlotStats, len(lineup))
+	}
+	g.Outs = outs
+	batter := startIndex
+	capping := cfg.RunCap > 0 && cfg.OverRunCap != nil
+	var plays []Play
+	for g.Outs < cfg.OutsPerInning && (walkOff < 0 || g.Runs <= walkOff) {
+		// A runner caught stealing for the last out ends the inning with the
+		// batter still due up.
+		g.stealSecond(cfg, r)
+		if g.Outs >= cfg.OutsPerInning {
+			break
+		}
+		// A wild pitch can score the winning run before the batter swings.
+		g.wildPitch(cfg, r)
+		if walkOff >= 0 && g.Runs > walkOff {
+			break
+		}
+		if g.pinchHitting {
+			g.pinchHit(cfg, lineup, batter)
+		}
+		g.Field.AtBat = &lineup[batter]
+		var before Field
+		if cfg.Trace != nil || capping {
+			before = g.Field
+		}
+		outsBefore, runsBefore := g.Outs, g.Runs
+		infieldIn := g.infieldIn(cfg)
+		g.holdThird = 1 - cfg.ScoreFromThirdOnSingle
+		g.holdSecond = 1 - cfg.ScoreFromSecondOnDouble
+		g.firstToHome = cfg.ScoreFromFirstOnSingle
+		g.extraOnThrow = cfg.ExtraBaseOnThrow
+		if infieldIn {
+			g.holdThird = 1 - cfg.InfieldIn.ScoreFromThird
+		}
+		stats := g.effectiveStats(&lineup[batter], cfg)
+		var result PlateOutcome
+		forced := false
+		if cfg.OutcomeOverride != nil {
+			result, forced = cfg.OutcomeOverride(batter, g.Inning)
+		}
+		switch {
+		case forced:
+		case g.walkIntentionally(cfg, stats):
+			result = HIT_WALK
+			g.IBB++
+		default:
+			result = plateAppearance(stats, lineup[batter].Speed, cfg, r)
+		}
+		if result == HIT_OUT && !forced && infieldIn && r.Float64() < cfg.InfieldIn.SingleBoost {
+			result = HIT_SINGLE
+		}
+		g.stretching = result == HIT_DOUBLE && cfg.OutStretching > 0 && r.Float64() < cfg.OutStretching
+		strikeout, dropped := false, false
+		switch result {
+		case HIT_OUT:
+			g.Outs++
+			// A strikeout leaves every runner where they are.
+			if k := lineup[batter].strikeoutShare(stats); k > 0 && r.Float64() < k {
+				strikeout = true
+				g.SO++
+			}
+			// On a dropped third strike the batter may run when first base
+			// is open or there are two outs, and reaches instead of being out.
+			canRun := g.Field.FirstBase == nil || outsBefore == cfg.OutsPerInning-1
+			if strikeout && cfg.DroppedThirdStrike > 0 && canRun && r.Float64() < cfg.DroppedThirdStrike {
+				g.Outs--
+				dropped = true
+				if third := g.Field.ThirdBase; g.Field.forceAdvance() > 0 {
+					g.score(third, nil)
+				}
+				g.Field.placeRunner(1, g.Field.AtBat)
+			}
+			// A double play needs room for two more outs in the inning, so
+			// it can't happen with two out. The runner from first is the one
+			// erased; everyone else holds.
+			gidp := false
+			if !strikeout && cfg.GIDPRate > 0 && g.Field.FirstBase != nil && outsBefore < cfg.OutsPerInning-1 {
+				if r.Float64() < cfg.GIDPRate {
+					g.Outs++
+					g.Field.clear(1)
+					gidp = true
+				}
+			}
+			scoringPosition := g.Field.SecondBase != nil || g.Field.ThirdBase != nil
+			advanced := false
+			if !gidp && !strikeout && cfg.ProductiveOutRate > 0 && scoringPosition && g.Outs < cfg.OutsPerInning {
+				if r.Float64() < cfg.ProductiveOutRate {
+					g.advanceLead(&lineup[batter])
+					advanced = true
+				}
+			}
+			// A ground ball to the right side moves a lone runner on second
+			// to third.
+			f := &g.Field
+			loneOnSecond := f.SecondBase != nil && f.FirstBase == nil && f.ThirdBase == nil
+			if !gidp && !advanced && !strikeout && cfg.SecondToThirdOnOut > 0 && loneOnSecond && g.Outs < cfg.OutsPerInning {
+				if r.Float64() < cfg.SecondToThirdOnOut {
+					f.moveRunner(2, 3)
+				}
+			}
+		default:
+			g.Hit(result)
+		}
+		g.Field.AtBat = nil
+		g.PA++
+		if g.Slots != nil {
+			g.Slots[batter].PA++
+		}
+		if cfg.Trace != nil || capping {
+			play := Play{
+				Inning:     g.Inning,
+				Home:       g.Home,
+				Slot:       batter,
+				Batter:     &lineup[batter],
+				Outcome:    result,
+				OutsBefore: outsBefore,
+				Outs:       g.Outs,
+				Runs:       g.Runs - runsBefore,
+				Strikeout:  strikeout,
+				Dropped:    dropped,
+				Stretching: g.stretching,
+				Before:     before,
+				After:      g.Field,
+			}
+			if cfg.Trace != nil {
+				cfg.Trace(play)
+			}
+			if capping {
+				plays = append(plays, play)
+			}
+		}
+		if g.pinchRunning {
+			g.pinchRun(cfg, lineup, batter)
+		}
+		batter++
+		if batter >= len(lineup) {
+			batter = 0
+		}
+	}
+	if capping && g.Runs-startRuns > cfg.RunCap {
+		cfg.OverRunCap(g.Inning, plays)
+	}
+	g.TotalOuts += g.Outs - outs
+	lob = g.Field.LOB()
+	g.AddLOB(lob)
+	g.Field.clearBases()
+	return g.Runs - startRuns, batter, lob
+}
+
+// infieldIn reports whether the defense plays the infield in for the next
+// batter: the mode is on, it's late, the game is close, and there's a runner
+// on third with fewer than cfg.OutsPerInning-1 outs.
+func (g *Game) infieldIn(cfg GameConfig) bool {
+	in := cfg.InfieldIn
+	if !in.Enabled || g.Inning < in.FromInning || g.Field.ThirdBase == nil || g.Outs >= cfg.OutsPerInning-1 {
+		return false
+	}
+	return g.close(in.MaxMargin)
+}
+
+// walkIntentionally reports whether the defense puts the batter, hitting
+// with stats, on first: the rule is on, it's late and close, first base is
+// open with a runner in scoring position, and the batter slugs at least
+// cfg.IntentionalWalk.MinSLUG.
+func (g *Game) walkIntentionally(cfg GameConfig, stats Stats) bool {
+	iw := cfg.IntentionalWalk
+	if !iw.Enabled || g.Inning < iw.FromInning || g.Field.FirstBase != nil || stats.SLUG < iw.MinSLUG {
+		return false
+	}
+	if g.Field.SecondBase == nil && g.Field.ThirdBase == nil {
+		return false
+	}
+	return g.close(iw.MaxMargin)
+}
+
+// close reports whether the score is within margin either way. SimulateGame
+// has no opponent score, so every game is close there.
+func (g *Game) close(margin int) bool {
+	if !g.vsOpponent {
+		return true
+	}
+	d := g.Runs - g.oppRuns
+	return d <= margin && d >= -margin
+}
+
+// newGame returns an empty game for lineup, with slot tracking when configured.
+func newGame(lineup []Player, cfg GameConfig, r *rand.Rand) Game {
+	checkLineup(lineup)
+	g := Game{Rand: r}
+	if cfg.TrackSlots {
+		g.Slots = make([]SlotStats, len(lineup))
+	}
+	return g
+}
+
+// SimulateGame plays a nine-inning game for lineup and returns the final state.
+func SimulateGame(lineup []Player, cfg GameConfig, r *rand.Rand) Game {
+	g := newGame(lineup, cfg, r)
+	lineup = g.sitOut(lineup, cfg, r)
+	if len(cfg.PinchHits) > 0 || len(cfg.DoubleSwitches) > 0 || len(cfg.PinchRunning.Runners) > 0 {
+		// Substitutions change the lineup, so they get this game's own copy.
+		lineup = append([]Player(nil), lineup...)
+		g.pinchHitting = len(cfg.PinchHits) > 0
+		g.pinchRunning = len(cfg.PinchRunning.Runners) > 0
+	}
+	g.StartPitcher(cfg, r)
+	next := 0
+	for inning := 1; inning <= 9; inning++ {
+		g.Inning = inning
+		g.MaybeChangePitcher(cfg, inning, r)
+		g.doubleSwitch(cfg, lineup)
+		var runs int
+		if st := cfg.FirstInning; st != nil && inning == 1 {
+			runs, next, _ = simulateInning(&g, lineup, next, cfg, r, -1, st.place(&g, lineup))
+		} else {
+			runs, next, _ = SimulateInning(&g, lineup, next, cfg, r)
+		}
+		if cfg.TrackSlots {
+			g.LineScore = append(g.LineScore, runs)
+		}
+	}
+	return g
+}
+
+// Play describes one plate appearance, as passed to GameConfig.Trace.
+type Play struct {
+	Inning     int
+	Home       bool // the home team batting in a matchup
+	Slot       int  // 0-based batting slot
+	Batter     *Player
+	Outcome    PlateOutcome
+	OutsBefore int
+	Outs       int // outs after the play
+	Runs       int // runs scored on the play
+	Strikeout  bool
+	// Dropped marks a strikeout on which the batter reached first on a
+	// dropped third strike.
+	Dropped bool
+	// Stretching marks a double on which the batter was thrown out trying
+	// for second; Outcome is still HIT_DOUBLE.
+	Stretching bool
+	Before     Field
+	After      Field
+}
+
+// DoublePlay reports whether the play recorded two outs.
+func (p Play) DoublePlay() bool {
+	return p.Outs-p.OutsBefore == 2
+}
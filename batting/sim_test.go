@@ -0,0 +1,544 @@
+package baseball
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// hitter returns a player with the same split against both hands: OBP
+// obp, AVG 70 points below it and SLUG slug.
+func hitter(last string, obp, slug float64) Player {
+	s := Stats{AVG: obp - 0.07, OBP: obp, SLUG: slug}
+	return Player{FirstName: "Test", LastName: last, LHP: s, RHP: s}
+}
+
+// nineOf returns a lineup of nine copies of p, each with its own name.
+func nineOf(p Player) []Player {
+	lineup := make([]Player, 9)
+	for i := range lineup {
+		lineup[i] = p
+		lineup[i].LastName = fmt.Sprintf("%s%d", p.LastName, i+1)
+	}
+	return lineup
+}
+
+// always is an OutcomeOverride forcing every plate appearance to o.
+func always(o PlateOutcome) func(int, int) (PlateOutcome, bool) {
+	return func(int, int) (PlateOutcome, bool) { return o, true }
+}
+
+func TestDefaultOutsPerInning(t *testing.T) {
+	if got := DefaultGameConfig().OutsPerInning; got != 3 {
+		t.Errorf("default outs per inning = %d, want 3", got)
+	}
+}
+
+func TestTwoOutInningsEndSooner(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	pas := func(outs int) int {
+		cfg := DefaultGameConfig()
+		cfg.OutsPerInning = outs
+		r := rand.New(rand.NewSource(1))
+		total := 0
+		for i := 0; i < 500; i++ {
+			total += SimulateGame(lineup, cfg, r).PA
+		}
+		return total
+	}
+	two, three := pas(2), pas(3)
+	if two >= three {
+		t.Errorf("two-out innings took %d plate appearances, three-out %d", two, three)
+	}
+
+	cfg := DefaultGameConfig()
+	cfg.OutsPerInning = 2
+	cfg.OutcomeOverride = always(HIT_OUT)
+	var g Game
+	SimulateInning(&g, lineup, 0, cfg, rand.New(rand.NewSource(1)))
+	if g.PA != 2 || g.TotalOuts != 2 {
+		t.Errorf("three-up-three-down with two outs: %d PA, %d outs; want 2 and 2", g.PA, g.TotalOuts)
+	}
+}
+
+func TestDoublePlayNeedsRoomForTwoOuts(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	for _, tc := range []struct {
+		perInning, before int
+		want              bool
+	}{
+		{3, 0, true},
+		{3, 1, true},
+		{3, 2, false},
+		{2, 1, false},
+		{4, 2, true},
+	} {
+		cfg := DefaultGameConfig()
+		cfg.OutsPerInning = tc.perInning
+		cfg.GIDPRate = 1
+		cfg.OutcomeOverride = always(HIT_OUT)
+		var plays []Play
+		cfg.Trace = func(p Play) { plays = append(plays, p) }
+		var g Game
+		g.Field.FirstBase = &lineup[8]
+		simulateInning(&g, lineup, 0, cfg, rand.New(rand.NewSource(1)), -1, tc.before)
+		if got := plays[0].DoublePlay(); got != tc.want {
+			t.Errorf("%d-out inning with %d out: double play = %v, want %v", tc.perInning, tc.before, got, tc.want)
+		}
+		if got := g.TotalOuts; got != tc.perInning-tc.before {
+			t.Errorf("%d-out inning with %d out: recorded %d outs, want %d", tc.perInning, tc.before, got, tc.perInning-tc.before)
+		}
+	}
+}
+
+// script is an OutcomeOverride forcing the plate appearances, in order, to
+// outcomes, and every one after them to an out.
+func script(outcomes ...PlateOutcome) func(int, int) (PlateOutcome, bool) {
+	next := 0
+	return func(int, int) (PlateOutcome, bool) {
+		if next >= len(outcomes) {
+			return HIT_OUT, true
+		}
+		next++
+		return outcomes[next-1], true
+	}
+}
+
+func TestSimulateInningThreeUpThreeDown(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	cfg := DefaultGameConfig()
+	cfg.OutcomeOverride = script(HIT_OUT, HIT_OUT, HIT_OUT)
+	var g Game
+	runs, next, lob := SimulateInning(&g, lineup, 4, cfg, rand.New(rand.NewSource(1)))
+	if runs != 0 || next != 7 || lob != 0 {
+		t.Errorf("runs, next, lob = %d, %d, %d; want 0, 7, 0", runs, next, lob)
+	}
+	if g.PA != 3 || g.TotalOuts != 3 {
+		t.Errorf("%d PA and %d outs, want 3 and 3", g.PA, g.TotalOuts)
+	}
+}
+
+func TestSimulateInningBasesLoadedWalk(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	cfg := DefaultGameConfig()
+	cfg.GIDPRate = 0
+	cfg.OutcomeOverride = script(HIT_WALK, HIT_WALK, HIT_WALK, HIT_WALK)
+	var g Game
+	runs, next, lob := SimulateInning(&g, lineup, 7, cfg, rand.New(rand.NewSource(1)))
+	if runs != 1 || next != 5 || lob != 3 {
+		t.Errorf("runs, next, lob = %d, %d, %d; want 1, 5, 3", runs, next, lob)
+	}
+	if g.Walks != 4 || g.PA != 7 {
+		t.Errorf("%d walks in %d PA, want 4 in 7", g.Walks, g.PA)
+	}
+	if g.Field.LOB() != 0 {
+		t.Errorf("bases left at %v after the inning", g.Field)
+	}
+}
+
+func TestNoDoublePlaysWithoutGIDP(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	cfg := DefaultGameConfig()
+	cfg.GIDPRate = 0
+	plays := 0
+	cfg.Trace = func(p Play) {
+		plays++
+		if p.DoublePlay() {
+			t.Fatalf("double play with GIDP off: %+v", p)
+		}
+		// Every runner who was on is still on or came home.
+		gone := 0
+		for _, r := range []*Player{p.Before.FirstBase, p.Before.SecondBase, p.Before.ThirdBase} {
+			if r != nil && !p.After.onBase(r) {
+				gone++
+			}
+		}
+		if gone > p.Runs {
+			t.Fatalf("%d runners left the bases but %d scored: %v -> %v on %s", gone, p.Runs, p.Before, p.After, p.Outcome)
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		SimulateGame(lineup, cfg, r)
+	}
+	if plays == 0 {
+		t.Fatal("no plays traced")
+	}
+}
+
+func TestRunnerOnThirdHoldsOnSingle(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	r := rand.New(rand.NewSource(1))
+	held := func(p float64) (n int) {
+		cfg := DefaultGameConfig()
+		cfg.ScoreFromThirdOnSingle = p
+		cfg.Trace = func(p Play) {
+			if p.Outcome == HIT_SINGLE && p.Before.ThirdBase != nil && p.After.ThirdBase == p.Before.ThirdBase {
+				n++
+			}
+		}
+		for i := 0; i < 1000; i++ {
+			cfg.OutcomeOverride = script(HIT_TRIPLE, HIT_SINGLE)
+			g := Game{Rand: r}
+			SimulateInning(&g, lineup, 0, cfg, r)
+		}
+		return n
+	}
+	if n := held(1); n != 0 {
+		t.Errorf("runner held %d times when always sent", n)
+	}
+	if n := held(0.1); n < 800 {
+		t.Errorf("runner held %d of 1000 times at a 10%% send rate", n)
+	}
+}
+
+func TestWalksAndHBPCountSeparately(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	count := func(share float64) (walks, hbp int) {
+		cfg := DefaultGameConfig()
+		cfg.HBPShare = share
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 100; i++ {
+			g := SimulateGame(lineup, cfg, r)
+			walks += g.Walks
+			hbp += g.HBP
+		}
+		return walks, hbp
+	}
+	if w, h := count(0); w == 0 || h != 0 {
+		t.Errorf("no HBP share: %d walks and %d HBP", w, h)
+	}
+	if w, h := count(1); w != 0 || h == 0 {
+		t.Errorf("all HBP: %d walks and %d HBP", w, h)
+	}
+	if w, h := count(0.3); w <= h || h == 0 {
+		t.Errorf("30%% HBP: %d walks and %d HBP", w, h)
+	}
+
+	g := Game{Field: fieldOf("")}
+	g.Hit(HIT_BY_PITCH)
+	g.Field.AtBat = &onSecond
+	g.Hit(HIT_BY_PITCH)
+	g.Field.AtBat = &onThird
+	g.Hit(HIT_WALK)
+	if g.HBP != 2 || g.Walks != 1 {
+		t.Errorf("two HBP and a walk counted as %d HBP and %d walks", g.HBP, g.Walks)
+	}
+}
+
+func TestTwoPitchingChangesInOneInning(t *testing.T) {
+	// Every plate appearance against a lefty is a hit and every one
+	// against a righty an out.
+	platoon := Player{
+		LastName: "Platoon",
+		LHP:      Stats{AVG: 1, OBP: 1, SLUG: 1},
+		RHP:      Stats{AVG: 0, OBP: 0, SLUG: 0},
+	}
+	cfg := DefaultGameConfig()
+	cfg.PitcherHand = "right"
+	cfg.GIDPRate, cfg.DroppedThirdStrike = 0, 0
+	cfg.Steals = StealModel{}
+	r := rand.New(rand.NewSource(1))
+	g := NewGame(cfg, r)
+	var hits []bool
+	cfg.Trace = func(p Play) {
+		hits = append(hits, p.Outcome != HIT_OUT)
+		switch len(hits) {
+		case 1:
+			g.ChangePitcher("left")
+		case 3:
+			g.ChangePitcher("right")
+		}
+	}
+	SimulateInning(g, nineOf(platoon), 0, cfg, r)
+	want := []bool{false, true, true, false, false}
+	if fmt.Sprint(hits) != fmt.Sprint(want) {
+		t.Errorf("hits by plate appearance %v, want %v", hits, want)
+	}
+	if g.PitcherChanges != 2 || g.PitcherHand != "right" {
+		t.Errorf("%d changes ending with a %s-hander, want 2 ending right", g.PitcherChanges, g.PitcherHand)
+	}
+}
+
+func TestInfieldInHoldsRunnerOnThirdLate(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	r := rand.New(rand.NewSource(1))
+	scored := func(in InfieldIn, inning int) (n int) {
+		cfg := DefaultGameConfig()
+		cfg.InfieldIn = in
+		cfg.Trace = func(p Play) {
+			if p.Outcome == HIT_SINGLE && p.Before.ThirdBase != nil && p.Runs > 0 {
+				n++
+			}
+		}
+		for i := 0; i < 1000; i++ {
+			cfg.OutcomeOverride = script(HIT_TRIPLE, HIT_SINGLE)
+			g := Game{Rand: r, Inning: inning}
+			SimulateInning(&g, lineup, 0, cfg, r)
+		}
+		return n
+	}
+	if off, early := scored(InfieldIn{}, 8), scored(DefaultInfieldIn, 3); off != 1000 || early != 1000 {
+		t.Errorf("runner scored %d of 1000 times with the infield back, %d in the 3rd", off, early)
+	}
+	if late := scored(DefaultInfieldIn, 8); late > 650 || late < 450 {
+		t.Errorf("runner scored %d of 1000 times against the infield in, want about %.0f", late, 1000*DefaultInfieldIn.ScoreFromThird)
+	}
+}
+
+func TestProductiveOutAdvancesRunner(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	cfg := DefaultGameConfig()
+	cfg.ProductiveOutRate = 1
+	var plays []Play
+	cfg.Trace = func(p Play) { plays = append(plays, p) }
+	cfg.OutcomeOverride = script(HIT_DOUBLE, HIT_OUT, HIT_OUT)
+	r := rand.New(rand.NewSource(1))
+	g := Game{Rand: r}
+	runs, _, _ := SimulateInning(&g, lineup, 0, cfg, r)
+
+	// The first out moves the runner from second to third, the second
+	// scores them, and the third ends the inning.
+	first, second := plays[1], plays[2]
+	if first.Outs != 1 || occupants(first.After) != "- - "+lineup[0].LastName {
+		t.Errorf("after the first out: %d outs, bases %s", first.Outs, occupants(first.After))
+	}
+	if second.Outs != 2 || second.Runs != 1 || second.After.LOB() != 0 {
+		t.Errorf("after the second out: %d outs, %d runs, bases %s", second.Outs, second.Runs, occupants(second.After))
+	}
+	if runs != 1 || g.TotalOuts != 3 || len(plays) != 4 {
+		t.Errorf("%d runs and %d outs in %d plate appearances, want 1 and 3 in 4", runs, g.TotalOuts, len(plays))
+	}
+}
+
+func TestTenManLineupCyclesThroughTen(t *testing.T) {
+	lineup := append(nineOf(hitter("Avg", 0.330, 0.420)), hitter("Tenth", 0.330, 0.420))
+	cfg := DefaultGameConfig()
+	var slots []int
+	cfg.Trace = func(p Play) {
+		if p.Batter != &lineup[p.Slot] {
+			t.Fatalf("slot %d batted %s", p.Slot, p.Batter.LastName)
+		}
+		slots = append(slots, p.Slot)
+	}
+	SimulateGame(lineup, cfg, rand.New(rand.NewSource(1)))
+	if len(slots) < 20 {
+		t.Fatalf("only %d plate appearances", len(slots))
+	}
+	for i, s := range slots {
+		if s != i%10 {
+			t.Fatalf("plate appearance %d was slot %d, want %d", i+1, s, i%10)
+		}
+	}
+}
+
+func TestIntentionalWalkOnlyInItsSpot(t *testing.T) {
+	// ibbs plays an inning from inning in which the leadoff man doubles,
+	// slot 2 bats for real with first open and a runner on second, and
+	// everyone after makes an out. It returns the intentional walks.
+	ibbs := func(second Player, inning int) int {
+		lineup := nineOf(hitter("Avg", 0.330, 0.420))
+		lineup[1] = second
+		cfg := DefaultGameConfig()
+		cfg.IntentionalWalk = DefaultIntentionalWalk
+		cfg.GIDPRate = 0
+		cfg.OutcomeOverride = func(batter, _ int) (PlateOutcome, bool) {
+			switch batter {
+			case 0:
+				return HIT_DOUBLE, true
+			case 1:
+				return "", false
+			}
+			return HIT_OUT, true
+		}
+		r := rand.New(rand.NewSource(1))
+		g := Game{Rand: r, Inning: inning}
+		SimulateInning(&g, lineup, 0, cfg, r)
+		return g.IBB
+	}
+	slugger := hitter("Slugger", 0.360, 0.600)
+	if n := ibbs(slugger, 8); n != 1 {
+		t.Errorf("slugger walked %d times in the 8th with first open", n)
+	}
+	if n := ibbs(slugger, 3); n != 0 {
+		t.Errorf("slugger walked %d times in the 3rd", n)
+	}
+	if n := ibbs(hitter("Slap", 0.330, 0.380), 8); n != 0 {
+		t.Errorf("a .380 slugger walked %d times in the 8th", n)
+	}
+}
+
+func TestPitcherHandByInning(t *testing.T) {
+	// Lefties get this batter out every time; righties don't.
+	platoon := Player{
+		LastName: "Platoon",
+		LHP:      Stats{AVG: 0, OBP: 0, SLUG: 0},
+		RHP:      Stats{AVG: 0.400, OBP: 0.500, SLUG: 0.600},
+	}
+	cfg := DefaultGameConfig()
+	cfg.PitcherHandByInning = []string{"right", "right", "left", "left", "right", "left"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	var onBase [10]int
+	cfg.Trace = func(p Play) {
+		if p.Outcome != HIT_OUT && p.Inning <= 9 {
+			onBase[p.Inning]++
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		SimulateGame(nineOf(platoon), cfg, r)
+	}
+	// Innings past the script keep its last hand.
+	for inning, hand := range []string{"", "right", "right", "left", "left", "right", "left", "left", "left", "left"} {
+		switch {
+		case hand == "left" && onBase[inning] != 0:
+			t.Errorf("inning %d: %d reached against the scripted lefty", inning, onBase[inning])
+		case hand == "right" && onBase[inning] == 0:
+			t.Errorf("inning %d: nobody reached against the scripted righty", inning)
+		}
+	}
+}
+
+func TestScriptedGameHasAKnownScore(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	cfg := DefaultGameConfig()
+	// Two walks and a three-run homer in the first, a solo shot by the
+	// seventh hitter in the fifth, and outs otherwise.
+	cfg.OutcomeOverride = func(slot, inning int) (PlateOutcome, bool) {
+		switch {
+		case inning == 1 && slot < 2:
+			return HIT_WALK, true
+		case inning == 1 && slot == 2, inning == 5 && slot == 6:
+			return HIT_HOMERUN, true
+		}
+		return HIT_OUT, true
+	}
+	for s := int64(1); s <= 20; s++ {
+		g := SimulateGame(lineup, cfg, rand.New(rand.NewSource(s)))
+		if g.Runs != 4 || g.Hits != 2 || g.Walks != 2 || g.PA != 31 || g.TotalOuts != 27 {
+			t.Errorf("seed %d: %d runs, %d hits, %d walks, %d PA, %d outs; want 4, 2, 2, 31, 27", s, g.Runs, g.Hits, g.Walks, g.PA, g.TotalOuts)
+		}
+	}
+}
+
+func TestStrikeoutHitterMovesRunnersLessOften(t *testing.T) {
+	// A double puts a runner on second, then every out is traced for
+	// whether it moved the runner up.
+	moved := func(kRate float64) (n int) {
+		p := hitter("Avg", 0.330, 0.420)
+		p.KRate = kRate
+		lineup := nineOf(p)
+		cfg := DefaultGameConfig()
+		cfg.ProductiveOutRate = 0.5
+		cfg.SecondToThirdOnOut = 0
+		cfg.DroppedThirdStrike = 0
+		cfg.OutStretching = 0
+		cfg.Trace = func(p Play) {
+			if p.Outcome == HIT_OUT && p.OutsBefore == 0 && p.Before.SecondBase != nil && p.After.SecondBase == nil {
+				n++
+			}
+		}
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 1000; i++ {
+			cfg.OutcomeOverride = script(HIT_DOUBLE, HIT_OUT)
+			g := Game{Rand: r}
+			SimulateInning(&g, lineup, 0, cfg, r)
+		}
+		return n
+	}
+	contact, whiffer := moved(0), moved(0.30)
+	if contact < 400 || whiffer >= contact*3/4 {
+		t.Errorf("runner moved up on %d of 1000 outs by a contact hitter, %d by a strikeout hitter", contact, whiffer)
+	}
+}
+
+func TestRunnerOnSecondHeldOnDouble(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	r := rand.New(rand.NewSource(1))
+	held := func(p float64) (n int) {
+		cfg := DefaultGameConfig()
+		cfg.ScoreFromSecondOnDouble = p
+		cfg.OutStretching = 0
+		cfg.Trace = func(p Play) {
+			if p.Outcome == HIT_DOUBLE && p.Before.SecondBase != nil && p.After.ThirdBase == p.Before.SecondBase {
+				n++
+			}
+		}
+		for i := 0; i < 1000; i++ {
+			cfg.OutcomeOverride = script(HIT_DOUBLE, HIT_DOUBLE)
+			g := Game{Rand: r}
+			SimulateInning(&g, lineup, 0, cfg, r)
+		}
+		return n
+	}
+	if n := held(1); n != 0 {
+		t.Errorf("runner held %d times when always sent", n)
+	}
+	if n := held(0.5); n < 400 || n > 600 {
+		t.Errorf("runner held %d of 1000 times at a 50%% send rate", n)
+	}
+}
+
+func TestOutMovesLoneRunnerFromSecondToThird(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	r := rand.New(rand.NewSource(1))
+	moved := func(rate float64) (n int) {
+		cfg := DefaultGameConfig()
+		cfg.SecondToThirdOnOut = rate
+		cfg.ProductiveOutRate = 0
+		cfg.Trace = func(p Play) {
+			if p.Outcome == HIT_OUT && p.OutsBefore == 0 && p.Before.SecondBase != nil && p.After.ThirdBase == p.Before.SecondBase {
+				n++
+			}
+		}
+		for i := 0; i < 200; i++ {
+			cfg.OutcomeOverride = script(HIT_DOUBLE, HIT_OUT)
+			g := Game{Rand: r}
+			SimulateInning(&g, lineup, 0, cfg, r)
+		}
+		return n
+	}
+	if n := moved(1); n != 200 {
+		t.Errorf("runner moved to third on %d of 200 outs with the rule always on", n)
+	}
+	if n := moved(0); n != 0 {
+		t.Errorf("runner moved to third on %d outs with the rule off", n)
+	}
+}
+
+func TestDroppedThirdStrikePutsTheBatterOnFirst(t *testing.T) {
+	// Every out is a strikeout, with first base open for the leadoff hitter.
+	p := hitter("Whiff", 0.330, 0.420)
+	p.KRate = 1
+	lineup := nineOf(p)
+	cfg := DefaultGameConfig()
+	cfg.DroppedThirdStrike = 0.3
+	cfg.OutcomeOverride = always(HIT_OUT)
+	dropped := 0
+	cfg.Trace = func(p Play) {
+		if p.OutsBefore != 0 || p.Before.FirstBase != nil {
+			return
+		}
+		if !p.Strikeout {
+			t.Fatalf("leadoff out wasn't a strikeout: %+v", p)
+		}
+		if p.Dropped {
+			dropped++
+			if p.Outs != 0 || p.After.FirstBase != p.Batter {
+				t.Errorf("dropped third strike: %d outs, %v on first", p.Outs, p.After.FirstBase)
+			}
+		} else if p.Outs != 1 || p.After.FirstBase != nil {
+			t.Errorf("strikeout: %d outs, %v on first", p.Outs, p.After.FirstBase)
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		var g Game
+		SimulateInning(&g, lineup, 0, cfg, r)
+	}
+	if dropped < 200 || dropped > 400 {
+		t.Errorf("batter reached on %d of 1000 leadoff strikeouts at a 30%% rate", dropped)
+	}
+}
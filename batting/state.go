@@ -0,0 +1,49 @@
+package baseball
+
+import "fmt"
+
+// GameState is a point in a matchup to resume from: the half-inning in
+// progress, its outs and runners, and the score.
+type GameState struct {
+	Inning int
+	// Bottom is set when the home team is batting.
+	Bottom bool
+	Outs   int
+	// First, Second and Third mark the occupied bases.
+	First, Second, Third bool
+	HomeRuns, AwayRuns   int
+}
+
+// Validate reports whether st is a point a game can be resumed from.
+func (st GameState) Validate(cfg GameConfig) error {
+	if st.Inning < 1 {
+		return fmt.Errorf("inning must be positive, got %d", st.Inning)
+	}
+	if st.Outs < 0 || st.Outs >= cfg.OutsPerInning {
+		return fmt.Errorf("outs must be between 0 and %d, got %d", cfg.OutsPerInning-1, st.Outs)
+	}
+	if st.HomeRuns < 0 || st.AwayRuns < 0 {
+		return fmt.Errorf("runs must not be negative, got %d-%d", st.AwayRuns, st.HomeRuns)
+	}
+	if st.Bottom && st.Inning >= 9 && st.HomeRuns > st.AwayRuns {
+		return fmt.Errorf("the game is over: the home team leads %d-%d in the bottom of the %d", st.HomeRuns, st.AwayRuns, st.Inning)
+	}
+	return nil
+}
+
+// place puts st's runners on g's bases from the end of lineup, the batters
+// just before the top of the order, and returns st's outs.
+func (st GameState) place(g *Game, lineup []Player) int {
+	n := len(lineup)
+	prev := func(k int) *Player { return &lineup[((n-k)%n+n)%n] }
+	if st.First {
+		g.Field.placeRunner(1, prev(1))
+	}
+	if st.Second {
+		g.Field.placeRunner(2, prev(2))
+	}
+	if st.Third {
+		g.Field.placeRunner(3, prev(3))
+	}
+	return st.Outs
+}
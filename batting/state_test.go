@@ -0,0 +1,54 @@
+package baseball
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBasesLoadedNoOutsOutscoresEmptyTwoOut(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	cfg := DefaultGameConfig()
+	cfg.MaxExtraInnings = 1
+	mean := func(st GameState) float64 {
+		if err := st.Validate(cfg); err != nil {
+			t.Fatal(err)
+		}
+		r := rand.New(rand.NewSource(1))
+		runs := 0
+		for i := 0; i < 2000; i++ {
+			runs += SimulateMatchupFrom(lineup, lineup, st, cfg, r).Away.Runs
+		}
+		return float64(runs) / 2000
+	}
+	loaded := mean(GameState{Inning: 9, First: true, Second: true, Third: true})
+	empty := mean(GameState{Inning: 9, Outs: 2})
+	if loaded < empty+1 {
+		t.Errorf("%.3f runs from bases loaded and none out, %.3f from empty with two out", loaded, empty)
+	}
+}
+
+func TestFirstInningWithTwoOutsScoresLess(t *testing.T) {
+	lineup := nineOf(hitter("Avg", 0.330, 0.420))
+	firstInning := func(st *GameState) float64 {
+		cfg := DefaultGameConfig()
+		cfg.TrackSlots = true
+		cfg.FirstInning = st
+		if err := cfg.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		r := rand.New(rand.NewSource(1))
+		runs := 0
+		for i := 0; i < 2000; i++ {
+			runs += SimulateGame(lineup, cfg, r).LineScore[0]
+		}
+		return float64(runs) / 2000
+	}
+	none := firstInning(&GameState{First: true})
+	two := firstInning(&GameState{Outs: 2, First: true})
+	if two >= none {
+		t.Errorf("runner on first: %.3f first-inning runs with two out, %.3f with none", two, none)
+	}
+	if plain, zero := firstInning(nil), firstInning(&GameState{}); plain != zero {
+		t.Errorf("an empty first-inning state scored %.3f, a plain game %.3f", zero, plain)
+	}
+}
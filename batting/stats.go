@@ -0,0 +1,60 @@
+package baseball
+
+import (
+	"fmt"
+	"strings"
+)
+
+// League-average relationships used to estimate a missing split field.
+const (
+	DefaultWalkRate    = 0.070 // typical OBP - AVG gap
+	DefaultBasesPerHit = 1.55  // typical SLUG / AVG ratio
+)
+
+// Missing lists the names of the fields that are unset (zero).
+func (s Stats) Missing() []string {
+	var m []string
+	if s.AVG <= 0 {
+		m = append(m, "avg")
+	}
+	if s.OBP <= 0 {
+		m = append(m, "obp")
+	}
+	if s.SLUG <= 0 {
+		m = append(m, "slug")
+	}
+	return m
+}
+
+// NoWalks reports whether OBP doesn't exceed AVG. Walks are drawn from the gap
+// between the two, so such a split never walks or is hit by a pitch.
+func (s Stats) NoWalks() bool {
+	return s.OBP <= s.AVG
+}
+
+// FillMissing estimates a single missing field from the other two using the
+// league-average walk rate and bases per hit, and returns the name of the
+// field it imputed ("" when nothing was missing). Two or more missing fields
+// can't be estimated and return an error.
+func (s *Stats) FillMissing() (string, error) {
+	missing := s.Missing()
+	switch len(missing) {
+	case 0:
+		return "", nil
+	case 1:
+	default:
+		return "", fmt.Errorf("cannot impute %s from a single field", strings.Join(missing, " and "))
+	}
+	switch missing[0] {
+	case "avg":
+		s.AVG = s.OBP - DefaultWalkRate
+		if s.AVG <= 0 {
+			s.AVG = s.SLUG / DefaultBasesPerHit
+		}
+	case "obp":
+		s.OBP = s.AVG + DefaultWalkRate
+	case "slug":
+		s.SLUG = s.AVG * DefaultBasesPerHit
+	}
+	return missing[0], nil
+}
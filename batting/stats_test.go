@@ -0,0 +1,45 @@
+package baseball
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFillMissing(t *testing.T) {
+	for _, tc := range []struct {
+		in    Stats
+		field string
+		want  Stats
+	}{
+		{Stats{AVG: 0.270, OBP: 0.340, SLUG: 0.450}, "", Stats{AVG: 0.270, OBP: 0.340, SLUG: 0.450}},
+		{Stats{OBP: 0.340, SLUG: 0.450}, "avg", Stats{AVG: 0.340 - DefaultWalkRate, OBP: 0.340, SLUG: 0.450}},
+		{Stats{AVG: 0.270, SLUG: 0.450}, "obp", Stats{AVG: 0.270, OBP: 0.270 + DefaultWalkRate, SLUG: 0.450}},
+		{Stats{AVG: 0.270, OBP: 0.340}, "slug", Stats{AVG: 0.270, OBP: 0.340, SLUG: 0.270 * DefaultBasesPerHit}},
+		// An OBP below the walk rate leaves no room for AVG, so it comes
+		// from SLUG instead.
+		{Stats{OBP: 0.050, SLUG: 0.155}, "avg", Stats{AVG: 0.155 / DefaultBasesPerHit, OBP: 0.050, SLUG: 0.155}},
+	} {
+		s := tc.in
+		field, err := s.FillMissing()
+		if err != nil {
+			t.Errorf("%+v: %v", tc.in, err)
+			continue
+		}
+		if field != tc.field {
+			t.Errorf("%+v: filled %q, want %q", tc.in, field, tc.field)
+		}
+		if math.Abs(s.AVG-tc.want.AVG) > 1e-12 || math.Abs(s.OBP-tc.want.OBP) > 1e-12 || math.Abs(s.SLUG-tc.want.SLUG) > 1e-12 {
+			t.Errorf("%+v: got %+v, want %+v", tc.in, s, tc.want)
+		}
+	}
+}
+
+func TestFillMissingNeedsTwoFields(t *testing.T) {
+	s := Stats{OBP: 0.340}
+	if _, err := s.FillMissing(); err == nil {
+		t.Errorf("filled %+v from one field", s)
+	}
+	if s != (Stats{OBP: 0.340}) {
+		t.Errorf("a failed fill changed the split to %+v", s)
+	}
+}
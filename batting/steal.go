@@ -0,0 +1,62 @@
+package baseball
+
+import "math/rand"
+
+// StealModel is how often and how well runners on first try to steal
+// second. The zero value never steals.
+type StealModel struct {
+	// AttemptRate is the chance a league-average runner on first, with
+	// second open, goes before a plate appearance.
+	AttemptRate float64
+	// SuccessRate is a league-average runner's chance of being safe.
+	SuccessRate float64
+}
+
+// DefaultSteals is roughly the modern MLB running game.
+var DefaultSteals = StealModel{AttemptRate: 0.07, SuccessRate: 0.78}
+
+// odds returns a runner's attempt and success probabilities. Each foot per
+// second of sprint speed above league average adds 10% to the attempt rate
+// and 4 points to the success rate; zero speed (unknown) is average.
+func (sm StealModel) odds(speed float64) (attempt, success float64) {
+	d := 0.0
+	if speed > 0 {
+		d = speed - LeagueSprintSpeed
+	}
+	attempt = clamp(sm.AttemptRate*(1+0.1*d), 0, 1)
+	success = clamp(sm.SuccessRate+0.04*d, 0.05, 0.98)
+	return attempt, success
+}
+
+func clamp(x, lo, hi float64) float64 {
+	if x < lo {
+		return lo
+	}
+	if x > hi {
+		return hi
+	}
+	return x
+}
+
+// stealSecond gives the runner on first, with second open, a chance to
+// steal before the next plate appearance, scaled by their aggression. A
+// caught runner is out.
+func (g *Game) stealSecond(cfg GameConfig, r *rand.Rand) {
+	runner := g.Field.FirstBase
+	if cfg.Steals.AttemptRate <= 0 || runner == nil || g.Field.SecondBase != nil {
+		return
+	}
+	attempt, success := cfg.Steals.odds(runner.Speed)
+	attempt = clamp(attempt*runner.aggression(), 0, 1)
+	if r.Float64() >= attempt {
+		return
+	}
+	g.Field.clear(1)
+	if r.Float64() < success {
+		g.Field.placeRunner(2, runner)
+		g.SB++
+		return
+	}
+	g.CS++
+	g.Outs++
+}
@@ -0,0 +1,145 @@
+package baseball
+
+// PinchHit sends Player up for the batter in Slot (0-based) at that slot's
+// first plate appearance in Inning or later. It's skipped when Player is
+// already in the lineup.
+type PinchHit struct {
+	Inning int
+	Slot   int
+	Player Player
+}
+
+// DoubleSwitch brings Player into the game at the start of Inning for the
+// batter in slot Out (0-based), batting in Slot instead: whoever batted in
+// Slot moves up or down to Out. It's skipped when Player is already in the
+// lineup. With Slot equal to Out it's a plain substitution.
+type DoubleSwitch struct {
+	Inning int
+	Out    int
+	Player Player
+	Slot   int
+}
+
+// PinchRunning sends a fast bench player in to run for a slow one who
+// reaches base late in a close game. The runner takes over the base and
+// stays in the game in that batting slot. Each runner is used at most once
+// a game, and matchups ignore it. It's off while Runners is empty.
+type PinchRunning struct {
+	// FromInning is the first inning it's used in.
+	FromInning int
+	// MaxMargin is the largest lead either way that still counts as close,
+	// as for InfieldIn.
+	MaxMargin int
+	// MaxSpeed is the sprint speed below which a runner is replaced. A
+	// runner with an unknown (zero) speed never is.
+	MaxSpeed float64
+	// Runners are the bench players to send in, the fastest first; only
+	// one faster than the runner replaced is used.
+	Runners []Player
+}
+
+// DefaultPinchRunning runs for hitters slower than 26 ft/s from the 8th with
+// the game within a run, once its Runners are set.
+var DefaultPinchRunning = PinchRunning{FromInning: 8, MaxMargin: 1, MaxSpeed: 26}
+
+// Substitution records a player entering the game. In a double switch,
+// Moved is the player who changed places to make room and MovedTo their
+// new 0-based slot; both are zero otherwise. Inning is zero for a backup
+// starting in place of an unavailable player. Runner marks a pinch runner
+// taking the base for the player in Slot.
+type Substitution struct {
+	Inning  int    `json:"inning"`
+	Slot    int    `json:"slot"` // 0-based batting slot
+	Out     string `json:"out"`
+	In      string `json:"in"`
+	Moved   string `json:"moved,omitempty"`
+	MovedTo int    `json:"moved_to,omitempty"`
+	Runner  bool   `json:"pinch_run,omitempty"`
+}
+
+// pinchHit makes the first due pinch hit for slot, replacing the batter in
+// lineup and logging it in g.Subs.
+func (g *Game) pinchHit(cfg GameConfig, lineup []Player, slot int) {
+	for _, ph := range cfg.PinchHits {
+		if ph.Slot != slot || g.Inning < ph.Inning || g.pinchHitUsed(ph) || inLineup(lineup, ph.Player) {
+			continue
+		}
+		g.Subs = append(g.Subs, Substitution{Inning: g.Inning, Slot: slot, Out: lineup[slot].LastName, In: ph.Player.LastName})
+		lineup[slot] = ph.Player
+		return
+	}
+}
+
+// doubleSwitch makes cfg's double switches for the inning about to start,
+// reordering lineup and logging each in g.Subs.
+func (g *Game) doubleSwitch(cfg GameConfig, lineup []Player) {
+	for _, ds := range cfg.DoubleSwitches {
+		if ds.Inning != g.Inning || inLineup(lineup, ds.Player) {
+			continue
+		}
+		sub := Substitution{Inning: g.Inning, Slot: ds.Slot, Out: lineup[ds.Out].LastName, In: ds.Player.LastName}
+		if ds.Slot != ds.Out {
+			sub.Moved, sub.MovedTo = lineup[ds.Slot].LastName, ds.Out
+			lineup[ds.Out] = lineup[ds.Slot]
+		}
+		lineup[ds.Slot] = ds.Player
+		g.Subs = append(g.Subs, sub)
+	}
+}
+
+// pinchRun sends the fastest unused cfg.PinchRunning runner in for the
+// player in slot when they're on base and the rule calls for it. The
+// runner takes over slot in lineup, so the base, which points at it, now
+// holds them and every later baserunning decision uses their Speed.
+func (g *Game) pinchRun(cfg GameConfig, lineup []Player, slot int) {
+	pr := cfg.PinchRunning
+	p := &lineup[slot]
+	if g.Inning < pr.FromInning || p.Speed <= 0 || p.Speed >= pr.MaxSpeed || !g.Field.onBase(p) || !g.close(pr.MaxMargin) {
+		return
+	}
+	var runner *Player
+	for i := range pr.Runners {
+		q := &pr.Runners[i]
+		if q.Speed <= p.Speed || inLineup(lineup, *q) || g.entered(*q) {
+			continue
+		}
+		if runner == nil || q.Speed > runner.Speed {
+			runner = q
+		}
+	}
+	if runner == nil {
+		return
+	}
+	g.Subs = append(g.Subs, Substitution{Inning: g.Inning, Slot: slot, Out: p.LastName, In: runner.LastName, Runner: true})
+	*p = *runner
+}
+
+// entered reports whether p has come into this game as a substitute.
+func (g *Game) entered(p Player) bool {
+	for _, s := range g.Subs {
+		if s.In == p.LastName {
+			return true
+		}
+	}
+	return false
+}
+
+// pinchHitUsed reports whether ph has already been made this game.
+func (g *Game) pinchHitUsed(ph PinchHit) bool {
+	for _, s := range g.Subs {
+		if s.Slot == ph.Slot && s.In == ph.Player.LastName {
+			return true
+		}
+	}
+	return false
+}
+
+// inLineup reports whether p, by name, is in lineup.
+func inLineup(lineup []Player, p Player) bool {
+	for _, q := range lineup {
+		if q.FirstName == p.FirstName && q.LastName == p.LastName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package baseball
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestPinchHitLogged(t *testing.T) {
+	lineup := nineOf(hitter("Starter", 0.330, 0.420))
+	bench := hitter("Bench", 0.360, 0.500)
+	cfg := DefaultGameConfig()
+	// Three up, three down: slot 4 bats in the 2nd, 5th and 8th.
+	cfg.OutcomeOverride = always(HIT_OUT)
+	cfg.PinchHits = []PinchHit{{Inning: 7, Slot: 3, Player: bench}}
+
+	g := SimulateGame(lineup, cfg, rand.New(rand.NewSource(1)))
+	want := Substitution{Inning: 8, Slot: 3, Out: "Starter4", In: "Bench"}
+	if len(g.Subs) != 1 || g.Subs[0] != want {
+		t.Fatalf("substitutions %+v, want [%+v]", g.Subs, want)
+	}
+	if lineup[3].LastName != "Starter4" {
+		t.Error("the pinch hit changed the caller's lineup")
+	}
+}
+
+func TestDoubleSwitchReordersLaterAtBats(t *testing.T) {
+	lineup := nineOf(hitter("Starter", 0.330, 0.420))
+	cfg := DefaultGameConfig()
+	cfg.OutcomeOverride = always(HIT_OUT)
+	// Before the 4th, the ninth hitter leaves, the third hitter moves to
+	// the ninth spot and Bench bats third.
+	cfg.DoubleSwitches = []DoubleSwitch{{Inning: 4, Out: 8, Slot: 2, Player: hitter("Bench", 0.300, 0.400)}}
+	var order []string
+	cfg.Trace = func(p Play) { order = append(order, p.Batter.LastName) }
+
+	g := SimulateGame(lineup, cfg, rand.New(rand.NewSource(1)))
+	want := []string{
+		"Starter1", "Starter2", "Starter3", "Starter4", "Starter5", "Starter6", "Starter7", "Starter8", "Starter9",
+		"Starter1", "Starter2", "Bench", "Starter4", "Starter5", "Starter6", "Starter7", "Starter8", "Starter3",
+		"Starter1", "Starter2", "Bench", "Starter4", "Starter5", "Starter6", "Starter7", "Starter8", "Starter3",
+	}
+	if fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Errorf("batted\n%v\nwant\n%v", order, want)
+	}
+	if len(g.Subs) != 1 || g.Subs[0].Out != "Starter9" || g.Subs[0].Moved != "Starter3" {
+		t.Errorf("substitutions %+v", g.Subs)
+	}
+}
+
+func TestPinchRunnerBringsTheirSpeed(t *testing.T) {
+	slow := hitter("Slow", 0.330, 0.420)
+	slow.Speed = 24
+	lineup := nineOf(slow)
+	burner := hitter("Burner", 0.250, 0.300)
+	burner.Speed = 30
+	// A scoreless game until slot 4 leads off the 8th with a single and
+	// slot 5 follows with another.
+	scored := func(runners []Player) (games int) {
+		cfg := DefaultGameConfig()
+		cfg.ScoreFromFirstOnSingle = 0.3
+		cfg.PinchRunning = DefaultPinchRunning
+		cfg.PinchRunning.Runners = runners
+		cfg.OutcomeOverride = func(slot, inning int) (PlateOutcome, bool) {
+			if inning == 8 && (slot == 3 || slot == 4) {
+				return HIT_SINGLE, true
+			}
+			return HIT_OUT, true
+		}
+		cfg.Trace = func(p Play) {
+			if p.Inning == 8 && p.Slot == 4 && runners != nil && p.Before.FirstBase.LastName != "Burner" {
+				t.Fatalf("on first for the second single: %s", p.Before.FirstBase.LastName)
+			}
+		}
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 500; i++ {
+			g := SimulateGame(lineup, cfg, r)
+			if runners != nil && (len(g.Subs) != 1 || g.Subs[0] != Substitution{Inning: 8, Slot: 3, Out: "Slow4", In: "Burner", Runner: true}) {
+				t.Fatalf("substitutions %+v", g.Subs)
+			}
+			if g.Runs > 0 {
+				games++
+			}
+		}
+		return games
+	}
+	if n := scored(nil); n != 0 {
+		t.Errorf("a slow runner scored from first on a single in %d games", n)
+	}
+	if n := scored([]Player{burner}); n < 50 || n > 450 {
+		t.Errorf("the pinch runner scored from first on a single in %d of 500 games, want some but not all", n)
+	}
+}
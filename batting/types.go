@@ -1,55 +1,194 @@
 package baseball
 
 import (
+	"fmt"
 	"math/rand"
-	"time"
+	"strings"
 )
 
-const HIT_SINGLE = "single"
-const HIT_DOUBLE = "double"
-const HIT_TRIPLE = "triple"
-const HIT_HOMERUN = "home_run"
-const HIT_BY_PITCH_WALK = "walk_hbp"
-const HIT_OUT = "out"
+// PlateOutcome is the result of a plate appearance. The values are the
+// strings used in traces and JSON, so encoding needs no custom marshaler.
+type PlateOutcome string
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
+const (
+	HIT_SINGLE   PlateOutcome = "single"
+	HIT_DOUBLE   PlateOutcome = "double"
+	HIT_TRIPLE   PlateOutcome = "triple"
+	HIT_HOMERUN  PlateOutcome = "home_run"
+	HIT_WALK     PlateOutcome = "walk"
+	HIT_BY_PITCH PlateOutcome = "hbp"
+	HIT_OUT      PlateOutcome = "out"
+
+	// Deprecated: walks and hit-by-pitches are now separate outcomes; use
+	// HIT_WALK or HIT_BY_PITCH.
+	HIT_BY_PITCH_WALK = HIT_WALK
+)
+
+func (o PlateOutcome) String() string { return string(o) }
+
+// Valid reports whether o is one of the defined outcomes.
+func (o PlateOutcome) Valid() bool {
+	switch o {
+	case HIT_SINGLE, HIT_DOUBLE, HIT_TRIPLE, HIT_HOMERUN, HIT_WALK, HIT_BY_PITCH, HIT_OUT:
+		return true
+	}
+	return false
+}
+
+// UnmarshalText rejects anything but a defined outcome.
+func (o *PlateOutcome) UnmarshalText(b []byte) error {
+	v := PlateOutcome(b)
+	if !v.Valid() {
+		return fmt.Errorf("unknown plate outcome %q", b)
+	}
+	*o = v
+	return nil
 }
 
 type Player struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+	Position  string `json:"position,omitempty"` // e.g. "SS" or "2B/SS"; see CanPlay
 	LHP       Stats  `json:"LHP"`
 	RHP       Stats  `json:"RHP"`
+	// Speed is Statcast sprint speed in feet per second; zero means unknown.
+	// https://baseballsavant.mlb.com/leaderboard/sprint_speed
+	Speed float64 `json:"speed,omitempty"`
+	// Aggression scales how often they take the extra base as a runner:
+	// scoring from second on a single, scoring from first on a double, and
+	// trying to steal. 1 is neutral, as is zero (unset).
+	Aggression float64 `json:"aggression,omitempty"`
+	// KRate is their strikeouts per plate appearance. That share of their
+	// outs are strikeouts, which never advance a runner or turn into a
+	// double play; zero makes every out a ball in play.
+	KRate float64 `json:"k_rate,omitempty"`
+	// Availability is the chance they're fit to play any one game; when
+	// they aren't, GameConfig.Backups says who bats in their place. 1 is
+	// always available, as is zero (unset).
+	Availability float64 `json:"availability,omitempty"`
+	// RecentLHP and RecentRHP are optional recent-form splits (say, the
+	// last 30 days), blended into LHP and RHP by GameConfig.RecentWeight.
+	RecentLHP *Stats `json:"recent_lhp,omitempty"`
+	RecentRHP *Stats `json:"recent_rhp,omitempty"`
+	// RISPLHP and RISPRHP are optional splits with runners in scoring
+	// position, used in place of LHP and RHP whenever second or third is
+	// occupied.
+	RISPLHP *Stats `json:"risp_lhp,omitempty"`
+	RISPRHP *Stats `json:"risp_rhp,omitempty"`
+}
+
+// aggression returns p.Aggression, with zero meaning neutral.
+func (p *Player) aggression() float64 {
+	if p.Aggression == 0 {
+		return 1
+	}
+	return p.Aggression
 }
 
-//TODO add speed:
-//https://baseballsavant.mlb.com/leaderboard/sprint_speed?min_season=2025&max_season=2025&position=&team=143&min=10
+// strikeoutShare is the fraction of p's outs that are strikeouts when they
+// hit with stats: KRate over their out rate, 1 - OBP, capped at 1.
+func (p *Player) strikeoutShare(stats Stats) float64 {
+	if p.KRate <= 0 {
+		return 0
+	}
+	if stats.OBP >= 1 {
+		return 1
+	}
+	return clamp(p.KRate/(1-stats.OBP), 0, 1)
+}
+
+// PlateAppearance draws one outcome for p against a pitcher of the given
+// hand, with risp set when a runner is in scoring position.
+func (p Player) PlateAppearance(LRPitcher string, risp bool, r *rand.Rand) PlateOutcome {
+	return plateAppearance(p.SituationalSplit(LRPitcher, risp), p.Speed, DefaultGameConfig(), r)
+}
+
+// SituationalSplit returns p's stats against a pitcher of the given hand:
+// the RISP split when risp is set and the file has one, and otherwise
+// Split's.
+func (p Player) SituationalSplit(LRPitcher string, risp bool) Stats {
+	if risp {
+		s := p.RISPRHP
+		if LRPitcher == "left" {
+			s = p.RISPLHP
+		}
+		if s != nil {
+			return *s
+		}
+	}
+	return p.Split(LRPitcher)
+}
 
-func (p Player) PlateAppearance(LRPitcher string, r *rand.Rand) string {
-	// Choose splits based on pitcher handedness input ("left" uses LHP, otherwise RHP)
-	var s Stats
+// RecentSplit returns p's recent-form stats against a pitcher of the given
+// hand, or nil if the file has none.
+func (p Player) RecentSplit(LRPitcher string) *Stats {
 	if LRPitcher == "left" {
-		s = p.LHP
-	} else {
-		s = p.RHP
+		return p.RecentLHP
+	}
+	return p.RecentRHP
+}
+
+// Split returns p's stats against a pitcher of the given hand ("left" uses
+// LHP, otherwise RHP).
+func (p Player) Split(LRPitcher string) Stats {
+	if LRPitcher == "left" {
+		return p.LHP
 	}
+	return p.RHP
+}
+
+// plateAppearance draws one outcome for a batter with stats s and sprint
+// speed speed. Walks take the OBP - AVG band, so a split with OBP equal to
+// AVG never walks; see Stats.NoWalks. The top cfg.HBPShare of that band is
+// hit-by-pitches.
+func plateAppearance(s Stats, speed float64, cfg GameConfig, r *rand.Rand) PlateOutcome {
 	u := r.Float64()
 	// Outcome by OBP/AVG thresholds
 	if u > s.OBP {
+		// A poor defense turns the bottom of the out band into singles.
+		if d := cfg.OpponentDefense; d > 1 && u <= s.OBP+(d-1)*s.AVG*(1-HitMix(s, speed, cfg)[3]) {
+			return HIT_SINGLE
+		}
 		return HIT_OUT
 	}
 	if u > s.AVG { // u <= OBP here
-		return HIT_BY_PITCH_WALK
+		if u > s.OBP-cfg.HBPShare*(s.OBP-s.AVG) {
+			return HIT_BY_PITCH
+		}
+		return HIT_WALK
 	}
 	// It's a hit: decide which kind
-	return hitType(s.AVG, s.SLUG, r)
+	var hit PlateOutcome
+	if s.HasHitMix() {
+		hit = observedHitType(s, cfg.Park, r)
+	} else {
+		hit = hitType(s.AVG, s.SLUG, speed, cfg.Park, cfg.Model.orDefault(), r)
+	}
+	// A good defense runs down some of the balls in play.
+	if d := cfg.OpponentDefense; d > 0 && d < 1 && hit != HIT_HOMERUN && r.Float64() >= d {
+		return HIT_OUT
+	}
+	return hit
 }
 
 type Stats struct {
 	AVG  float64 `json:"avg"`
 	OBP  float64 `json:"obp"`
 	SLUG float64 `json:"slug"`
+	// Double, Triple and HomeRun are the observed shares of hits that go
+	// for extra bases. When any is set, hitType uses them, with singles
+	// taking the rest, instead of deriving the mix from SLUG / AVG.
+	Double  float64 `json:"double,omitempty"`
+	Triple  float64 `json:"triple,omitempty"`
+	HomeRun float64 `json:"home_run,omitempty"`
+	// PA is the plate appearances behind the split, for
+	// GameConfig.ShrinkPA; zero means unknown and is never shrunk.
+	PA int `json:"pa,omitempty"`
+}
+
+// HasHitMix reports whether s carries an observed extra-base mix.
+func (s Stats) HasHitMix() bool {
+	return s.Double > 0 || s.Triple > 0 || s.HomeRun > 0
 }
 
 type Field struct {
@@ -59,6 +198,24 @@ type Field struct {
 	ThirdBase  *Player `json:"third_base"`
 }
 
+// String lists the occupied bases, e.g. "1B 3B", or "empty".
+func (f Field) String() string {
+	var bases []string
+	if f.FirstBase != nil {
+		bases = append(bases, "1B")
+	}
+	if f.SecondBase != nil {
+		bases = append(bases, "2B")
+	}
+	if f.ThirdBase != nil {
+		bases = append(bases, "3B")
+	}
+	if len(bases) == 0 {
+		return "empty"
+	}
+	return strings.Join(bases, " ")
+}
+
 func (f Field) LOB() int {
 	b := 0
 	if f.FirstBase != nil {
@@ -73,6 +230,49 @@ func (f Field) LOB() int {
 	return b
 }
 
+// AdvanceAll moves the batter and every runner forward the given number of
+// bases (4 brings everyone home) and returns how many crossed the plate.
+func (f *Field) AdvanceAll(bases int) (runs int) {
+	from := [4]*Player{f.AtBat, f.clear(1), f.clear(2), f.clear(3)}
+	f.AtBat = nil
+	for base, p := range from {
+		if p == nil {
+			continue
+		}
+		if base+bases >= 4 {
+			runs++
+			continue
+		}
+		f.placeRunner(base+bases, p)
+	}
+	return runs
+}
+
+// forceAdvance moves only the runners forced by the batter taking first, as on
+// a walk or hit-by-pitch, and returns the runs forced in. A runner is forced
+// only when every base behind them is occupied, so the outcomes by base state are:
+//
+//	empty, 2B, 3B, 2B+3B: nobody moves
+//	1B, 1B+3B:            1B -> 2B (an unforced 3B holds)
+//	1B+2B:                2B -> 3B, 1B -> 2B
+//	loaded:               3B scores, 2B -> 3B, 1B -> 2B
+//
+// First base is left empty for the batter.
+func (f *Field) forceAdvance() (runs int) {
+	if f.FirstBase == nil {
+		return 0
+	}
+	if f.SecondBase != nil {
+		if f.ThirdBase != nil {
+			f.clear(3)
+			runs++
+		}
+		f.moveRunner(2, 3)
+	}
+	f.moveRunner(1, 2)
+	return runs
+}
+
 func (g *Game) AddLOB(lob int) {
 	g.LOB += lob
 }
@@ -88,118 +288,207 @@ func (g *Game) currentBatterSlug() float64 {
 	return g.Field.AtBat.RHP.SLUG
 }
 
-func (g *Game) Hit(hittype string) {
-	if hittype == HIT_BY_PITCH_WALK {
-		// Force-only advances on walk/HBP
-		// If 1B is occupied, it forces runners forward; 3B only scores when bases are loaded.
-		if g.Field.FirstBase != nil {
-			if g.Field.SecondBase != nil {
-				if g.Field.ThirdBase != nil {
-					// Bases loaded: force in a run
-					g.Field.ThirdBase = nil
-					g.Runs++
-				}
-				// Force 2B -> 3B
-				g.Field.ThirdBase = g.Field.SecondBase
-				g.Field.SecondBase = nil
+// Hit applies a batter reaching base with hittype, advancing and scoring
+// runners. It panics on an invalid outcome.
+func (g *Game) Hit(hittype PlateOutcome) {
+	if !hittype.Valid() {
+		panic(fmt.Sprintf("baseball: invalid plate outcome %q", string(hittype)))
+	}
+	batter := g.Field.AtBat
+	if g.Slots != nil {
+		if i := g.slotOf(batter); i >= 0 {
+			g.Slots[i].TimesOnBase++
+			switch hittype {
+			case HIT_WALK:
+				g.Slots[i].Walks++
+			case HIT_BY_PITCH:
+				g.Slots[i].HBP++
+			default:
+				g.Slots[i].Hits++
+			}
+			if hittype == HIT_DOUBLE && !g.stretching || hittype == HIT_TRIPLE || hittype == HIT_HOMERUN {
+				g.Slots[i].ExtraBaseHits++
 			}
-			// Force 1B -> 2B
-			g.Field.SecondBase = g.Field.FirstBase
-			g.Field.FirstBase = nil
 		}
-		g.Field.FirstBase = g.Field.AtBat
+	}
+	if hittype == HIT_WALK {
+		g.Walks++
+	} else if hittype == HIT_BY_PITCH {
+		g.HBP++
+	}
+	// A hit-by-pitch moves runners exactly like a walk.
+	if hittype == HIT_WALK || hittype == HIT_BY_PITCH {
+		if third := g.Field.ThirdBase; g.Field.forceAdvance() > 0 {
+			g.score(third, batter)
+		}
+		g.Field.placeRunner(1, g.Field.AtBat)
 		g.Field.AtBat = nil
 	}
 	if hittype == HIT_SINGLE {
 		g.Hits++
-		if g.Field.ThirdBase != nil {
-			g.Field.ThirdBase = nil
-			g.Runs++
-		}
-		// With some probability, the runner from 2B scores; otherwise advances to 3B.
-		if g.Field.SecondBase != nil {
-			p := probScoreFromSecondOnSingle(g.currentBatterSlug())
-			if rand.Float64() < p {
-				g.Field.SecondBase = nil
-				g.Runs++
-			} else {
-				g.Field.ThirdBase = g.Field.SecondBase
-				g.Field.SecondBase = nil
-			}
-		}
-		if g.Field.FirstBase != nil {
-			g.Field.SecondBase = g.Field.FirstBase
-			g.Field.FirstBase = nil
-		}
-		g.Field.FirstBase = g.Field.AtBat
-		g.Field.AtBat = nil
+		runs := g.Runs
+		g.single(batter)
+		g.advanceOnThrow(g.Runs > runs)
 	}
 	if hittype == HIT_DOUBLE {
 		g.Hits++
-		// Any runner on 3B scores
-		if g.Field.ThirdBase != nil {
-			g.Field.ThirdBase = nil
-			g.Runs++
-		}
-		// Any runner on 2B scores
-		if g.Field.SecondBase != nil {
-			g.Field.SecondBase = nil
-			g.Runs++
-		}
+		runs := g.Runs
 		// Runner on 1B sometimes scores on a double; otherwise goes to 3B
+		firstScores := false
 		if g.Field.FirstBase != nil {
-			p := probScoreFromFirstOnDouble(g.currentBatterSlug())
-			if rand.Float64() < p {
-				g.Field.FirstBase = nil
-				g.Runs++
-			} else {
-				g.Field.ThirdBase = g.Field.FirstBase
-				g.Field.FirstBase = nil
+			p := probScoreFromFirstOnDouble(g.currentBatterSlug(), g.model.orDefault().ScoreFromFirstOnDouble)
+			firstScores = g.float64() < clamp(p*g.Field.FirstBase.aggression(), 0, 1)
+		}
+		// An unforced runner on 2B is sometimes held at 3B by the relay.
+		holdSecond := false
+		if f := &g.Field; f.SecondBase != nil && f.FirstBase == nil && g.holdSecond > 0 {
+			holdSecond = g.float64() < g.holdSecond
+		}
+		if holdSecond {
+			if third := g.Field.ThirdBase; third != nil {
+				g.score(g.Field.clear(3), batter)
+			}
+			g.Field.moveRunner(2, 3)
+			g.Field.placeRunner(2, g.Field.AtBat)
+			g.Field.AtBat = nil
+		} else {
+			// Runners on 2B and 3B score, 1B -> 3B, batter to 2B
+			g.advanceAll(2, batter)
+			if firstScores {
+				g.score(g.Field.clear(3), batter)
 			}
 		}
-		// Batter to 2B
-		g.Field.SecondBase = g.Field.AtBat
-		g.Field.AtBat = nil
+		if g.stretching {
+			// The batter, on second, is tagged out there.
+			g.Field.clear(2)
+			g.Outs++
+		} else {
+			g.advanceOnThrow(g.Runs > runs)
+		}
 	}
 	if hittype == HIT_TRIPLE {
 		g.Hits++
-		if g.Field.ThirdBase != nil {
-			g.Field.ThirdBase = nil
-			g.Runs++
+		g.advanceAll(3, batter)
+	}
+	if hittype == HIT_HOMERUN {
+		g.Hits++
+		g.advanceAll(4, batter)
+	}
+}
+
+// single moves the runners and batter on a single. Runners are settled lead
+// first so each base is vacated before anyone is sent to it:
+//
+//	3B:  scores, unless it holds (only when the bases aren't loaded)
+//	2B:  scores or takes 3B if 3B is now empty; otherwise stays at 2B,
+//	     which can only happen with 1B empty
+//	1B:  to 2B, which is empty by then
+//	batter to 1B
+//
+// So 1B+2B ends with runners on 1B and 2B plus either one run or a runner on
+// 3B, and a loaded single always scores at least one.
+func (g *Game) single(batter *Player) {
+	f := &g.Field
+	aloneOnFirst := f.FirstBase != nil && f.SecondBase == nil && f.ThirdBase == nil
+	if runner := f.ThirdBase; runner != nil {
+		forced := f.FirstBase != nil && f.SecondBase != nil
+		if forced || g.holdThird <= 0 || g.float64() >= g.holdThird {
+			g.score(f.clear(3), batter)
 		}
-		if g.Field.SecondBase != nil {
-			g.Field.SecondBase = nil
-			g.Runs++
+	}
+	if runner := f.SecondBase; runner != nil && f.ThirdBase == nil {
+		p := probScoreFromSecondOnSingle(g.currentBatterSlug(), g.model.orDefault().ScoreFromSecond)
+		if g.float64() < clamp(p*runner.aggression(), 0, 1) {
+			g.score(f.clear(2), batter)
+		} else {
+			f.moveRunner(2, 3)
 		}
-		if g.Field.FirstBase != nil {
-			g.Field.FirstBase = nil
-			g.Runs++
+	}
+	if runner := f.FirstBase; runner != nil {
+		if aloneOnFirst && g.firstToHome > 0 && runner.Speed > LeagueSprintSpeed &&
+			g.float64() < clamp(g.firstToHome*speedTripleFactor(runner.Speed)*runner.aggression(), 0, 1) {
+			g.score(f.clear(1), batter)
+		} else {
+			f.moveRunner(1, 2)
 		}
-		g.Field.ThirdBase = g.Field.AtBat
-		g.Field.AtBat = nil
 	}
-	if hittype == HIT_HOMERUN {
-		g.Hits++
-		if g.Field.ThirdBase != nil {
-			g.Field.ThirdBase = nil
-			g.Runs++
+	f.placeRunner(1, f.AtBat)
+	f.AtBat = nil
+}
+
+// advanceOnThrow sends the trailing runner up one base on the throw home,
+// with chance extraOnThrow, after a hit that scored: the runner on second
+// to an empty third, else the one on first to an empty second.
+func (g *Game) advanceOnThrow(scored bool) {
+	if !scored || g.extraOnThrow <= 0 {
+		return
+	}
+	f := &g.Field
+	for _, base := range []int{2, 1} {
+		if *f.base(base) != nil && *f.base(base + 1) == nil {
+			if g.float64() < g.extraOnThrow {
+				f.moveRunner(base, base+1)
+			}
+			return
 		}
-		if g.Field.SecondBase != nil {
-			g.Field.SecondBase = nil
-			g.Runs++
+	}
+}
+
+// advanceLead moves the lead runner in scoring position up one base on
+// batter's out, scoring them from third.
+func (g *Game) advanceLead(batter *Player) {
+	f := &g.Field
+	switch {
+	case f.ThirdBase != nil:
+		g.score(f.clear(3), batter)
+	case f.SecondBase != nil:
+		f.moveRunner(2, 3)
+	}
+}
+
+// advanceAll is Field.AdvanceAll with every runner who crosses the plate
+// scored through score.
+func (g *Game) advanceAll(bases int, batter *Player) {
+	from := [4]*Player{g.Field.AtBat, g.Field.FirstBase, g.Field.SecondBase, g.Field.ThirdBase}
+	g.Field.AdvanceAll(bases)
+	for base, p := range from {
+		if p != nil && base+bases >= 4 {
+			g.score(p, batter)
 		}
-		if g.Field.FirstBase != nil {
-			g.Field.FirstBase = nil
-			g.Runs++
+	}
+}
+
+// score records a run by runner and credits the RBI to batter, if any. A
+// nil batter is a run without an RBI, counted in NoRBI.
+func (g *Game) score(runner, batter *Player) {
+	g.Runs++
+	if batter == nil {
+		g.NoRBI++
+	}
+	if g.Slots == nil {
+		return
+	}
+	if i := g.slotOf(runner); i >= 0 {
+		g.Slots[i].Runs++
+	}
+	if i := g.slotOf(batter); i >= 0 {
+		g.Slots[i].RBI++
+	}
+}
+
+// slotOf returns p's batting slot in the lineup being simulated, or -1.
+func (g *Game) slotOf(p *Player) int {
+	for i := range g.lineup {
+		if &g.lineup[i] == p {
+			return i
 		}
-		g.Runs++
-		g.Field.AtBat = nil
 	}
+	return -1
 }
 
 // probScoreFromSecondOnSingle maps batter SLUG to a probability that a runner on second scores on a single.
-// Calibrated to produce roughly 0.38–0.72 across SLUG 0.350–0.600, with sensible clamping.
-func probScoreFromSecondOnSingle(slug float64) float64 {
+// It maps SLUG 0.350–0.600 linearly onto band (0.38–0.72 in DefaultModel), with sensible clamping.
+func probScoreFromSecondOnSingle(slug float64, band [2]float64) float64 {
 	// Default to a league-average-ish SLUG if missing
 	if slug <= 0 {
 		slug = 0.400
@@ -212,18 +501,18 @@ func probScoreFromSecondOnSingle(slug float64) float64 {
 		slug = 0.600
 	}
 
-	// Linearly map slug in [0.350, 0.600] to probability in [0.38, 0.72]
+	// Linearly map slug in [0.350, 0.600] to probability in band
 	minS, maxS := 0.350, 0.600
-	minP, maxP := 0.38, 0.72
+	minP, maxP := band[0], band[1]
 	t := (slug - minS) / (maxS - minS)
 	return minP + t*(maxP-minP)
 }
 
 // probScoreFromFirstOnDouble maps batter SLUG to a probability that a runner on first
-// scores on a double. Calibrated to produce roughly 0.32–0.62 across SLUG 0.350–0.600,
-// with sensible clamping. This is slightly higher-impact than 2B->home on a single,
-// but still bounded by realistic MLB baselines.
-func probScoreFromFirstOnDouble(slug float64) float64 {
+// scores on a double. It maps SLUG 0.350–0.600 linearly onto band (0.32–0.62 in
+// DefaultModel), with sensible clamping. This is slightly higher-impact than 2B->home
+// on a single, but still bounded by realistic MLB baselines.
+func probScoreFromFirstOnDouble(slug float64, band [2]float64) float64 {
 	// Default to a league-average-ish SLUG if missing
 	if slug <= 0 {
 		slug = 0.400
@@ -236,22 +525,131 @@ func probScoreFromFirstOnDouble(slug float64) float64 {
 		slug = 0.600
 	}
 
-	// Linearly map slug in [0.350, 0.600] to probability in [0.32, 0.62]
+	// Linearly map slug in [0.350, 0.600] to probability in band
 	minS, maxS := 0.350, 0.600
-	minP, maxP := 0.32, 0.62
+	minP, maxP := band[0], band[1]
 	t := (slug - minS) / (maxS - minS)
 	return minP + t*(maxP-minP)
 }
 
+// Game is one team's offense in a game. Build one with NewGame (or let
+// SimulateGame and SimulateMatchup do it) and drive it with SimulateInning.
+// Hits, Runs, LOB, PA, SB, CS, PitcherChanges, Slots and LineScore are
+// results the simulation accumulates; read them, but setting them leaves
+// the game inconsistent.
 type Game struct {
 	Hits        int
 	Runs        int
 	LOB         int
+	PA          int // plate appearances
+	Outs        int // outs in the current half-inning
+	TotalOuts   int // outs recorded in the game so far
+	Inning      int
 	Field       Field
-	PitcherHand string // "left" or "right"
+	PitcherHand string     // "left" or "right"; the pitcher currently in
+	Home        bool       // batting as the home team in a matchup
+	Rand        *rand.Rand // source for baserunning decisions; SimulateGame sets it to the game's source, nil uses the global one
+
+	// PitcherChanges counts the relievers brought in so far.
+	PitcherChanges int
+	// Walks and HBP count batters walked (intentionally or not) and hit by
+	// pitches.
+	Walks, HBP int
+	// SB and CS count stolen bases and runners caught stealing, IBB
+	// intentional walks, WP wild pitches, and SO strikeouts.
+	SB, CS, IBB, WP, SO int
+	// NoRBI counts runs that scored without an RBI: on a wild pitch or a
+	// dropped third strike.
+	NoRBI int
+
+	// Subs logs the substitutions made, in order.
+	Subs []Substitution
+
+	// Slots holds per-slot contributions and LineScore the runs scored in
+	// each inning; both are recorded only when cfg.TrackSlots is set.
+	Slots     []SlotStats
+	LineScore []int
+	lineup    []Player
+	// pitcher is the starter this offense faces all game, or nil for a
+	// league-average staff.
+	pitcher *Pitcher
+	// model is the calibration in use, set each inning from cfg.Model.
+	model Model
+	// holdThird is the chance an unforced runner on third holds on a
+	// single, set before each plate appearance.
+	holdThird float64
+	// holdSecond is the chance an unforced runner on second holds at third
+	// on a double, set before each plate appearance.
+	holdSecond float64
+	// stretching is set by the simulation when the next double has the
+	// batter thrown out trying for second (cfg.OutStretching).
+	stretching bool
+	// firstToHome is cfg.ScoreFromFirstOnSingle, set before each plate
+	// appearance.
+	firstToHome float64
+	// extraOnThrow is cfg.ExtraBaseOnThrow, set before each plate
+	// appearance.
+	extraOnThrow float64
+	// oppRuns is the other side's score in a matchup, kept current by
+	// SimulateMatchup when vsOpponent is set.
+	oppRuns    int
+	vsOpponent bool
+	// pinchHitting and pinchRunning are set when SimulateGame is playing
+	// cfg.PinchHits or cfg.PinchRunning on its own copy of the lineup.
+	pinchHitting, pinchRunning bool
 }
 
-func (g *Game) StartPitcher(r *rand.Rand) {
+// SlotStats is one batting slot's contribution to a game.
+type SlotStats struct {
+	PA            int `json:"pa"`
+	Hits          int `json:"hits"`
+	Walks         int `json:"walks"`
+	HBP           int `json:"hbp"`
+	RBI           int `json:"rbi"`
+	Runs          int `json:"runs"`
+	TimesOnBase   int `json:"times_on_base"`
+	ExtraBaseHits int `json:"extra_base_hits"`
+}
+
+// float64 draws from the game's random source, falling back to the global one.
+func (g *Game) float64() float64 {
+	if g.Rand != nil {
+		return g.Rand.Float64()
+	}
+	return rand.Float64()
+}
+
+// NewGame returns a game with the bases empty, no score, r attached for
+// baserunning decisions, and its starting pitcher's hand picked per
+// cfg.PitcherHand. Slot tracking starts with the first inning played when
+// cfg.TrackSlots is set.
+func NewGame(cfg GameConfig, r *rand.Rand) *Game {
+	g := &Game{Rand: r}
+	g.StartPitcher(cfg, r)
+	return g
+}
+
+// Pitcher returns the named starter this offense faces, or nil for a
+// league-average staff.
+func (g *Game) Pitcher() *Pitcher {
+	return g.pitcher
+}
+
+// StartPitcher picks the starting pitcher's hand, honoring
+// cfg.PitcherHandByInning or cfg.PitcherHand when set.
+func (g *Game) StartPitcher(cfg GameConfig, r *rand.Rand) {
+	if len(cfg.PitcherHandByInning) > 0 {
+		g.PitcherHand = cfg.handForInning(1)
+		return
+	}
+	if g.pitcher != nil && g.pitcher.Hand != "" {
+		g.PitcherHand = g.pitcher.Hand
+		return
+	}
+	if cfg.PitcherHand != "" {
+		g.PitcherHand = cfg.PitcherHand
+		return
+	}
 	if r.Float64() < 0.3 {
 		g.PitcherHand = "left"
 	} else {
@@ -259,28 +657,72 @@ func (g *Game) StartPitcher(r *rand.Rand) {
 	}
 }
 
-func (g *Game) MaybeChangePitcher(inning int, changed *bool, r *rand.Rand) {
-	if *changed {
+// MaybeChangePitcher may bring in a reliever between the 5th and 9th innings,
+// up to cfg.MaxPitcherChanges times a game. A fixed cfg.PitcherHand or a
+// named starter disables changes, and cfg.PitcherHandByInning replaces them
+// with its scripted hand for the inning.
+func (g *Game) MaybeChangePitcher(cfg GameConfig, inning int, r *rand.Rand) {
+	if len(cfg.PitcherHandByInning) > 0 {
+		g.PitcherHand = cfg.handForInning(inning)
+		return
+	}
+	if g.PitcherChanges >= cfg.MaxPitcherChanges || cfg.PitcherHand != "" || g.pitcher != nil {
 		return
 	}
 	if inning >= 5 && inning <= 9 {
 		if r.Float64() < 0.5 {
 			// Change pitcher
 			if r.Float64() < 0.3 {
-				g.PitcherHand = "left"
+				g.ChangePitcher("left")
 			} else {
-				g.PitcherHand = "right"
+				g.ChangePitcher("right")
 			}
-			*changed = true
 		}
 	}
 }
 
-func hitType(avg, slug float64, r *rand.Rand) string {
+// ChangePitcher brings in a league-average reliever of the given hand. It
+// can be called any number of times, including mid-inning; every later
+// plate appearance uses the batters' splits against the new hand.
+func (g *Game) ChangePitcher(hand string) {
+	g.PitcherHand = hand
+	g.pitcher = nil
+	g.PitcherChanges++
+}
+
+// LeagueSprintSpeed is the MLB-average sprint speed in feet per second; a
+// runner this fast gets the SLUG-only triple rate.
+const LeagueSprintSpeed = 27.0
+
+// speedTripleFactor scales the triple share by sprint speed: 30% more per
+// foot per second above league average, bounded to [0.25, 2.5]. Zero speed
+// (unknown) is neutral. Singles absorb the difference.
+func speedTripleFactor(speed float64) float64 {
+	if speed <= 0 {
+		return 1
+	}
+	f := 1 + 0.3*(speed-LeagueSprintSpeed)
+	if f < 0.25 {
+		f = 0.25
+	}
+	if f > 2.5 {
+		f = 2.5
+	}
+	return f
+}
+
+func hitType(avg, slug, speed float64, park ParkFactors, m Model, r *rand.Rand) PlateOutcome {
 	// Defensive defaults
 	if avg <= 0 || slug <= 0 {
 		return HIT_SINGLE
 	}
+	w := hitWeights(avg, slug, speed, park, m)
+	return hitTypes[chooseWeighted(r, w[:])]
+}
+
+// hitWeights is hitType's chance of each of hitTypes for a batter with a
+// positive avg and slug.
+func hitWeights(avg, slug, speed float64, park ParkFactors, m Model) [4]float64 {
 
 	// Average bases per hit
 	t := slug / avg
@@ -312,21 +754,21 @@ func hitType(avg, slug float64, r *rand.Rand) string {
 	rem := t - (1 + p2 + 2*p3)
 	pHR := rem / 3.0
 	// Bound HR into a realistic band
-	if pHR < 0.03 {
-		pHR = 0.03
+	if pHR < m.HomeRunShare[0] {
+		pHR = m.HomeRunShare[0]
 	}
-	if pHR > 0.12 {
-		pHR = 0.12
+	if pHR > m.HomeRunShare[1] {
+		pHR = m.HomeRunShare[1]
 	}
 
 	// Singles are whatever remains
 	pS := 1.0 - (p2 + p3 + pHR)
 	// Enforce a floor on singles share to avoid runaway extra-base explosions
-	if pS < 0.55 {
+	if pS < m.SinglesFloor {
 		// Reduce HR first, then 2B, to restore singles floor
-		deficit := 0.55 - pS
+		deficit := m.SinglesFloor - pS
 		// Reduce HR
-		maxHRReduce := pHR - 0.03
+		maxHRReduce := pHR - m.HomeRunShare[0]
 		if maxHRReduce < 0 {
 			maxHRReduce = 0
 		}
@@ -357,18 +799,53 @@ func hitType(avg, slug float64, r *rand.Rand) string {
 		}
 	}
 
-	// Draw
-	u := r.Float64()
-	if u < pS {
-		return HIT_SINGLE
-	}
-	u -= pS
-	if u < p2 {
-		return HIT_DOUBLE
+	p3 *= speedTripleFactor(speed)
+	p2, p3, pHR = park.apply(p2, p3, pHR)
+	pS = 1.0 - (p2 + p3 + pHR)
+
+	return [4]float64{pS, p2, p3, pHR}
+}
+
+// observedHitType draws a hit's kind from s's observed extra-base shares,
+// adjusted for the park, with singles taking whatever is left.
+func observedHitType(s Stats, park ParkFactors, r *rand.Rand) PlateOutcome {
+	w := observedWeights(s, park)
+	return hitTypes[chooseWeighted(r, w[:])]
+}
+
+// observedWeights is observedHitType's chance of each of hitTypes.
+func observedWeights(s Stats, park ParkFactors) [4]float64 {
+	p2, p3, pHR := park.apply(s.Double, s.Triple, s.HomeRun)
+	return [4]float64{1 - (p2 + p3 + pHR), p2, p3, pHR}
+}
+
+// HitMix returns the shares of a hit by a batter with split s and sprint
+// speed speed that go for a single, double, triple and home run under cfg,
+// as the simulator draws them. It takes s as given, so adjust it first for
+// any game context of interest.
+func HitMix(s Stats, speed float64, cfg GameConfig) [4]float64 {
+	var w [4]float64
+	switch {
+	case s.HasHitMix():
+		w = observedWeights(s, cfg.Park)
+	case s.AVG <= 0 || s.SLUG <= 0:
+		return [4]float64{1, 0, 0, 0}
+	default:
+		w = hitWeights(s.AVG, s.SLUG, speed, cfg.Park, cfg.Model.orDefault())
+	}
+	// Normalize as chooseWeighted does, ignoring non-positive weights.
+	var total float64
+	for i := range w {
+		if w[i] < 0 {
+			w[i] = 0
+		}
+		total += w[i]
 	}
-	u -= p2
-	if u < p3 {
-		return HIT_TRIPLE
+	for i := range w {
+		w[i] /= total
 	}
-	return HIT_HOMERUN
+	return w
 }
+
+// hitTypes are hitType's outcomes in the order of its weights.
+var hitTypes = [...]PlateOutcome{HIT_SINGLE, HIT_DOUBLE, HIT_TRIPLE, HIT_HOMERUN}
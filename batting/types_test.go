@@ -0,0 +1,259 @@
+package baseball
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// runners are the batter and the runners a test field starts with.
+var (
+	batterUp = Player{LastName: "Batter"}
+	onFirst  = Player{LastName: "First"}
+	onSecond = Player{LastName: "Second"}
+	onThird  = Player{LastName: "Third"}
+)
+
+// fieldOf returns a field with the batter up and a runner on each base
+// named in bases, e.g. "13" for first and third.
+func fieldOf(bases string) Field {
+	f := Field{AtBat: &batterUp}
+	if strings.Contains(bases, "1") {
+		f.FirstBase = &onFirst
+	}
+	if strings.Contains(bases, "2") {
+		f.SecondBase = &onSecond
+	}
+	if strings.Contains(bases, "3") {
+		f.ThirdBase = &onThird
+	}
+	return f
+}
+
+// occupants lists who is on first, second and third, "-" for empty.
+func occupants(f Field) string {
+	name := func(p *Player) string {
+		if p == nil {
+			return "-"
+		}
+		return p.LastName
+	}
+	return name(f.FirstBase) + " " + name(f.SecondBase) + " " + name(f.ThirdBase)
+}
+
+func TestWalkForcesOnlyForcedRunners(t *testing.T) {
+	for _, tc := range []struct {
+		bases string
+		runs  int
+		after string
+	}{
+		{"", 0, "Batter - -"},
+		{"1", 0, "Batter First -"},
+		{"2", 0, "Batter Second -"},
+		{"3", 0, "Batter - Third"},
+		{"12", 0, "Batter First Second"},
+		{"13", 0, "Batter First Third"},
+		{"23", 0, "Batter Second Third"},
+		{"123", 1, "Batter First Second"},
+	} {
+		for _, o := range []PlateOutcome{HIT_WALK, HIT_BY_PITCH} {
+			g := Game{Field: fieldOf(tc.bases)}
+			g.Hit(o)
+			if g.Runs != tc.runs || occupants(g.Field) != tc.after {
+				t.Errorf("%s with %q on: %d runs, bases %s; want %d, %s", o, tc.bases, g.Runs, occupants(g.Field), tc.runs, tc.after)
+			}
+			if g.Field.AtBat != nil {
+				t.Errorf("%s with %q on: batter still at bat", o, tc.bases)
+			}
+		}
+	}
+}
+
+func TestAdvanceAllLoaded(t *testing.T) {
+	for _, tc := range []struct {
+		bases int
+		runs  int
+		after string
+	}{
+		{1, 1, "Batter First Second"},
+		{2, 2, "- Batter First"},
+		{3, 3, "- - Batter"},
+		{4, 4, "- - -"},
+	} {
+		f := fieldOf("123")
+		if runs := f.AdvanceAll(tc.bases); runs != tc.runs || occupants(f) != tc.after {
+			t.Errorf("loaded, advance %d: %d runs, bases %s; want %d, %s", tc.bases, runs, occupants(f), tc.runs, tc.after)
+		}
+		if f.AtBat != nil {
+			t.Errorf("loaded, advance %d: batter still at bat", tc.bases)
+		}
+	}
+}
+
+func TestPlateOutcomeText(t *testing.T) {
+	for _, o := range []PlateOutcome{HIT_SINGLE, HIT_DOUBLE, HIT_TRIPLE, HIT_HOMERUN, HIT_WALK, HIT_BY_PITCH, HIT_OUT} {
+		var got PlateOutcome
+		if err := got.UnmarshalText([]byte(o.String())); err != nil || got != o {
+			t.Errorf("%s: round-tripped to %q, %v", o, got, err)
+		}
+	}
+	var o PlateOutcome
+	if err := json.Unmarshal([]byte(`"bunt"`), &o); err == nil || o != "" {
+		t.Errorf("decoded %q with error %v, want it rejected", o, err)
+	}
+	if PlateOutcome("bunt").Valid() {
+		t.Error("an undefined outcome is valid")
+	}
+}
+
+func TestSingleWithFirstAndSecondTaken(t *testing.T) {
+	for _, tc := range []struct {
+		bases string
+		draw  float64 // whether the runner from second scores
+		runs  int
+		after string
+	}{
+		{"12", 0, 1, "Batter First -"},
+		{"12", 0.999, 0, "Batter First Second"},
+		{"123", 0, 2, "Batter First -"},
+		{"123", 0.999, 1, "Batter First Second"},
+	} {
+		g := Game{Field: fieldOf(tc.bases), Rand: NewScripted(tc.draw)}
+		g.Hit(HIT_SINGLE)
+		if g.Runs != tc.runs || occupants(g.Field) != tc.after {
+			t.Errorf("single with %q on, draw %v: %d runs, bases %s; want %d, %s", tc.bases, tc.draw, g.Runs, occupants(g.Field), tc.runs, tc.after)
+		}
+		if n := g.Field.LOB(); n+g.Runs != len(tc.bases)+1 {
+			t.Errorf("single with %q on: %d on base and %d in, lost or doubled a runner", tc.bases, n, g.Runs)
+		}
+	}
+}
+
+func TestNewGame(t *testing.T) {
+	for _, hand := range []string{"left", "right"} {
+		cfg := DefaultGameConfig()
+		cfg.PitcherHand = hand
+		r := rand.New(rand.NewSource(1))
+		g := NewGame(cfg, r)
+		if g.Runs != 0 || g.Outs != 0 || g.Hits != 0 || g.PA != 0 {
+			t.Errorf("%s: new game has %d runs, %d outs, %d hits, %d PA", hand, g.Runs, g.Outs, g.Hits, g.PA)
+		}
+		if occupants(g.Field) != "- - -" || g.Field.AtBat != nil {
+			t.Errorf("%s: new game has bases %s", hand, occupants(g.Field))
+		}
+		if g.PitcherHand != hand || g.Pitcher() != nil || g.Rand != r {
+			t.Errorf("new game facing a %s-hander has hand %q, pitcher %v", hand, g.PitcherHand, g.Pitcher())
+		}
+	}
+}
+
+func TestAggressiveRunnerTakesTheExtraBase(t *testing.T) {
+	scores := func(aggression float64) int {
+		runner := hitter("Runner", 0.330, 0.420)
+		runner.Aggression = aggression
+		r := rand.New(rand.NewSource(1))
+		n := 0
+		for i := 0; i < 2000; i++ {
+			g := Game{Field: fieldOf(""), Rand: r}
+			g.Field.SecondBase = &runner
+			g.Hit(HIT_SINGLE)
+			n += g.Runs
+		}
+		return n
+	}
+	leadoff, cleanup := scores(1.5), scores(0.5)
+	if leadoff <= cleanup {
+		t.Errorf("aggressive runner scored from second %d times in 2000 singles, cautious one %d", leadoff, cleanup)
+	}
+}
+
+func TestEveryHitLeavesValidBases(t *testing.T) {
+	for _, bases := range []string{"", "1", "2", "3", "12", "13", "23", "123"} {
+		for _, o := range []PlateOutcome{HIT_SINGLE, HIT_DOUBLE, HIT_TRIPLE, HIT_HOMERUN, HIT_WALK, HIT_BY_PITCH} {
+			for s := int64(1); s <= 30; s++ {
+				g := Game{Field: fieldOf(bases), Rand: rand.New(rand.NewSource(s)),
+					holdThird: 0.5, holdSecond: 0.5, extraOnThrow: 0.5}
+				g.Hit(o)
+				f := g.Field
+				if f.AtBat != nil {
+					t.Fatalf("%s with %q on: batter still at bat", o, bases)
+				}
+				on := []*Player{f.FirstBase, f.SecondBase, f.ThirdBase}
+				for i := range on {
+					for j := i + 1; j < len(on); j++ {
+						if on[i] != nil && on[i] == on[j] {
+							t.Fatalf("%s with %q on, seed %d: %s on two bases", o, bases, s, on[i].LastName)
+						}
+					}
+				}
+				if f.LOB()+g.Runs != len(bases)+1 {
+					t.Fatalf("%s with %q on, seed %d: %d on base and %d in from %d", o, bases, s, f.LOB(), g.Runs, len(bases)+1)
+				}
+			}
+		}
+	}
+}
+
+func TestFastRunnerScoresFromFirstOnSingle(t *testing.T) {
+	scored := func(speed float64) int {
+		runner := hitter("Runner", 0.330, 0.420)
+		runner.Speed = speed
+		r := rand.New(rand.NewSource(1))
+		n := 0
+		for i := 0; i < 1000; i++ {
+			g := Game{Field: fieldOf(""), Rand: r, firstToHome: 0.3}
+			g.Field.FirstBase = &runner
+			g.Hit(HIT_SINGLE)
+			n += g.Runs
+		}
+		return n
+	}
+	if n := scored(LeagueSprintSpeed + 2); n < 100 || n > 900 {
+		t.Errorf("fast runner scored from first on %d of 1000 singles, want some but not all", n)
+	}
+	if n := scored(LeagueSprintSpeed - 1); n != 0 {
+		t.Errorf("slow runner scored from first on %d singles", n)
+	}
+}
+
+func TestRunnerTakesThirdOnTheThrowHome(t *testing.T) {
+	// Second and first: the runner from second scores (draw 0) and the
+	// runner from first, who stops at second, takes third on the throw.
+	for _, tc := range []struct {
+		draw  float64
+		after string
+	}{
+		{0, "Batter - First"},
+		{0.999, "Batter First -"},
+	} {
+		g := Game{Field: fieldOf("12"), Rand: NewScripted(0, tc.draw), extraOnThrow: 0.9}
+		g.Hit(HIT_SINGLE)
+		if g.Runs != 1 || occupants(g.Field) != tc.after {
+			t.Errorf("throw draw %v: %d runs, bases %s; want 1, %s", tc.draw, g.Runs, occupants(g.Field), tc.after)
+		}
+	}
+}
+
+func TestOutStretchingAdvancesRunnersAndRecordsAnOut(t *testing.T) {
+	for _, tc := range []struct {
+		bases string
+		runs  int
+		after string
+	}{
+		{"", 0, "- - -"},
+		{"2", 1, "- - -"},
+		{"1", 0, "- - First"},
+		{"23", 2, "- - -"},
+	} {
+		// A draw of 0.999 keeps a runner from first at third.
+		g := Game{Field: fieldOf(tc.bases), Rand: NewScripted(0.999), stretching: true}
+		g.Hit(HIT_DOUBLE)
+		if g.Runs != tc.runs || g.Outs != 1 || occupants(g.Field) != tc.after {
+			t.Errorf("out stretching with %q on: %d runs, %d outs, bases %s; want %d, 1, %s", tc.bases, g.Runs, g.Outs, occupants(g.Field), tc.runs, tc.after)
+		}
+		if g.Hits != 1 || g.Field.AtBat != nil {
+			t.Errorf("out stretching with %q on: %d hits, batter at bat %v", tc.bases, g.Hits, g.Field.AtBat)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package baseball
+
+import "math/rand"
+
+// wildPitch gives the defense a chance to throw a wild pitch (or let a
+// passed ball by) before the next plate appearance when anyone is on base.
+// Runners move up one base, lead first; the runner on third scores only
+// with probability cfg.ScoreFromThirdOnWildPitch, holding otherwise, and a
+// runner who finds the next base still occupied holds too.
+func (g *Game) wildPitch(cfg GameConfig, r *rand.Rand) {
+	f := &g.Field
+	if cfg.WildPitchRate <= 0 || (f.FirstBase == nil && f.SecondBase == nil && f.ThirdBase == nil) {
+		return
+	}
+	if r.Float64() >= cfg.WildPitchRate {
+		return
+	}
+	g.WP++
+	if f.ThirdBase != nil {
+		if p := cfg.ScoreFromThirdOnWildPitch; p >= 1 || r.Float64() < p {
+			g.score(f.clear(3), nil)
+		}
+	}
+	if f.SecondBase != nil && f.ThirdBase == nil {
+		f.moveRunner(2, 3)
+	}
+	if f.FirstBase != nil && f.SecondBase == nil {
+		f.moveRunner(1, 2)
+	}
+}
@@ -0,0 +1,31 @@
+package baseball
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestWildPitchRunnerOnThirdHolds(t *testing.T) {
+	cfg := DefaultGameConfig()
+	cfg.WildPitchRate = 1
+	cfg.ScoreFromThirdOnWildPitch = 0.1
+	r := rand.New(rand.NewSource(1))
+	held := 0
+	for i := 0; i < 1000; i++ {
+		g := Game{Field: fieldOf("13")}
+		g.wildPitch(cfg, r)
+		switch occupants(g.Field) {
+		case "- First Third":
+			held++
+		case "- First -":
+			if g.Runs != 1 {
+				t.Fatalf("runner left third but %d runs scored", g.Runs)
+			}
+		default:
+			t.Fatalf("wild pitch with first and third on left bases %s", occupants(g.Field))
+		}
+	}
+	if held < 850 || held > 950 {
+		t.Errorf("runner on third held %d of 1000 wild pitches, want about 900", held)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// benchValue is the best a bench player does when swapped into the optimized
+// lineup, in mean runs per game.
+type benchValue struct {
+	Name     string   `json:"name"`
+	Replaces string   `json:"replaces"`
+	Mean     float64  `json:"mean"`
+	Gain     float64  `json:"gain"`
+	Order    []string `json:"order"`
+	// Wins is Gain as wins over a season, with -runs-per-win.
+	Wins float64 `json:"wins,omitempty"`
+}
+
+// benchValues tries every roster player not in best in each of its slots,
+// locally re-optimizes the order after each swap, and returns the baseline
+// mean of best plus each candidate's best result ranked by gain. Every
+// lineup is scored over the same seeded games so the comparisons share
+// their noise.
+func benchValues(best, roster []baseball.Player, cfg baseball.GameConfig, games int, seed int64) (float64, []benchValue) {
+	eval := func(lineup []baseball.Player) float64 {
+		r := rand.New(rand.NewSource(seed))
+		var sum int
+		for g := 0; g < games; g++ {
+			sum += baseball.SimulateGame(lineup, cfg, r).Runs
+		}
+		return float64(sum) / float64(games)
+	}
+	_, base := localOptimize(best, eval)
+
+	starting := make(map[string]bool, len(best))
+	for _, p := range best {
+		starting[p.LastName+","+p.FirstName] = true
+	}
+	var mu sync.Mutex
+	var vals []benchValue
+	var wg sync.WaitGroup
+	for _, b := range roster {
+		if starting[b.LastName+","+b.FirstName] {
+			continue
+		}
+		wg.Add(1)
+		go func(b baseball.Player) {
+			defer wg.Done()
+			v := benchValue{Name: b.FirstName + " " + b.LastName}
+			for slot := range best {
+				lineup := append([]baseball.Player(nil), best...)
+				lineup[slot] = b
+				lineup, mean := localOptimize(lineup, eval)
+				if v.Order == nil || mean > v.Mean {
+					v.Replaces = best[slot].FirstName + " " + best[slot].LastName
+					v.Mean = mean
+					v.Order = v.Order[:0]
+					for _, p := range lineup {
+						v.Order = append(v.Order, p.LastName)
+					}
+				}
+			}
+			v.Gain = v.Mean - base
+			v.Wins = seasonWins(v.Gain)
+			mu.Lock()
+			vals = append(vals, v)
+			mu.Unlock()
+		}(b)
+	}
+	wg.Wait()
+	sort.Slice(vals, func(i, j int) bool { return vals[i].Gain > vals[j].Gain })
+	return base, vals
+}
+
+// localOptimize hill-climbs lineup by swapping pairs of slots, keeping any
+// swap that raises eval, until no swap helps. It returns the improved order
+// and its score.
+func localOptimize(lineup []baseball.Player, eval func([]baseball.Player) float64) ([]baseball.Player, float64) {
+	lineup = append([]baseball.Player(nil), lineup...)
+	score := eval(lineup)
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < len(lineup); i++ {
+			for j := i + 1; j < len(lineup); j++ {
+				lineup[i], lineup[j] = lineup[j], lineup[i]
+				if s := eval(lineup); s > score {
+					score = s
+					improved = true
+					continue
+				}
+				lineup[i], lineup[j] = lineup[j], lineup[i]
+			}
+		}
+	}
+	return lineup, score
+}
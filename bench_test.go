@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestBenchValuesRanksTheBetterBat(t *testing.T) {
+	best := make([]baseball.Player, 6)
+	for i := range best {
+		best[i] = testPlayer(string(rune('A'+i)), 0.310, 0.400)
+	}
+	star := testPlayer("Star", 0.420, 0.650)
+	scrub := testPlayer("Scrub", 0.220, 0.250)
+	roster := append(append([]baseball.Player(nil), best...), scrub, star)
+
+	_, vals := benchValues(best, roster, baseball.DefaultGameConfig(), 300, 1)
+	if len(vals) != 2 {
+		t.Fatalf("valued %d bench players, want 2", len(vals))
+	}
+	if vals[0].Name != "Test Star" || vals[0].Gain <= 0 {
+		t.Errorf("first is %s with gain %.3f, want Test Star above zero", vals[0].Name, vals[0].Gain)
+	}
+	if vals[1].Gain >= vals[0].Gain {
+		t.Errorf("%s gains %.3f, no less than %s's %.3f", vals[1].Name, vals[1].Gain, vals[0].Name, vals[0].Gain)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// benchmarkLineup plays lineup games times on one goroutine and reports the
+// run distribution and the engine's throughput, so profiles of it show the
+// game engine without the search around it.
+func benchmarkLineup(w io.Writer, lineup []baseball.Player, cfg baseball.GameConfig, games int, r *rand.Rand) runTally {
+	var tally runTally
+	start := time.Now()
+	for g := 0; g < games; g++ {
+		tally.Add(baseball.SimulateGame(lineup, cfg, r).Runs)
+	}
+	elapsed := time.Since(start)
+	order := make([]string, len(lineup))
+	for i, p := range lineup {
+		order[i] = p.LastName
+	}
+	fmt.Fprintf(w, "Lineup ID=%s order=%v\n", lineupResult{Hash: lineupHash(lineup)}.ID(), order)
+	fmt.Fprintf(w, "%d games: mean=%.4f ±%.4f stddev=%.4f\n", tally.N, tally.Mean(), tally.HalfWidth95(), tally.StdDev())
+	fmt.Fprintf(w, "%v elapsed, %.0f games/sec, %v/game\n",
+		elapsed.Round(time.Millisecond), float64(games)/elapsed.Seconds(), elapsed/time.Duration(games))
+	return tally
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestBenchmarkLineup(t *testing.T) {
+	var buf bytes.Buffer
+	lineup := nineOf(testPlayer("Avg", 0.320, 0.410))
+	tally := benchmarkLineup(&buf, lineup, baseball.DefaultGameConfig(), 2000, rand.New(rand.NewSource(1)))
+	if tally.N != 2000 {
+		t.Errorf("played %d games, want 2000", tally.N)
+	}
+	// A league-average order scores somewhere around four or five a game.
+	if m := tally.Mean(); m < 2.5 || m > 7 {
+		t.Errorf("mean %.3f runs a game", m)
+	}
+	if sd := tally.StdDev(); sd < 1 || sd > 5 {
+		t.Errorf("stddev %.3f runs a game", sd)
+	}
+	out := buf.String()
+	for _, want := range []string{"Lineup ID=" + lineupResult{Hash: lineupHash(lineup)}.ID(), "2000 games: mean=", "games/sec"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report is missing %q:\n%s", want, out)
+		}
+	}
+}
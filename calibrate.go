@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// calibration is the simulated scoring level of a league-average lineup and
+// the stat scale that moves it to a target.
+type calibration struct {
+	Games     int     `json:"games"`
+	RPG       float64 `json:"rpg"`
+	Target    float64 `json:"target"`
+	StatScale float64 `json:"stat_scale"`
+	ScaledRPG float64 `json:"scaled_rpg"`
+}
+
+// leagueLineup is nine batters hitting the league-average line from both
+// sides.
+func leagueLineup() []baseball.Player {
+	avg := baseball.Stats{AVG: baseball.LeagueAVG, OBP: baseball.LeagueOBP, SLUG: baseball.LeagueSLUG}
+	lineup := make([]baseball.Player, 9)
+	for i := range lineup {
+		lineup[i] = baseball.Player{FirstName: "League", LastName: fmt.Sprintf("Average%d", i+1), LHP: avg, RHP: avg}
+	}
+	return lineup
+}
+
+// calibrate plays a league-average lineup under cfg, then bisects the stat
+// scale on top of cfg.Model.StatScale until it scores target runs per game.
+// Every trial replays the same seeded games, so the search isn't chasing
+// noise.
+func calibrate(cfg baseball.GameConfig, games int, target float64, seed int64) calibration {
+	lineup := leagueLineup()
+	base := cfg.Model.StatScale
+	if base == 0 {
+		base = 1
+	}
+	rpg := func(scale float64) float64 {
+		c := cfg
+		if c.Model == (baseball.Model{}) {
+			c.Model = baseball.DefaultModel
+		}
+		c.Model.StatScale = scale
+		return EvaluateLineup(lineup, c, games, seed).Mean
+	}
+	cal := calibration{Games: games, RPG: rpg(base), Target: target}
+	lo, hi := base/2, base*2
+	for i := 0; i < 30 && hi-lo > 1e-4; i++ {
+		mid := (lo + hi) / 2
+		if rpg(mid) < target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	cal.StatScale = (lo + hi) / 2
+	cal.ScaledRPG = rpg(cal.StatScale)
+	return cal
+}
+
+func writeCalibration(w io.Writer, c calibration) {
+	fmt.Fprintf(w, "League-average lineup: %.3f R/G over %d games (target %.2f)\n", c.RPG, c.Games, c.Target)
+	fmt.Fprintf(w, "Suggested -stat-scale %.4f scores %.3f R/G\n", c.StatScale, c.ScaledRPG)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestCalibrateHitsTarget(t *testing.T) {
+	const target, tolerance = 4.5, 0.1
+	c := calibrate(baseball.DefaultGameConfig(), 500, target, 1)
+	// League-average hitters should already score like a real league.
+	if c.RPG < 3.5 || c.RPG > 5.5 {
+		t.Errorf("league-average lineup scores %.3f R/G", c.RPG)
+	}
+	if math.Abs(c.ScaledRPG-target) > tolerance {
+		t.Errorf("stat scale %.4f scores %.3f R/G, want %.2f±%.2f", c.StatScale, c.ScaledRPG, target, tolerance)
+	}
+	if (c.RPG < target) != (c.StatScale > 1) {
+		t.Errorf("scale %.4f moves %.3f R/G the wrong way toward %.2f", c.StatScale, c.RPG, target)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// runTally accumulates per-game run totals for a mean and sample variance
+// online, without keeping the games. The mean is the exact integer sum over
+// N, so equal totals tie exactly in the rankings; the variance uses
+// Welford's update, which doesn't lose precision to a big sum of squares
+// the way the textbook formula does over millions of games.
+type runTally struct {
+	N   int64
+	Sum float64
+	// M2 is the sum of squared deviations from the running mean.
+	M2 float64
+}
+
+func (t *runTally) Add(runs int) {
+	x := float64(runs)
+	prev := t.Mean()
+	t.N++
+	t.Sum += x
+	t.M2 += (x - prev) * (x - t.Mean())
+}
+
+func (t runTally) Mean() float64 {
+	if t.N == 0 {
+		return 0
+	}
+	return t.Sum / float64(t.N)
+}
+
+// StdDev is the sample standard deviation of the recorded games.
+func (t runTally) StdDev() float64 {
+	if t.N < 2 {
+		return 0
+	}
+	return math.Sqrt(t.M2 / float64(t.N-1))
+}
+
+// HalfWidth95 is the half-width of the normal-approximation 95% confidence
+// interval for the mean.
+func (t runTally) HalfWidth95() float64 {
+	if t.N < 2 {
+		return math.Inf(1)
+	}
+	return 1.96 * t.StdDev() / math.Sqrt(float64(t.N))
+}
+
+// refineToCI keeps simulating each finalist in batches of step games until the
+// 95% confidence half-width of its mean is at most target or it has played
+// maxGames, so compute goes to the close calls instead of clear winners.
+// Every stat is recomputed over all of a finalist's games, first and
+// refining, and the finalists are returned re-sorted by them.
+func refineToCI(results []lineupResult, cfg baseball.GameConfig, target float64, step, maxGames, workers int) []lineupResult {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(workerID int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(baseSeed() + int64(workerID)*7919))
+			for i := range jobs {
+				res := &results[i]
+				for res.tally.N < int64(maxGames) && res.tally.HalfWidth95() > target {
+					for g := 0; g < step; g++ {
+						game := baseball.SimulateGame(res.lineup, cfg, r)
+						res.tally.Add(game.Runs)
+						if keepHistogram() {
+							res.hist.add(game.Runs)
+						}
+						res.runs += int64(game.Runs)
+						res.pa += int64(game.PA)
+					}
+				}
+				res.Mean = res.tally.Mean()
+				res.Score = res.Mean - clusterPenalty(res.lineup, cfg)
+				res.summarize()
+			}
+		}(w)
+	}
+	for i := range results {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return ranksAbove(results[i], results[j]) })
+	return results
+}
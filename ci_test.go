@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// nineOf returns a lineup of nine copies of p, each with its own name.
+func nineOf(p baseball.Player) []baseball.Player {
+	lineup := make([]baseball.Player, 9)
+	for i := range lineup {
+		lineup[i] = p
+		lineup[i].LastName = p.LastName + string(rune('1'+i))
+	}
+	return lineup
+}
+
+func TestRefineToCIPlaysNoisyLineupsLonger(t *testing.T) {
+	withInt64(t, seed, 1)
+	steady := nineOf(testPlayer("Weak", 0.120, 0.100))
+	noisy := nineOf(testPlayer("Slugger", 0.380, 0.600))
+	results := []lineupResult{
+		{Hash: lineupHash(steady), lineup: steady},
+		{Hash: lineupHash(noisy), lineup: noisy},
+	}
+	results = refineToCI(results, baseball.DefaultGameConfig(), 0.3, 50, 5000, 2)
+	byHash := map[uint64]lineupResult{}
+	for _, r := range results {
+		byHash[r.Hash] = r
+	}
+	s, n := byHash[lineupHash(steady)], byHash[lineupHash(noisy)]
+	if n.Games <= s.Games {
+		t.Errorf("noisy lineup (stddev %.2f) played %d games, steady one (stddev %.2f) %d", n.StdDev, n.Games, s.StdDev, s.Games)
+	}
+	for _, r := range []lineupResult{s, n} {
+		if int64(r.Games) != r.tally.N || r.RunsPerPA != float64(r.runs)/float64(r.pa) {
+			t.Errorf("lineup %s: games %d, runs per PA %v not recomputed from %d games", r.ID(), r.Games, r.RunsPerPA, r.tally.N)
+		}
+		if hw := r.tally.HalfWidth95(); hw > 0.3 {
+			t.Errorf("lineup %s stopped with half-width %.3f", r.ID(), hw)
+		}
+	}
+	if results[0].Hash != n.Hash {
+		t.Error("refined results aren't re-sorted best first")
+	}
+}
+
+func TestRunTallyMatchesBatch(t *testing.T) {
+	games := []int{0, 3, 7, 2, 4, 4, 11, 1, 5, 0, 6, 3}
+	var tally runTally
+	sum := 0
+	for _, runs := range games {
+		tally.Add(runs)
+		sum += runs
+	}
+	mean := float64(sum) / float64(len(games))
+	ss := 0.0
+	for _, runs := range games {
+		d := float64(runs) - mean
+		ss += d * d
+	}
+	stddev := math.Sqrt(ss / float64(len(games)-1))
+
+	if tally.N != int64(len(games)) || tally.Mean() != mean {
+		t.Errorf("online mean %v over %d games, batch %v over %d", tally.Mean(), tally.N, mean, len(games))
+	}
+	if math.Abs(tally.StdDev()-stddev) > 1e-12 {
+		t.Errorf("online stddev %v, batch %v", tally.StdDev(), stddev)
+	}
+}
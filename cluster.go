@@ -0,0 +1,34 @@
+package main
+
+import baseball "github.com/genghisjahn/battinglineup/batting"
+
+// clusterPenalty is the -cluster-penalty runs taken off a lineup's score
+// for each pair of adjacent hitters, the ninth and the leadoff included,
+// whose OBP is below -cluster-obp. Stacked outs rarely show up clearly at
+// low game counts, so this lets a user state the prior outright. It's zero
+// when the penalty is off or in -opponent mode, where the score is a win
+// probability.
+func clusterPenalty(lineup []baseball.Player, cfg baseball.GameConfig) float64 {
+	if *clusterRuns <= 0 || *opponentPath != "" {
+		return 0
+	}
+	low := func(p baseball.Player) bool {
+		var obp float64
+		switch {
+		case *platoon:
+			obp = *lhpShare*p.LHP.OBP + (1-*lhpShare)*p.RHP.OBP
+		case cfg.PitcherHand != "":
+			obp = p.Split(cfg.PitcherHand).OBP
+		default:
+			obp = (p.LHP.OBP + p.RHP.OBP) / 2
+		}
+		return obp < *clusterOBP
+	}
+	pairs := 0
+	for i := range lineup {
+		if low(lineup[i]) && low(lineup[(i+1)%len(lineup)]) {
+			pairs++
+		}
+	}
+	return *clusterRuns * float64(pairs)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestClusterPenaltyDemotesBackToBackOuts(t *testing.T) {
+	withFloat(t, clusterRuns, 1)
+	hi1, hi2 := testPlayer("High1", 0.380, 0.500), testPlayer("High2", 0.370, 0.480)
+	lo1, lo2 := testPlayer("Low1", 0.280, 0.350), testPlayer("Low2", 0.270, 0.340)
+	cfg := baseball.DefaultGameConfig()
+
+	if p := clusterPenalty([]baseball.Player{hi1, lo1, lo2, hi2}, cfg); p != 1 {
+		t.Errorf("back-to-back low OBPs cost %v, want 1", p)
+	}
+	if p := clusterPenalty([]baseball.Player{lo1, hi1, lo2, hi2}, cfg); p != 0 {
+		t.Errorf("split-up low OBPs cost %v, want 0", p)
+	}
+	// The ninth and the leadoff are back to back too.
+	if p := clusterPenalty([]baseball.Player{lo1, hi1, hi2, lo2}, cfg); p != 1 {
+		t.Errorf("low OBPs wrapping around the order cost %v, want 1", p)
+	}
+
+	hi3, hi4 := testPlayer("High3", 0.360, 0.460), testPlayer("High4", 0.350, 0.440)
+	s := runSearch(t, []baseball.Player{hi1, hi2, hi3, hi4, lo1, lo2}, 6, cfg, 200)
+	for i, res := range s.topResults() {
+		if res.Mean-res.Score != clusterPenalty(res.lineup, cfg) {
+			t.Errorf("#%d %v: score %v isn't mean %v less its penalty", i+1, res.Order, res.Score, res.Mean)
+		}
+	}
+	if best := s.topResults()[0]; clusterPenalty(best.lineup, cfg) != 0 {
+		t.Errorf("best order %v bats its low OBPs back to back", best.Order)
+	}
+}
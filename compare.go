@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// orderComparison is one -compare-orders lineup, ranked against the others.
+type orderComparison struct {
+	Source string   `json:"source"`
+	ID     string   `json:"id"`
+	Order  []string `json:"order"`
+	Mean   float64  `json:"mean"`
+	CI95   float64  `json:"ci95"` // half-width of the mean's 95% interval
+	// BehindBest is the best order's mean minus this one's, and Z the
+	// paired z-score of that gap over the shared games; Significant is set
+	// when |Z| >= 1.96. All are zero for the best order itself.
+	BehindBest  float64 `json:"behind_best"`
+	Z           float64 `json:"z"`
+	Significant bool    `json:"significant"`
+	// Games is how many games the lineup played: fewer than asked when
+	// TimedOut, and the comparison then uses only the games both played.
+	Games    int  `json:"games"`
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+// compareOrders plays every lineup over the same seeded games, so the gaps
+// between them reflect the orders rather than luck, and returns them best
+// first with each one's paired test against the best. A positive limit
+// caps each lineup's time; see playTimed. A lineup that timed out can't be
+// the best unless they all did.
+func compareOrders(sources []string, lineups [][]baseball.Player, cfg baseball.GameConfig, games int, seed int64, limit time.Duration) []orderComparison {
+	runs := make([][]int, len(lineups))
+	out := make([]orderComparison, len(lineups))
+	for i, lineup := range lineups {
+		var tally runTally
+		var timedOut bool
+		runs[i], _, timedOut = playTimed(lineup, cfg, games, rand.New(rand.NewSource(seed)), limit)
+		for _, n := range runs[i] {
+			tally.Add(n)
+		}
+		out[i] = orderComparison{Source: sources[i], ID: fmt.Sprintf("%x", lineupHash(lineup))[:6], Mean: tally.Mean(), CI95: tally.HalfWidth95(), Games: len(runs[i]), TimedOut: timedOut}
+		for _, p := range lineup {
+			out[i].Order = append(out[i].Order, p.LastName)
+		}
+	}
+	best := 0
+	for i := range out {
+		if out[best].TimedOut && !out[i].TimedOut || out[i].TimedOut == out[best].TimedOut && out[i].Mean > out[best].Mean {
+			best = i
+		}
+	}
+	for i := range out {
+		if i == best {
+			continue
+		}
+		var diff runTally
+		for g := range runs[i] {
+			if g >= len(runs[best]) {
+				break
+			}
+			diff.Add(runs[best][g] - runs[i][g])
+		}
+		out[i].BehindBest = diff.Mean()
+		if se := diff.StdDev() / math.Sqrt(float64(diff.N)); se > 0 {
+			out[i].Z = diff.Mean() / se
+		}
+		out[i].Significant = math.Abs(out[i].Z) >= 1.96
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].TimedOut != out[j].TimedOut {
+			return !out[i].TimedOut
+		}
+		return out[i].Mean > out[j].Mean
+	})
+	return out
+}
+
+// readCandidates loads a -candidates file, a JSON array of orders each
+// given as an array of last names from players, and returns a source label
+// and lineup for each. Every order needs size distinct players.
+func readCandidates(path string, players []baseball.Player, size int) ([]string, [][]baseball.Player, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var orders [][]string
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(orders) == 0 {
+		return nil, nil, fmt.Errorf("%s has no orders", path)
+	}
+	var sources []string
+	var lineups [][]baseball.Player
+	for i, order := range orders {
+		if len(order) != size {
+			return nil, nil, fmt.Errorf("order %d has %d players, want %d", i+1, len(order), size)
+		}
+		lineup := make([]baseball.Player, len(order))
+		seen := make(map[string]bool, len(order))
+		for k, name := range order {
+			p, err := findPlayer(players, name)
+			if err != nil {
+				return nil, nil, fmt.Errorf("order %d: %v", i+1, err)
+			}
+			if seen[p.LastName] {
+				return nil, nil, fmt.Errorf("order %d lists %s twice", i+1, p.LastName)
+			}
+			seen[p.LastName] = true
+			lineup[k] = p
+		}
+		sources = append(sources, fmt.Sprintf("candidate %d", i+1))
+		lineups = append(lineups, lineup)
+	}
+	return sources, lineups, nil
+}
+
+// writeComparison renders a -compare-orders result as "text" or "json".
+func writeComparison(w io.Writer, format string, games int, cmp []orderComparison) error {
+	switch format {
+	case "text":
+		fmt.Fprintf(w, "Orders compared over the same %d games:\n", games)
+		for i, c := range cmp {
+			verdict := "best"
+			if c.TimedOut {
+				verdict = fmt.Sprintf("TIMED OUT after %d games", c.Games)
+			} else if i > 0 {
+				verdict = fmt.Sprintf("%.3f behind, z=%.2f, ", c.BehindBest, c.Z)
+				if c.Significant {
+					verdict += "significant"
+				} else {
+					verdict += "not significant"
+				}
+			}
+			fmt.Fprintf(w, "%2d) ID=%s mean=%.3f ±%.3f  (%s)  %s  order=%v\n", i+1, c.ID, c.Mean, c.CI95, verdict, c.Source, c.Order)
+		}
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cmp)
+	}
+	return fmt.Errorf("format %q isn't supported with -compare-orders", format)
+}
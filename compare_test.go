@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestCompareOrdersRanksWithSignificance(t *testing.T) {
+	roster := testRoster(14)
+	strong := roster[:9]
+	reversed := make([]baseball.Player, 9)
+	for i := range reversed {
+		reversed[i] = strong[8-i]
+	}
+	weak := roster[5:]
+	sources := []string{"weak", "reversed", "strong"}
+	cmp := compareOrders(sources, [][]baseball.Player{weak, reversed, strong}, baseball.DefaultGameConfig(), 2000, 1, 0)
+
+	if len(cmp) != 3 || cmp[0].Source != "strong" || cmp[2].Source != "weak" {
+		t.Fatalf("ranked %+v", cmp)
+	}
+	if cmp[0].Z != 0 || cmp[0].BehindBest != 0 || cmp[0].Significant {
+		t.Errorf("best order carries a test against itself: %+v", cmp[0])
+	}
+	if !cmp[2].Significant || cmp[2].BehindBest <= cmp[1].BehindBest {
+		t.Errorf("weaker players %.3f behind (z=%.2f), reversed order %.3f", cmp[2].BehindBest, cmp[2].Z, cmp[1].BehindBest)
+	}
+
+	var buf bytes.Buffer
+	if err := writeComparison(&buf, "text", 2000, cmp); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[1], "(best)  strong") || !strings.Contains(lines[3], ", significant)  weak") {
+		t.Errorf("printed:\n%s", buf.String())
+	}
+}
+
+func TestCompareOrdersCutsOffSlowLineup(t *testing.T) {
+	cfg := baseball.DefaultGameConfig()
+	// Every plate appearance by a Slow hitter takes a millisecond.
+	cfg.Trace = func(p baseball.Play) {
+		if strings.HasPrefix(p.Batter.LastName, "Slow") {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	fast, slow := testRoster(9), nineOf(testPlayer("Slow", 0.400, 0.600))
+	done := make(chan []orderComparison)
+	go func() {
+		done <- compareOrders([]string{"slow", "fast"}, [][]baseball.Player{slow, fast}, cfg, 200, 1, 50*time.Millisecond)
+	}()
+	var cmp []orderComparison
+	select {
+	case cmp = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("comparison didn't return")
+	}
+	if cmp[0].Source != "fast" || cmp[0].TimedOut || cmp[0].Games != 200 {
+		t.Errorf("first %+v, want the fast order with all 200 games", cmp[0])
+	}
+	if cmp[1].Source != "slow" || !cmp[1].TimedOut || cmp[1].Games >= 200 {
+		t.Errorf("second %+v, want the slow order timed out", cmp[1])
+	}
+
+	var buf bytes.Buffer
+	if err := writeComparison(&buf, "text", 200, cmp); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "TIMED OUT after") {
+		t.Errorf("printed:\n%s", buf.String())
+	}
+}
+
+func TestCandidatesRankEveryOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "candidates.json")
+	body := `[["P1", "P2", "P3", "P4", "P5", "P6"], ["p6", "p5", "p4", "p3", "p2", "p1"], ["P7", "P1", "P2", "P3", "P4", "P5"]]`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	players := testRoster(7)
+	sources, lineups, err := readCandidates(path, players, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmp := compareOrders(sources, lineups, baseball.DefaultGameConfig(), 200, 1, 0)
+	if len(cmp) != 3 {
+		t.Fatalf("%d results for three candidates: %+v", len(cmp), cmp)
+	}
+	seen := map[string]bool{}
+	for i, c := range cmp {
+		seen[c.Source] = true
+		if i > 0 && c.Mean > cmp[i-1].Mean {
+			t.Errorf("%s (%.3f) ranked below %s (%.3f)", c.Source, c.Mean, cmp[i-1].Source, cmp[i-1].Mean)
+		}
+	}
+	if len(seen) != 3 {
+		t.Errorf("sources %v", seen)
+	}
+
+	for _, bad := range []string{`[["P1", "P2", "P3", "P4", "P5"]]`, `[["P1", "P1", "P2", "P3", "P4", "P5"]]`, `[["P1", "P2", "P3", "P4", "P5", "Nobody"]]`, `[]`} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := readCandidates(path, players, 6); err == nil {
+			t.Errorf("accepted %s", bad)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "time"
+
+// processCPU isn't available on this platform.
+func processCPU() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// rankChange is one lineup's position in two -format json reports. A zero
+// rank means the lineup wasn't in that report's top list.
+type rankChange struct {
+	ID       string   `json:"id"`
+	Order    []string `json:"order"`
+	OldRank  int      `json:"old_rank,omitempty"`
+	NewRank  int      `json:"new_rank,omitempty"`
+	OldScore float64  `json:"old_score,omitempty"`
+	NewScore float64  `json:"new_score,omitempty"`
+}
+
+// reportDiff lists the lineups that entered or left the top list between two
+// runs, and those that stayed but changed rank.
+type reportDiff struct {
+	Entered []rankChange `json:"entered"`
+	Left    []rankChange `json:"left"`
+	Moved   []rankChange `json:"moved"`
+}
+
+// loadReport reads a report written with -format json.
+func loadReport(path string) (report, error) {
+	var rep report
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rep, err
+	}
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return rep, fmt.Errorf("%s: %w", path, err)
+	}
+	return rep, nil
+}
+
+// diffReports compares the top lists of two reports, matching lineups by
+// hash. Entered and Moved are ordered by new rank, Left by old rank.
+func diffReports(old, cur report) reportDiff {
+	oldRank := make(map[uint64]int, len(old.Top))
+	for i, r := range old.Top {
+		oldRank[r.Hash] = i + 1
+	}
+	var d reportDiff
+	seen := make(map[uint64]bool, len(cur.Top))
+	for i, r := range cur.Top {
+		seen[r.Hash] = true
+		c := rankChange{ID: r.ID(), Order: r.Order, NewRank: i + 1, NewScore: r.Score}
+		was, ok := oldRank[r.Hash]
+		if !ok {
+			d.Entered = append(d.Entered, c)
+			continue
+		}
+		if was != c.NewRank {
+			c.OldRank, c.OldScore = was, old.Top[was-1].Score
+			d.Moved = append(d.Moved, c)
+		}
+	}
+	for i, r := range old.Top {
+		if !seen[r.Hash] {
+			d.Left = append(d.Left, rankChange{ID: r.ID(), Order: r.Order, OldRank: i + 1, OldScore: r.Score})
+		}
+	}
+	sort.SliceStable(d.Moved, func(i, j int) bool { return d.Moved[i].NewRank < d.Moved[j].NewRank })
+	return d
+}
+
+// writeDiff renders d to w as "text" or "json".
+func writeDiff(w io.Writer, format string, d reportDiff) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	fmt.Fprintf(w, "Entered the top list (%d):\n", len(d.Entered))
+	for _, c := range d.Entered {
+		fmt.Fprintf(w, "  #%-3d ID=%s score=%.3f  order=%v\n", c.NewRank, c.ID, c.NewScore, c.Order)
+	}
+	fmt.Fprintf(w, "Left the top list (%d):\n", len(d.Left))
+	for _, c := range d.Left {
+		fmt.Fprintf(w, "  #%-3d ID=%s score=%.3f  order=%v\n", c.OldRank, c.ID, c.OldScore, c.Order)
+	}
+	fmt.Fprintf(w, "Changed rank (%d):\n", len(d.Moved))
+	for _, c := range d.Moved {
+		fmt.Fprintf(w, "  #%d -> #%d (%+d) ID=%s score=%.3f -> %.3f  order=%v\n",
+			c.OldRank, c.NewRank, c.OldRank-c.NewRank, c.ID, c.OldScore, c.NewScore, c.Order)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffReports(t *testing.T) {
+	dir := t.TempDir()
+	fixture := func(name, body string) report {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		rep, err := loadReport(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rep
+	}
+	// Hashes 0xaaaaaa.. 0xdddddd; A drops out, D comes in and B and C swap.
+	old := fixture("old.json", `{"top": [
+		{"hash": 11184810, "score": 5.1, "order": ["A"]},
+		{"hash": 12303291, "score": 5.0, "order": ["B"]},
+		{"hash": 13421772, "score": 4.9, "order": ["C"]}
+	], "bottom": []}`)
+	cur := fixture("new.json", `{"top": [
+		{"hash": 14540253, "score": 5.3, "order": ["D"]},
+		{"hash": 13421772, "score": 5.2, "order": ["C"]},
+		{"hash": 12303291, "score": 5.0, "order": ["B"]}
+	], "bottom": []}`)
+
+	d := diffReports(old, cur)
+	want := reportDiff{
+		Entered: []rankChange{{ID: "dddddd", Order: []string{"D"}, NewRank: 1, NewScore: 5.3}},
+		Left:    []rankChange{{ID: "aaaaaa", Order: []string{"A"}, OldRank: 1, OldScore: 5.1}},
+		Moved: []rankChange{
+			{ID: "cccccc", Order: []string{"C"}, OldRank: 3, NewRank: 2, OldScore: 4.9, NewScore: 5.2},
+			{ID: "bbbbbb", Order: []string{"B"}, OldRank: 2, NewRank: 3, OldScore: 5.0, NewScore: 5.0},
+		},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("diff = %+v\nwant %+v", d, want)
+	}
+}
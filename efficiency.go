@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// efficiency is how well a search used its workers: the CPU time the
+// process spent against the wall-clock time it took.
+type efficiency struct {
+	Wall    time.Duration
+	CPU     time.Duration
+	Workers int
+	Lineups uint64
+}
+
+// Speedup is CPU time over wall time: how many cores were busy on average.
+func (e efficiency) Speedup() float64 {
+	if e.Wall <= 0 {
+		return 0
+	}
+	return e.CPU.Seconds() / e.Wall.Seconds()
+}
+
+// Efficiency is Speedup as a share of the workers; 1 is linear scaling.
+func (e efficiency) Efficiency() float64 {
+	if e.Workers <= 0 {
+		return 0
+	}
+	return e.Speedup() / float64(e.Workers)
+}
+
+// PerWorker is lineups per second of wall time for each worker.
+func (e efficiency) PerWorker() float64 {
+	if e.Wall <= 0 || e.Workers <= 0 {
+		return 0
+	}
+	return float64(e.Lineups) / e.Wall.Seconds() / float64(e.Workers)
+}
+
+func writeEfficiency(w io.Writer, e efficiency) {
+	fmt.Fprintf(w, "Search took %v wall, %v CPU on %d workers: %.2fx speedup, %.0f%% efficiency, %.0f lineups/sec per worker\n",
+		e.Wall.Round(time.Millisecond), e.CPU.Round(time.Millisecond), e.Workers, e.Speedup(), 100*e.Efficiency(), e.PerWorker())
+}
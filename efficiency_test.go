@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestEfficiency(t *testing.T) {
+	e := efficiency{Wall: 2 * time.Second, CPU: 6 * time.Second, Workers: 4, Lineups: 8000}
+	if e.Speedup() != 3 || e.Efficiency() != 0.75 || e.PerWorker() != 1000 {
+		t.Errorf("speedup %v, efficiency %v, per worker %v; want 3, 0.75, 1000", e.Speedup(), e.Efficiency(), e.PerWorker())
+	}
+	var buf bytes.Buffer
+	writeEfficiency(&buf, e)
+	if want := "Search took 2s wall, 6s CPU on 4 workers: 3.00x speedup, 75% efficiency, 1000 lineups/sec per worker\n"; buf.String() != want {
+		t.Errorf("printed %q, want %q", buf.String(), want)
+	}
+	if (efficiency{}).Speedup() != 0 || (efficiency{Wall: time.Second}).Efficiency() != 0 {
+		t.Error("zero wall time or workers didn't give zero")
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// The exhaustive search visits lineups in a fixed order: each combination
+// of pool players from combinations, in lexicographic order, then each of
+// its k! orders as permutations swaps them. A lineup's index is its place
+// in that order, counting lineups -positions or -prefilter skip, so index
+// i is combination i/k! and permutation i%k! of it.
+
+// lineupAt returns the lineup, as roster indices, at index i of the
+// search's enumeration. i must be below s.space().
+func (s *search) lineupAt(i uint64) []int {
+	pool := s.pool()
+	k := s.free()
+	perms := factorial(k)
+	comb := unrankCombination(i/perms, len(pool), k)
+	order := make([]int, k)
+	for j, c := range comb {
+		order[j] = pool[c]
+	}
+	// permutations swaps slot j with each of slots j.. in turn, undoing
+	// each swap after the orders below it, so digit d of the rank picks
+	// the swap with slot j+d.
+	rank := i % perms
+	for j := 0; j < k; j++ {
+		block := factorial(k - j - 1)
+		d := int(rank / block)
+		rank %= block
+		order[j], order[j+d] = order[j+d], order[j]
+	}
+	return s.place(order)
+}
+
+// indexOf is the inverse of lineupAt: the enumeration index of lineup,
+// whose players must come from the roster around any fixed player.
+func (s *search) indexOf(lineup []baseball.Player) (uint64, error) {
+	idx := make([]int, len(lineup))
+	for j, p := range lineup {
+		idx[j] = -1
+		for r, q := range s.players {
+			if q.FirstName == p.FirstName && q.LastName == p.LastName {
+				idx[j] = r
+				break
+			}
+		}
+		if idx[j] < 0 {
+			return 0, fmt.Errorf("%s isn't on the roster", p.LastName)
+		}
+	}
+	if s.fixed >= 0 && idx[s.fixedSlot] != s.fixed {
+		return 0, fmt.Errorf("slot %d isn't the fixed player", s.fixedSlot+1)
+	}
+	order := s.unplace(idx)
+	pos := make(map[int]int)
+	for c, r := range s.pool() {
+		pos[r] = c
+	}
+	cur := make([]int, 0, len(order))
+	for _, r := range order {
+		c, ok := pos[r]
+		if !ok {
+			return 0, fmt.Errorf("the fixed player bats outside their slot")
+		}
+		cur = append(cur, c)
+	}
+	comb := append([]int(nil), cur...)
+	sort.Ints(comb)
+	// Replay the swaps from the sorted combination: each slot's digit is
+	// how far along the player who ends up there was.
+	k := len(cur)
+	work := append([]int(nil), comb...)
+	var rank uint64
+	for j := 0; j < k; j++ {
+		d := 0
+		for work[j+d] != cur[j] {
+			d++
+		}
+		work[j], work[j+d] = work[j+d], work[j]
+		rank += uint64(d) * factorial(k-j-1)
+	}
+	return rankCombination(comb, len(pos))*factorial(k) + rank, nil
+}
+
+// unrankCombination returns the rth k-combination of 0..n-1 in the
+// lexicographic order combinations yields them.
+func unrankCombination(r uint64, n, k int) []int {
+	comb := make([]int, 0, k)
+	next := 0
+	for i := 0; i < k; i++ {
+		for c := next; ; c++ {
+			// Combinations starting here with c fill the other k-i-1 slots
+			// from the n-c-1 numbers above it.
+			count := binomial(n-c-1, k-i-1)
+			if r < count {
+				comb = append(comb, c)
+				next = c + 1
+				break
+			}
+			r -= count
+		}
+	}
+	return comb
+}
+
+// rankCombination is the inverse of unrankCombination for a sorted comb.
+func rankCombination(comb []int, n int) uint64 {
+	var r uint64
+	next := 0
+	k := len(comb)
+	for i, c := range comb {
+		for d := next; d < c; d++ {
+			r += binomial(n-d-1, k-i-1)
+		}
+		next = c + 1
+	}
+	return r
+}
+
+func binomial(n, k int) uint64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	r := uint64(1)
+	for i := 1; i <= k; i++ {
+		r = r * uint64(n-k+i) / uint64(i)
+	}
+	return r
+}
+
+func factorial(n int) uint64 {
+	f := uint64(1)
+	for i := 2; i <= n; i++ {
+		f *= uint64(i)
+	}
+	return f
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestLineupIndexRoundTrips(t *testing.T) {
+	withInt(t, lineupSize, 4)
+	players := testRoster(6)
+	for _, fix := range []string{"", "2:p5"} {
+		s := newSearch(players, nil, baseball.DefaultGameConfig(), 1, nil)
+		if fix != "" {
+			var err error
+			if s.fixed, s.fixedSlot, err = parseFix(fix, players); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// Enumerate the way the search's generator does.
+		var want [][]int
+		pool := s.pool()
+		combinations(len(pool), s.free(), func(ci []int) bool {
+			idx := make([]int, 0, len(ci))
+			for _, c := range ci {
+				idx = append(idx, pool[c])
+			}
+			permutations(idx, func(order []int) bool {
+				want = append(want, s.place(order))
+				return true
+			})
+			return true
+		})
+		if float64(len(want)) != s.space() {
+			t.Fatalf("fix %q: enumerated %d lineups of a %v space", fix, len(want), s.space())
+		}
+		for i, order := range want {
+			if got := s.lineupAt(uint64(i)); fmt.Sprint(got) != fmt.Sprint(order) {
+				t.Fatalf("fix %q: lineup #%d = %v, enumeration has %v", fix, i, got, order)
+			}
+			back, err := s.indexOf(s.lineupOf(order))
+			if err != nil || back != uint64(i) {
+				t.Fatalf("fix %q: lineup %v indexes to %d, %v; want %d", fix, order, back, err, i)
+			}
+		}
+	}
+}
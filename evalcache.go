@@ -0,0 +1,98 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// evalKey identifies one evaluation of a lineup under a cache's config.
+type evalKey struct {
+	Hash  uint64
+	Games int
+	Seed  int64
+}
+
+// evalCache keeps lineup results for re-use by the iterative optimizers,
+// which revisit the same orders many times. It holds results for one
+// GameConfig; the caller keeps it to that. Results are stored as
+// EvaluateLineup returns them, so a caller scoring with -cluster-penalty
+// takes it off after reading. At most size results are kept, the least
+// recently used dropped first. A nil *evalCache caches nothing.
+type evalCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *evalEntry, most recently used first
+	entries map[evalKey]*list.Element
+	// hits and misses count lookups that found a result and ones that
+	// didn't; misses is how many lineups were simulated.
+	hits, misses int
+}
+
+type evalEntry struct {
+	key evalKey
+	res lineupResult
+}
+
+// newEvalCache returns a cache of at most size results, or nil when size
+// isn't positive.
+func newEvalCache(size int) *evalCache {
+	if size <= 0 {
+		return nil
+	}
+	return &evalCache{size: size, order: list.New(), entries: make(map[evalKey]*list.Element)}
+}
+
+// get returns the result stored under k, if any, counting the lookup.
+func (c *evalCache) get(k evalKey) (lineupResult, bool) {
+	if c == nil {
+		return lineupResult{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[k]
+	if !ok {
+		c.misses++
+		return lineupResult{}, false
+	}
+	c.hits++
+	c.order.MoveToFront(e)
+	return e.Value.(*evalEntry).res, true
+}
+
+// put stores res under k, dropping the least recently used result when the
+// cache is full.
+func (c *evalCache) put(k evalKey, res lineupResult) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[k]; ok {
+		e.Value.(*evalEntry).res = res
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[k] = c.order.PushFront(&evalEntry{key: k, res: res})
+	if c.order.Len() > c.size {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.entries, last.Value.(*evalEntry).key)
+	}
+}
+
+// evaluate is EvaluateLineup through the cache: a stored result for order,
+// games and seed is returned without simulating. cfg must be the cache's.
+func (c *evalCache) evaluate(order []baseball.Player, cfg baseball.GameConfig, games int, seed int64) lineupResult {
+	if c == nil {
+		return EvaluateLineup(order, cfg, games, seed)
+	}
+	k := evalKey{Hash: lineupHash(order), Games: games, Seed: seed}
+	if res, ok := c.get(k); ok {
+		return res
+	}
+	res := EvaluateLineup(order, cfg, games, seed)
+	c.put(k, res)
+	return res
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestEvalCacheSimulatesOnce(t *testing.T) {
+	var plays int64
+	cfg := baseball.DefaultGameConfig()
+	cfg.Trace = func(baseball.Play) { atomic.AddInt64(&plays, 1) }
+	order := testRoster(9)
+	c := newEvalCache(10)
+
+	first := c.evaluate(order, cfg, 50, 1)
+	played := atomic.LoadInt64(&plays)
+	if played == 0 {
+		t.Fatal("first evaluation played no games")
+	}
+	again := c.evaluate(order, cfg, 50, 1)
+	if n := atomic.LoadInt64(&plays); n != played {
+		t.Errorf("second evaluation played %d more plate appearances", n-played)
+	}
+	if again.Hash != first.Hash || again.Mean != first.Mean || c.hits != 1 || c.misses != 1 {
+		t.Errorf("cached %x mean %v, first %x mean %v; %d hits, %d misses", again.Hash, again.Mean, first.Hash, first.Mean, c.hits, c.misses)
+	}
+	// Another seed is another evaluation.
+	c.evaluate(order, cfg, 50, 2)
+	if c.misses != 2 {
+		t.Errorf("%d misses after a new seed, want 2", c.misses)
+	}
+}
+
+func TestEvalCacheDropsLeastRecentlyUsed(t *testing.T) {
+	c := newEvalCache(2)
+	a, b, d := evalKey{Hash: 1}, evalKey{Hash: 2}, evalKey{Hash: 3}
+	c.put(a, lineupResult{Mean: 1})
+	c.put(b, lineupResult{Mean: 2})
+	c.get(a)
+	c.put(d, lineupResult{Mean: 3})
+	if _, ok := c.get(b); ok {
+		t.Error("least recently used result survived a full cache")
+	}
+	if res, ok := c.get(a); !ok || res.Mean != 1 {
+		t.Errorf("recently used result: %v, %v", res.Mean, ok)
+	}
+	var none *evalCache
+	none.put(a, lineupResult{Mean: 1})
+	if _, ok := none.get(a); ok || newEvalCache(0) != nil {
+		t.Error("a nil cache cached")
+	}
+}
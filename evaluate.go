@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// EvaluateLineup plays order as given for games seeded games under cfg and
+// returns its result, without touching the search heaps or the global
+// lineup stats. The same order, cfg, games and seed always give the same
+// result.
+func EvaluateLineup(order []baseball.Player, cfg baseball.GameConfig, games int, seed int64) lineupResult {
+	return evaluateWithin(order, cfg, games, seed, 0)
+}
+
+// evaluateWithin is EvaluateLineup with a time limit: a positive limit cuts
+// the games short once it has passed, reporting the ones played and setting
+// TimedOut.
+func evaluateWithin(order []baseball.Player, cfg baseball.GameConfig, games int, seed int64, limit time.Duration) lineupResult {
+	res := lineupResult{Hash: lineupHash(order), lineup: order}
+	for _, p := range order {
+		res.Order = append(res.Order, p.LastName)
+	}
+	played, pa, timedOut := playTimed(order, cfg, games, rand.New(rand.NewSource(seed)), limit)
+	var runs int64
+	var hist runsHistogram
+	for _, n := range played {
+		res.tally.Add(n)
+		if tiebreaker.Kind != "" {
+			hist.add(n)
+		}
+		runs += int64(n)
+	}
+	res.Tiebreak = tiebreaker.value(hist, res.tally)
+	res.TimedOut = timedOut
+	res.Mean = res.tally.Mean()
+	res.Score = res.Mean
+	res.Games = int(res.tally.N)
+	res.StdDev = res.tally.StdDev()
+	if pa > 0 {
+		res.RunsPerPA = float64(runs) / float64(pa)
+	}
+	return res
+}
+
+// resimulateBottom replays each bottom lineup over games fresh games,
+// seeded from its hash, and returns them re-ranked worst first. Extremes
+// are the noisiest results of a search, so a bigger sample firms up which
+// lineups really are the worst.
+func resimulateBottom(results []lineupResult, cfg baseball.GameConfig, games int) []lineupResult {
+	out := make([]lineupResult, len(results))
+	var wg sync.WaitGroup
+	for i, res := range results {
+		wg.Add(1)
+		go func(i int, res lineupResult) {
+			defer wg.Done()
+			out[i] = EvaluateLineup(res.lineup, cfg, games, lineupRandSeed(res.Hash))
+		}(i, res)
+	}
+	wg.Wait()
+	sort.Slice(out, func(i, j int) bool { return ranksAbove(out[j], out[i]) })
+	return out
+}
+
+// readLineup loads the order for -evaluate from path, or stdin for "-". It
+// must hold exactly -lineup-size players.
+func readLineup(path string) ([]baseball.Player, error) {
+	var (
+		lineup []baseball.Player
+		err    error
+	)
+	if path == "-" {
+		lineup, err = LoadPlayers(os.Stdin)
+	} else {
+		lineup, err = loadPlayersFromFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(lineup) != *lineupSize {
+		return nil, fmt.Errorf("have %d players, want exactly %d", len(lineup), *lineupSize)
+	}
+	return lineup, nil
+}
+
+// writeEvaluation renders a single -evaluate result as "text", or as a
+// one-lineup report in "json" or "csv" with rc as its config.
+func writeEvaluation(w io.Writer, format string, res lineupResult, rc *runConfig) error {
+	switch format {
+	case "text":
+		fmt.Fprintf(w, "ID=%s mean=%.3f ±%.3f stddev=%.3f runs/PA=%.4f games=%d  order=%v\n",
+			res.ID(), res.Mean, res.tally.HalfWidth95(), res.StdDev, res.RunsPerPA, res.Games, res.Order)
+		if res.TimedOut {
+			fmt.Fprintf(w, "Timed out after %d games; the stats cover only those\n", res.Games)
+		}
+		return nil
+	case "json", "csv":
+		return writeReport(w, format, report{Config: rc, Top: []lineupResult{res}})
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
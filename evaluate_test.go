@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestEvaluateLineupIsReproducible(t *testing.T) {
+	order := testRoster(9)
+	cfg := baseball.DefaultGameConfig()
+	res := EvaluateLineup(order, cfg, 500, 11)
+
+	// The mean is that of the same games played by hand from the seed.
+	r := rand.New(rand.NewSource(11))
+	runs := 0
+	for g := 0; g < 500; g++ {
+		runs += baseball.SimulateGame(order, cfg, r).Runs
+	}
+	if want := float64(runs) / 500; res.Mean != want || res.Score != want {
+		t.Errorf("mean %v, score %v; want %v", res.Mean, res.Score, want)
+	}
+	if res.Games != 500 || res.Hash != lineupHash(order) || res.Order[0] != "P1" {
+		t.Errorf("%d games of %x %v", res.Games, res.Hash, res.Order)
+	}
+
+	again := EvaluateLineup(order, cfg, 500, 11)
+	if again.Mean != res.Mean || again.StdDev != res.StdDev || again.RunsPerPA != res.RunsPerPA {
+		t.Errorf("second evaluation %+v, first %+v", again, res)
+	}
+}
+
+func TestEvaluateFromStdin(t *testing.T) {
+	order := testRoster(9)
+	data, _ := json.Marshal(order)
+	stdout, _ := runMainStdin(t, string(data), "-evaluate", "-", "-games", "300", "-seed", "5")
+
+	want := EvaluateLineup(order, baseball.DefaultGameConfig(), 300, 5)
+	if !strings.Contains(stdout, fmt.Sprintf("mean=%.3f ", want.Mean)) || !strings.Contains(stdout, "games=300") {
+		t.Errorf("printed %q, want mean=%.3f over 300 games", stdout, want.Mean)
+	}
+}
+
+func TestResimulateBottomReranks(t *testing.T) {
+	withInt(t, &bottomK, 5)
+	cfg := baseball.DefaultGameConfig()
+	s := runSearch(t, testRoster(6), 6, cfg, 20)
+	bottom := s.bottomResults()
+
+	got := resimulateBottom(bottom, cfg, 1000)
+	if len(got) != len(bottom) {
+		t.Fatalf("re-simulated %d lineups, want %d", len(got), len(bottom))
+	}
+	for i, res := range got {
+		if res.Games != 1000 {
+			t.Errorf("#%d played %d games, want 1000", i+1, res.Games)
+		}
+		if i > 0 && ranksAbove(got[i-1], res) {
+			t.Errorf("#%d (%.3f) ranks above #%d (%.3f); want worst first", i, got[i-1].Mean, i+1, res.Mean)
+		}
+		if want := EvaluateLineup(res.lineup, cfg, 1000, lineupRandSeed(res.Hash)); res.Mean != want.Mean {
+			t.Errorf("#%d mean %v, a fresh evaluation gives %v", i+1, res.Mean, want.Mean)
+		}
+	}
+}
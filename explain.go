@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// matchesID reports whether id names the lineup with this hash, either as a
+// prefix of its hex ID or as the full decimal hash.
+func matchesID(hash uint64, id string) bool {
+	return strings.HasPrefix(fmt.Sprintf("%x", hash), id) || strconv.FormatUint(hash, 10) == id
+}
+
+// percentile is one point of a lineup's runs-per-game distribution.
+type percentile struct {
+	P    int `json:"p"`
+	Runs int `json:"runs"`
+}
+
+// explanation is the -explain drill-down for a single lineup.
+type explanation struct {
+	ID    string   `json:"id"`
+	Hash  uint64   `json:"hash"`
+	Order []string `json:"order"`
+	// Index is the lineup's place in the exhaustive enumeration, as
+	// -lineup-index takes it.
+	Index uint64 `json:"index"`
+
+	// SearchMean and Rank come from the search's own games; Rank is by
+	// mean runs among all Of lineups processed.
+	SearchMean float64 `json:"search_mean"`
+	Rank       int     `json:"rank"`
+	Of         int     `json:"of"`
+	// SearchPA and SearchOuts are the plate appearances and outs behind
+	// SearchMean.
+	SearchPA   int64 `json:"search_pa"`
+	SearchOuts int64 `json:"search_outs"`
+
+	// The rest comes from a fresh replay of Games games.
+	Games       int          `json:"games"`
+	Mean        float64      `json:"mean"`
+	StdDev      float64      `json:"stddev"`
+	Percentiles []percentile `json:"percentiles"`
+	InningMeans []float64    `json:"inning_means"`
+	Players     []slotLine   `json:"players"`
+	// NoRBI is the replay's runs per game that scored without an RBI, the
+	// rest of the runs beyond the slots' RBI.
+	NoRBI float64 `json:"no_rbi"`
+}
+
+// explainLineup ranks the lineup with hash against every lineup in
+// lineupStats and replays it games times for its run distribution, runs by
+// inning and per-slot attribution.
+func explainLineup(lineup []baseball.Player, hash uint64, cfg baseball.GameConfig, games int, r *rand.Rand) explanation {
+	e := explanation{Hash: hash, Games: games}
+	e.ID = fmt.Sprintf("%x", hash)[:6]
+	for _, p := range lineup {
+		e.Order = append(e.Order, p.LastName)
+	}
+	e.SearchMean, e.Rank, e.Of = rankByMean(hash)
+	if v, ok := lineupStats.Load(hash); ok {
+		a := v.(*Agg)
+		e.SearchPA, e.SearchOuts = atomic.LoadInt64(&a.PAs), atomic.LoadInt64(&a.Outs)
+	}
+
+	cfg.TrackSlots = true
+	var tally runTally
+	runs := make([]int, 0, games)
+	e.InningMeans = make([]float64, 9)
+	for g := 0; g < games; g++ {
+		game := baseball.SimulateGame(lineup, cfg, r)
+		tally.Add(game.Runs)
+		runs = append(runs, game.Runs)
+		for i, n := range game.LineScore {
+			e.InningMeans[i] += float64(n) / float64(games)
+		}
+	}
+	e.Mean = tally.Mean()
+	e.StdDev = tally.StdDev()
+	sort.Ints(runs)
+	for _, p := range []int{10, 25, 50, 75, 90} {
+		e.Percentiles = append(e.Percentiles, percentile{P: p, Runs: runs[nearestRank(len(runs), p)]})
+	}
+	e.Players, e.NoRBI = slotBreakdown(lineup, cfg, games, r)
+	return e
+}
+
+// nearestRank is the 0-based index of the pth percentile of n sorted
+// values by the nearest-rank method.
+func nearestRank(n, p int) int {
+	i := (p*n+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+// runsHistogram counts games by runs scored, for percentiles of a lineup's
+// games without keeping them all.
+type runsHistogram []int
+
+func (h *runsHistogram) add(runs int) {
+	for len(*h) <= runs {
+		*h = append(*h, 0)
+	}
+	(*h)[runs]++
+}
+
+// percentile is the nearest-rank pth percentile of the games in h.
+func (h runsHistogram) percentile(p int) int {
+	n := 0
+	for _, c := range h {
+		n += c
+	}
+	return h.at(nearestRank(n, p))
+}
+
+// at returns the runs of the ith game (0-based) in h sorted by runs.
+func (h runsHistogram) at(i int) int {
+	for runs, c := range h {
+		if i < c {
+			return runs
+		}
+		i -= c
+	}
+	return 0
+}
+
+// median is the median runs of the games in h, the mean of the middle two
+// when there's an even number of games.
+func (h runsHistogram) median() float64 {
+	n := 0
+	for _, c := range h {
+		n += c
+	}
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return float64(h.at(n / 2))
+	}
+	return float64(h.at(n/2-1)+h.at(n/2)) / 2
+}
+
+// mode is the most frequent single-game run total in h, the lowest of any
+// tied for most.
+func (h runsHistogram) mode() int {
+	best := 0
+	for runs, c := range h {
+		if c > h[best] {
+			best = runs
+		}
+	}
+	return best
+}
+
+// rankByMean returns the search mean of the lineup with hash and its 1-based
+// rank by mean runs among all lineups in lineupStats, along with their count.
+func rankByMean(hash uint64) (mean float64, rank, of int) {
+	aggMean := func(a *Agg) float64 {
+		if a.Games == 0 {
+			return 0
+		}
+		return float64(a.Runs) / float64(a.Games)
+	}
+	if v, ok := lineupStats.Load(hash); ok {
+		mean = aggMean(v.(*Agg))
+	}
+	rank = 1
+	lineupStats.Range(func(_, v interface{}) bool {
+		of++
+		if aggMean(v.(*Agg)) > mean {
+			rank++
+		}
+		return true
+	})
+	return mean, rank, of
+}
+
+// writeExplanation renders e to w as "text" or "json".
+func writeExplanation(w io.Writer, format string, e explanation) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	}
+	fmt.Fprintf(w, "Lineup ID=%s index=%d  order=%v\n", e.ID, e.Index, e.Order)
+	fmt.Fprintf(w, "Search mean %.3f, rank %d of %d\n", e.SearchMean, e.Rank, e.Of)
+	if e.SearchPA > 0 {
+		fmt.Fprintf(w, "Search PAs %d, outs %d (%.3f outs per PA)\n", e.SearchPA, e.SearchOuts, float64(e.SearchOuts)/float64(e.SearchPA))
+	}
+	fmt.Fprintf(w, "Replay of %d games: mean=%.3f stddev=%.3f\n", e.Games, e.Mean, e.StdDev)
+	fmt.Fprint(w, "Runs percentiles:")
+	for _, p := range e.Percentiles {
+		fmt.Fprintf(w, "  p%d=%d", p.P, p.Runs)
+	}
+	fmt.Fprint(w, "\nRuns by inning:  ")
+	for _, m := range e.InningMeans {
+		fmt.Fprintf(w, " %.2f", m)
+	}
+	fmt.Fprintf(w, "\n%4s  %-22s %5s %5s %5s %5s\n", "Slot", "Player", "RBI", "R", "OB", "XBH")
+	for _, s := range e.Players {
+		fmt.Fprintf(w, "%4d  %-22s %5.2f %5.2f %5.2f %5.2f\n", s.Slot, s.Name, s.RBI, s.Runs, s.TimesOnBase, s.ExtraBaseHits)
+	}
+	if e.NoRBI > 0 {
+		fmt.Fprintf(w, "%4s  %-22s %5.2f\n", "", "(no RBI: WP, dropped K)", e.NoRBI)
+	}
+	return nil
+}
+
+// explainIDs lists the lineup IDs named by -explain and -explain-diff.
+func explainIDs() []string {
+	var ids []string
+	if *explainID != "" {
+		ids = append(ids, *explainID)
+	}
+	if *explainDiff != "" {
+		ids = append(ids, strings.Split(*explainDiff, ",")...)
+	}
+	return ids
+}
+
+// slotDiff attributes the difference in mean runs between lineups A and B
+// to batting slots.
+type slotDiff struct {
+	AID   string      `json:"a_id"`
+	A     []string    `json:"a_order"`
+	BID   string      `json:"b_id"`
+	B     []string    `json:"b_order"`
+	Games int         `json:"games"`
+	Delta float64     `json:"delta"`
+	Slots []slotDelta `json:"slots"`
+	// NoRBI is the change in runs that scored without an RBI.
+	NoRBI float64 `json:"no_rbi"`
+}
+
+// slotDelta is one slot's share of A's runs over B's: RBI is the change in
+// runs driven in from the slot, which with the slotDiff's NoRBI sums to the
+// total delta, and Runs the change in runs scored by whoever bats there.
+type slotDelta struct {
+	Slot int     `json:"slot"`
+	A    string  `json:"a"`
+	B    string  `json:"b"`
+	RBI  float64 `json:"rbi"`
+	Runs float64 `json:"runs"`
+}
+
+// diffSlots replays a and b over the same seeded games with slot tracking
+// and differences their per-slot lines.
+func diffSlots(a, b lineupResult, cfg baseball.GameConfig, games int, seed int64) slotDiff {
+	la, na := slotBreakdown(a.lineup, cfg, games, rand.New(rand.NewSource(seed)))
+	lb, nb := slotBreakdown(b.lineup, cfg, games, rand.New(rand.NewSource(seed)))
+	d := slotDiff{A: a.Order, B: b.Order, AID: a.ID(), BID: b.ID(), Games: games, NoRBI: na - nb}
+	d.Delta = d.NoRBI
+	for i := range la {
+		sd := slotDelta{Slot: i + 1, A: la[i].Name, B: lb[i].Name, RBI: la[i].RBI - lb[i].RBI, Runs: la[i].Runs - lb[i].Runs}
+		d.Delta += sd.RBI
+		d.Slots = append(d.Slots, sd)
+	}
+	return d
+}
+
+// writeSlotDiff renders d to w as "text" or "json".
+func writeSlotDiff(w io.Writer, format string, d slotDiff) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	fmt.Fprintf(w, "Lineup A ID=%s  order=%v\n", d.AID, d.A)
+	fmt.Fprintf(w, "Lineup B ID=%s  order=%v\n", d.BID, d.B)
+	fmt.Fprintf(w, "A scores %+.3f runs per game over B across %d shared games\n", d.Delta, d.Games)
+	fmt.Fprintf(w, "%4s  %-22s %-22s %6s %6s\n", "Slot", "A", "B", "dRBI", "dR")
+	for _, s := range d.Slots {
+		fmt.Fprintf(w, "%4d  %-22s %-22s %+6.2f %+6.2f\n", s.Slot, s.A, s.B, s.RBI, s.Runs)
+	}
+	if d.NoRBI != 0 {
+		fmt.Fprintf(w, "%4s  %-45s %+6.2f\n", "", "(no RBI: WP, dropped K)", d.NoRBI)
+	}
+	return nil
+}
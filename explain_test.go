@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// clearLineupStats forgets the aggregates earlier searches in the test
+// binary left behind.
+func clearLineupStats() {
+	lineupStats.Range(func(k, _ interface{}) bool {
+		lineupStats.Delete(k)
+		return true
+	})
+}
+
+func TestExplainLineupMeanAndRank(t *testing.T) {
+	clearLineupStats()
+	withInt(t, &topK, 720)
+	cfg := baseball.DefaultGameConfig()
+	s := runSearch(t, testRoster(6), 6, cfg, 40)
+	results := s.topResults()
+	if len(results) != 720 {
+		t.Fatalf("kept %d lineups, want all 720", len(results))
+	}
+	for _, i := range []int{0, 357, 719} {
+		want := results[i]
+		rank := 1
+		for _, r := range results {
+			if r.Mean > want.Mean {
+				rank++
+			}
+		}
+		e := explainLineup(want.lineup, want.Hash, cfg, 20, rand.New(rand.NewSource(1)))
+		if e.SearchMean != want.Mean || e.Rank != rank || e.Of != 720 {
+			t.Errorf("lineup %s: mean %v rank %d of %d; want %v, %d of 720", want.ID(), e.SearchMean, e.Rank, e.Of, want.Mean, rank)
+		}
+		if e.Games != 20 || len(e.Order) != 6 || e.Order[0] != want.lineup[0].LastName {
+			t.Errorf("lineup %s: replayed %d games of order %v", want.ID(), e.Games, e.Order)
+		}
+	}
+}
+
+func TestDiffSlotsLocalizesSwap(t *testing.T) {
+	a := nineOf(testPlayer("Avg", 0.320, 0.400))
+	a[2] = testPlayer("Star", 0.450, 0.700)
+	a[6] = testPlayer("Weak", 0.200, 0.200)
+	b := append([]baseball.Player(nil), a...)
+	b[2], b[6] = b[6], b[2]
+	ra := lineupResult{Hash: lineupHash(a), lineup: a}
+	rb := lineupResult{Hash: lineupHash(b), lineup: b}
+
+	d := diffSlots(ra, rb, baseball.DefaultGameConfig(), 2000, 1)
+	if len(d.Slots) != 9 || d.Slots[2].A != "Test Star" || d.Slots[2].B != "Test Weak" {
+		t.Fatalf("slots %+v", d.Slots)
+	}
+	// The two slots that swapped move the most: the star's gains runs
+	// and the weak hitter's loses them.
+	for i, s := range d.Slots {
+		if i == 2 || i == 6 {
+			continue
+		}
+		if math.Abs(s.Runs) >= d.Slots[2].Runs || math.Abs(s.Runs) >= -d.Slots[6].Runs {
+			t.Errorf("slot %d moved %+.3f runs, swapped slots %+.3f and %+.3f", s.Slot, s.Runs, d.Slots[2].Runs, d.Slots[6].Runs)
+		}
+	}
+	sum := d.NoRBI
+	for _, s := range d.Slots {
+		sum += s.RBI
+	}
+	if math.Abs(sum-d.Delta) > 1e-9 {
+		t.Errorf("slot RBI and no-RBI runs sum to %v, delta %v", sum, d.Delta)
+	}
+}
+
+func TestRunsHistogramPercentiles(t *testing.T) {
+	// Twenty games: 0,1,...,9 runs once each and 10 runs ten times.
+	var h runsHistogram
+	var games []int
+	for runs := 0; runs < 10; runs++ {
+		h.add(runs)
+		games = append(games, runs)
+	}
+	for i := 0; i < 10; i++ {
+		h.add(10)
+		games = append(games, 10)
+	}
+	sort.Ints(games)
+	for _, p := range []int{10, 50, 90} {
+		if got, want := h.percentile(p), games[nearestRank(len(games), p)]; got != want {
+			t.Errorf("p%d = %d, want %d", p, got, want)
+		}
+	}
+	if h.percentile(10) != 1 || h.percentile(90) != 10 {
+		t.Errorf("floor %d and ceiling %d, want 1 and 10", h.percentile(10), h.percentile(90))
+	}
+}
+
+func TestMedianAndModeDifferFromMean(t *testing.T) {
+	withBool(t, medianMode, true)
+	// Mode 1, median 2.5 and mean 4.
+	res := lineupResult{Hash: 1 << 60, Order: []string{"P1", "P2", "P3", "P4"}}
+	for _, runs := range []int{1, 1, 1, 2, 3, 4, 9, 11} {
+		res.tally.Add(runs)
+		res.hist.add(runs)
+	}
+	res.summarize()
+	res.Mean = res.tally.Mean()
+	if c := res.Center; c == nil || c.Median != 2.5 || c.Mode != 1 || res.Mean != 4 {
+		t.Fatalf("center %+v, mean %v; want median 2.5, mode 1, mean 4", c, res.Mean)
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "text", report{Top: []lineupResult{res}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "mean=4.000 median=2.5 mode=1 ") {
+		t.Errorf("report:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// dumpFieldStates plays res's lineup once from seed and writes the bases
+// before and after every plate appearance, for debugging baserunning. The
+// closing count of PAs dumped should match the game's own.
+func dumpFieldStates(w io.Writer, res lineupResult, cfg baseball.GameConfig, seed int64) {
+	fmt.Fprintf(w, "Field states for lineup ID=%s, seed %d:\n", res.ID(), seed)
+	fmt.Fprintf(w, "%4s %3s %4s  %-12s %-8s  %-10s -> %-10s %4s\n", "PA", "Inn", "Outs", "Batter", "Outcome", "Before", "After", "Runs")
+	pa := 0
+	cfg.Trace = func(p baseball.Play) {
+		pa++
+		outcome := p.Outcome.String()
+		if p.DoublePlay() {
+			outcome = "gidp"
+		} else if p.Dropped {
+			outcome = "k-drop"
+		} else if p.Strikeout {
+			outcome = "k"
+		} else if p.Stretching {
+			outcome = "1b-out"
+		}
+		fmt.Fprintf(w, "%4d %3d %4d  %-12s %-8s  %-10s -> %-10s %4d\n",
+			pa, p.Inning, p.OutsBefore, p.Batter.LastName, outcome, p.Before, p.After, p.Runs)
+	}
+	game := baseball.SimulateGame(res.lineup, cfg, rand.New(rand.NewSource(seed)))
+	fmt.Fprintf(w, "%d plate appearances dumped of the game's %d, %d runs\n", pa, game.PA, game.Runs)
+}
+
+// dumpSeed is the seed -dump-field-states plays its game from: -game-seed
+// when set, so a game found by -max-game can be dumped, else the run's.
+func dumpSeed() int64 {
+	if *gameSeed != 0 {
+		return *gameSeed
+	}
+	return baseSeed()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestDumpFieldStatesHasALinePerPA(t *testing.T) {
+	lineup := nineOf(testPlayer("Hitter", 0.330, 0.420))
+	res := lineupResult{Hash: lineupHash(lineup), lineup: lineup}
+	cfg := baseball.DefaultGameConfig()
+	var buf bytes.Buffer
+	dumpFieldStates(&buf, res, cfg, 7)
+
+	game := baseball.SimulateGame(lineup, cfg, rand.New(rand.NewSource(7)))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	// A title, a header, a line per PA and the closing count.
+	if len(lines) != game.PA+3 {
+		t.Fatalf("dumped %d lines for a %d-PA game:\n%s", len(lines), game.PA, buf.String())
+	}
+	want := fmt.Sprintf("%d plate appearances dumped of the game's %d, %d runs", game.PA, game.PA, game.Runs)
+	if last := lines[len(lines)-1]; last != want {
+		t.Errorf("closing line %q, want %q", last, want)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// gaOptions configures the genetic search.
+type gaOptions struct {
+	Generations int
+	Population  int
+	// Minimize searches for the lowest mean instead of the highest.
+	Minimize bool
+	// Resume, when set, continues from a saved population instead of a
+	// random one.
+	Resume *gaState
+	// Cache keeps scored lineups so survivors and repeats aren't simulated
+	// again; nil simulates every member of every generation.
+	Cache *evalCache
+	// Patience stops the search early once the best score has moved less
+	// than Tolerance over that many generations, but never within the
+	// first BurnIn generations. Zero runs every generation.
+	Patience, BurnIn int
+	Tolerance        float64
+}
+
+// gaMember is one lineup in the population, as roster indices in batting
+// order.
+type gaMember struct {
+	idx []int
+	res lineupResult
+}
+
+// runGA evolves lineups from s.players under the search's constraints: the
+// fixed player (-leadoff-obp or -fix) keeps their slot and, with
+// -positions, every lineup must field a valid alignment. Each lineup's games are seeded from
+// its hash, so a lineup always gets the same score, and opt.Cache saves
+// simulating it again. It returns the best (or, when minimizing, worst)
+// lineup found and how many lineups were simulated, along with the state to
+// continue from and the convergence history of the generations run.
+func (s *search) runGA(opt gaOptions, r *rand.Rand) (lineupResult, int, gaState, []gaGeneration) {
+	pool := s.pool()
+
+	valid := func(idx []int) bool {
+		if !*positions {
+			return true
+		}
+		return baseball.ValidAlignment(s.lineupOf(idx))
+	}
+	random := func() []int {
+		for try := 0; ; try++ {
+			p := append([]int(nil), pool...)
+			r.Shuffle(len(p), func(i, j int) { p[i], p[j] = p[j], p[i] })
+			idx := s.place(p[:s.free()])
+			if valid(idx) || try >= 1000 {
+				return idx
+			}
+		}
+	}
+
+	// better orders members by the objective, breaking ties the way the
+	// exhaustive search does.
+	better := func(a, b lineupResult) bool {
+		if opt.Minimize {
+			return ranksAbove(b, a)
+		}
+		return ranksAbove(a, b)
+	}
+
+	var simulated int64
+	score := func(pop []gaMember) {
+		sem := make(chan struct{}, runtime.NumCPU())
+		var wg sync.WaitGroup
+		for i := range pop {
+			lineup := s.lineupOf(pop[i].idx)
+			hash := lineupHash(lineup)
+			key := evalKey{Hash: hash, Games: s.games, Seed: lineupRandSeed(hash)}
+			if res, ok := opt.Cache.get(key); ok {
+				res.Score = res.Mean - clusterPenalty(lineup, s.cfg)
+				pop[i].res = res
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(m *gaMember) {
+				defer wg.Done()
+				m.res = EvaluateLineup(lineup, s.cfg, s.games, key.Seed)
+				opt.Cache.put(key, m.res)
+				m.res.Score = m.res.Mean - clusterPenalty(lineup, s.cfg)
+				atomic.AddInt64(&simulated, 1)
+				<-sem
+			}(&pop[i])
+		}
+		wg.Wait()
+		sort.Slice(pop, func(i, j int) bool { return better(pop[i].res, pop[j].res) })
+	}
+
+	var pop []gaMember
+	var done int
+	if st := opt.Resume; st != nil {
+		for _, idx := range st.Population {
+			pop = append(pop, gaMember{idx: idx})
+		}
+		done = st.Generations
+		// The saved best may have been bred out of the population, so it
+		// rejoins it for scoring and the weakest member makes room.
+		pop = append(pop, gaMember{idx: st.Best})
+		score(pop)
+		pop = pop[:len(pop)-1]
+	} else {
+		pop = make([]gaMember, opt.Population)
+		for i := range pop {
+			pop[i].idx = random()
+		}
+		score(pop)
+	}
+	best, bestIdx := pop[0].res, pop[0].idx
+	history := []gaGeneration{newGAGeneration(done, pop, best)}
+
+	const elite = 2
+	pick := func() []int {
+		// Tournament of three.
+		w := r.Intn(len(pop))
+		for k := 0; k < 2; k++ {
+			if c := r.Intn(len(pop)); c < w {
+				w = c
+			}
+		}
+		return pop[w].idx
+	}
+	ran := 0
+	for ran < opt.Generations && !converged(history, opt.Patience, opt.BurnIn, opt.Tolerance) {
+		next := make([]gaMember, 0, len(pop))
+		for i := 0; i < elite && i < len(pop); i++ {
+			next = append(next, pop[i])
+		}
+		for len(next) < len(pop) {
+			a, b := pick(), pick()
+			child := crossover(s.unplace(a), s.unplace(b), r)
+			mutate(child, pool, r)
+			idx := s.place(child)
+			if !valid(idx) {
+				idx = append([]int(nil), a...)
+			}
+			next = append(next, gaMember{idx: idx})
+		}
+		pop = next
+		score(pop)
+		if better(pop[0].res, best) {
+			best, bestIdx = pop[0].res, pop[0].idx
+		}
+		ran++
+		history = append(history, newGAGeneration(done+ran, pop, best))
+	}
+
+	st := gaState{
+		Version:     gaStateVersion,
+		Roster:      rosterNames(s.players),
+		Minimize:    opt.Minimize,
+		Generations: done + ran,
+		Seed:        r.Int63(),
+		Best:        bestIdx,
+		BestMean:    best.Mean,
+	}
+	for _, m := range pop {
+		st.Population = append(st.Population, m.idx)
+	}
+	return best, int(simulated), st, history
+}
+
+// lineupOf returns the players at roster indices idx, in order.
+func (s *search) lineupOf(idx []int) []baseball.Player {
+	lineup := make([]baseball.Player, len(idx))
+	for i, p := range idx {
+		lineup[i] = s.players[p]
+	}
+	return lineup
+}
+
+// crossover is an order crossover: the child keeps a random run of slots
+// from a and fills the rest, in order, with b's players that aren't already
+// in it.
+func crossover(a, b []int, r *rand.Rand) []int {
+	n := len(a)
+	i, j := r.Intn(n), r.Intn(n)
+	if i > j {
+		i, j = j, i
+	}
+	child := make([]int, n)
+	used := make(map[int]bool, n)
+	for k := i; k <= j; k++ {
+		child[k] = a[k]
+		used[a[k]] = true
+	}
+	k := (j + 1) % n
+	for _, p := range append(append([]int(nil), b...), a...) {
+		if k == i {
+			break
+		}
+		if used[p] {
+			continue
+		}
+		child[k] = p
+		used[p] = true
+		k = (k + 1) % n
+	}
+	return child
+}
+
+// mutate swaps two slots of lineup and sometimes brings in a player from
+// pool who isn't in it.
+func mutate(lineup, pool []int, r *rand.Rand) {
+	if r.Float64() < 0.3 {
+		i, j := r.Intn(len(lineup)), r.Intn(len(lineup))
+		lineup[i], lineup[j] = lineup[j], lineup[i]
+	}
+	if len(pool) > len(lineup) && r.Float64() < 0.2 {
+		in := make(map[int]bool, len(lineup))
+		for _, p := range lineup {
+			in[p] = true
+		}
+		var bench []int
+		for _, p := range pool {
+			if !in[p] {
+				bench = append(bench, p)
+			}
+		}
+		lineup[r.Intn(len(lineup))] = bench[r.Intn(len(bench))]
+	}
+}
+
+// optimum is the single lineup a -ga or -worst run reports, next to the
+// first -lineup-size players in file order played over the same kind of
+// seeded games.
+type optimum struct {
+	Config      *runConfig   `json:"config"`
+	Method      string       `json:"method"` // "exhaustive" or "ga"
+	Minimize    bool         `json:"minimize"`
+	Generations int          `json:"generations,omitempty"`
+	Evaluated   int          `json:"evaluated"`
+	Lineup      lineupResult `json:"lineup"`
+	FileOrder   lineupResult `json:"file_order"`
+	// History is a -ga run's best score by generation, and Converged is
+	// set when -ga-patience stopped it before -ga generations.
+	History   []gaGeneration `json:"history,omitempty"`
+	Converged bool           `json:"converged,omitempty"`
+}
+
+// fileOrder plays the first -lineup-size players in file order, seeded from
+// their hash like a -ga lineup.
+func fileOrder(players []baseball.Player, cfg baseball.GameConfig) lineupResult {
+	lineup := players[:*lineupSize]
+	return EvaluateLineup(lineup, cfg, *games, lineupRandSeed(lineupHash(lineup)))
+}
+
+func writeOptimum(w io.Writer, format string, o optimum) error {
+	switch format {
+	case "text":
+		what := "Best"
+		if o.Minimize {
+			what = "Worst"
+		}
+		if o.Method == "ga" {
+			fmt.Fprintf(w, "%s lineup after %d generations (%d lineups simulated):\n", what, o.Generations, o.Evaluated)
+			if len(o.History) > 0 {
+				last := o.History[len(o.History)-1]
+				fmt.Fprintf(w, "  best %.3f last improved in generation %d of %d", last.Best, lastImproved(o.History), last.Gen)
+				if o.Converged {
+					fmt.Fprintf(w, "; stopped early, converged")
+				}
+				fmt.Fprintln(w)
+			}
+		} else {
+			fmt.Fprintf(w, "%s of %d lineups:\n", what, o.Evaluated)
+		}
+		fmt.Fprintf(w, "  ID=%s mean=%.3f ±%.3f  order=%v\n", o.Lineup.ID(), o.Lineup.Mean, o.Lineup.tally.HalfWidth95(), o.Lineup.Order)
+		fmt.Fprintf(w, "File order: mean=%.3f (%+.3f)  order=%v\n", o.FileOrder.Mean, o.Lineup.Mean-o.FileOrder.Mean, o.FileOrder.Order)
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(o)
+	}
+	return fmt.Errorf("format %q isn't supported with -ga or -worst", format)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestWorstFindsLowerThanFileOrder(t *testing.T) {
+	withInt(t, lineupSize, 9)
+	withInt(t, games, 100)
+	withInt64(t, seed, 5)
+	players := testRoster(12)
+	cfg := baseball.DefaultGameConfig()
+	s := newSearch(players, nil, cfg, *games, nil)
+	worst, evaluated, _, _ := s.runGA(gaOptions{Generations: 15, Population: 20, Minimize: true}, rand.New(rand.NewSource(1)))
+	file := fileOrder(players, cfg)
+	if worst.Mean >= file.Mean {
+		t.Errorf("worst lineup %v scores %.3f, file order %.3f", worst.Order, worst.Mean, file.Mean)
+	}
+	if evaluated == 0 || len(worst.Order) != 9 {
+		t.Errorf("simulated %d lineups and kept %v", evaluated, worst.Order)
+	}
+}
+
+func TestGAContinueNeverRegresses(t *testing.T) {
+	withInt(t, lineupSize, 9)
+	withInt64(t, seed, 5)
+	players := testRoster(12)
+	s := newSearch(players, nil, baseball.DefaultGameConfig(), 50, nil)
+	best, _, st, _ := s.runGA(gaOptions{Generations: 5, Population: 16}, rand.New(rand.NewSource(1)))
+
+	path := filepath.Join(t.TempDir(), "ga.json")
+	if err := saveGAState(path, st); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadGAState(path, players, 9, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Generations != 5 || !reflect.DeepEqual(loaded.Best, st.Best) {
+		t.Errorf("reloaded %d generations, best %v; saved %d, %v", loaded.Generations, loaded.Best, st.Generations, st.Best)
+	}
+
+	more, _, st2, _ := s.runGA(gaOptions{Generations: 5, Population: 16, Resume: loaded}, rand.New(rand.NewSource(loaded.Seed)))
+	if ranksAbove(best, more) {
+		t.Errorf("continuing dropped the best from %.3f %v to %.3f %v", best.Score, best.Order, more.Score, more.Order)
+	}
+	if st2.Generations != 10 {
+		t.Errorf("continued state counts %d generations, want 10", st2.Generations)
+	}
+}
+
+func TestGAHistoryBestNeverFalls(t *testing.T) {
+	withInt(t, lineupSize, 9)
+	withInt64(t, seed, 5)
+	s := newSearch(testRoster(12), nil, baseball.DefaultGameConfig(), 50, nil)
+	best, _, _, history := s.runGA(gaOptions{Generations: 12, Population: 16}, rand.New(rand.NewSource(1)))
+
+	// Generation 0 is the starting population.
+	if len(history) != 13 {
+		t.Fatalf("%d generations recorded, want 13", len(history))
+	}
+	for i, g := range history {
+		if g.Gen != i || g.Top > g.Best {
+			t.Errorf("generation %d recorded as %+v", i, g)
+		}
+		if i > 0 && g.Best < history[i-1].Best {
+			t.Errorf("best fell from %.3f to %.3f in generation %d", history[i-1].Best, g.Best, i)
+		}
+	}
+	if last := history[len(history)-1]; last.Best != best.Score {
+		t.Errorf("history ends at %.3f, run's best %.3f", last.Best, best.Score)
+	}
+}
+
+func TestGACachesUnpenalizedResults(t *testing.T) {
+	withInt(t, lineupSize, 9)
+	withInt64(t, seed, 5)
+	withFloat(t, clusterRuns, 1)
+	withFloat(t, clusterOBP, 0.400) // everyone is low: nine pairs
+	cfg := baseball.DefaultGameConfig()
+	s := newSearch(testRoster(10), nil, cfg, 30, nil)
+	cache := newEvalCache(10000)
+	opt := gaOptions{Generations: 3, Population: 12, Cache: cache}
+	best, _, _, _ := s.runGA(opt, rand.New(rand.NewSource(1)))
+	if best.Score != best.Mean-9 {
+		t.Fatalf("best scored %v with mean %v, want the mean less 9", best.Score, best.Mean)
+	}
+
+	// The hill climb reads the same cache and applies the penalty itself.
+	hits := cache.hits
+	res := cache.evaluate(best.lineup, cfg, 30, lineupRandSeed(best.Hash))
+	if cache.hits != hits+1 || res.Score != res.Mean {
+		t.Errorf("cached result scored %v with mean %v, want it unpenalized", res.Score, res.Mean)
+	}
+	again, _, _, _ := s.runGA(opt, rand.New(rand.NewSource(1)))
+	if again.Hash != best.Hash || again.Score != best.Score {
+		t.Errorf("a rerun from the cache found %s scoring %v, want %s at %v", again.ID(), again.Score, best.ID(), best.Score)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// gaGeneration is one generation of a -ga run in its convergence history.
+// Generation 0 is the starting population, scored before any breeding.
+type gaGeneration struct {
+	Gen int `json:"gen"`
+	// Best is the best score found so far, so it never gets worse from one
+	// generation to the next; Top is the best in this generation alone and
+	// Mean the population's average.
+	Best float64 `json:"best"`
+	Top  float64 `json:"top"`
+	Mean float64 `json:"mean"`
+}
+
+// newGAGeneration records pop, sorted best first, as generation gen with
+// best the best result found so far.
+func newGAGeneration(gen int, pop []gaMember, best lineupResult) gaGeneration {
+	g := gaGeneration{Gen: gen, Best: best.Score, Top: pop[0].res.Score}
+	for _, m := range pop {
+		g.Mean += m.res.Score
+	}
+	g.Mean /= float64(len(pop))
+	return g
+}
+
+// converged reports whether a GA should stop after the last generation in
+// history: past burnIn generations, the best score has moved less than tol
+// over the last patience generations. A patience of 0 never stops early.
+func converged(history []gaGeneration, patience, burnIn int, tol float64) bool {
+	n := len(history) - 1
+	if patience <= 0 || n < burnIn || n < patience {
+		return false
+	}
+	d := history[n].Best - history[n-patience].Best
+	if d < 0 {
+		d = -d
+	}
+	return d < tol
+}
+
+// lastImproved is the generation in history where the best score last
+// changed.
+func lastImproved(history []gaGeneration) int {
+	gen := 0
+	for i := 1; i < len(history); i++ {
+		if history[i].Best != history[i-1].Best {
+			gen = history[i].Gen
+		}
+	}
+	return gen
+}
+
+// saveGAHistory writes history to path with writeGAHistory.
+func saveGAHistory(path string, history []gaGeneration) error {
+	f, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+	if err := writeGAHistory(f, path, history); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeGAHistory writes a -ga convergence history to w as CSV when path
+// ends in .csv, else as JSON.
+func writeGAHistory(w io.Writer, path string, history []gaGeneration) error {
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(history)
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"gen", "best", "top", "mean"})
+	for _, g := range history {
+		cw.Write([]string{
+			strconv.Itoa(g.Gen),
+			strconv.FormatFloat(g.Best, 'f', 4, 64),
+			strconv.FormatFloat(g.Top, 'f', 4, 64),
+			strconv.FormatFloat(g.Mean, 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
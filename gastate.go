@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// gaStateVersion is the -ga-save file format's version.
+const gaStateVersion = 1
+
+// gaState is a genetic search saved with -ga-save and picked up again with
+// -continue. Lineups are roster indices in batting order, so the state only
+// loads against the same players file, in the same order.
+type gaState struct {
+	Version  int      `json:"version"`
+	Roster   []string `json:"roster"`
+	Minimize bool     `json:"minimize"`
+	// Generations counts every generation run so far, across continues.
+	Generations int `json:"generations"`
+	// Seed starts the continued search's random stream. math/rand can't
+	// save its own state, so the last run draws this from it instead.
+	Seed       int64   `json:"seed"`
+	Population [][]int `json:"population"`
+	Best       []int   `json:"best"`
+	// BestMean is the best lineup's mean when saved, for reference; it's
+	// re-simulated on load, so a changed game setup can move it.
+	BestMean float64 `json:"best_mean"`
+}
+
+// rosterNames lists players' full names in file order.
+func rosterNames(players []baseball.Player) []string {
+	names := make([]string, len(players))
+	for i, p := range players {
+		names[i] = p.FirstName + " " + p.LastName
+	}
+	return names
+}
+
+// loadGAState reads a -ga-save file and checks it against the roster, the
+// lineup size and the search direction.
+func loadGAState(path string, players []baseball.Player, size int, minimize bool) (*gaState, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var st gaState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if st.Version != gaStateVersion {
+		return nil, fmt.Errorf("%s is version %d, want %d", path, st.Version, gaStateVersion)
+	}
+	names := rosterNames(players)
+	if len(st.Roster) != len(names) {
+		return nil, fmt.Errorf("%s was saved with %d players, the roster has %d", path, len(st.Roster), len(names))
+	}
+	for i, n := range names {
+		if st.Roster[i] != n {
+			return nil, fmt.Errorf("%s was saved with %s as player %d, the roster has %s", path, st.Roster[i], i+1, n)
+		}
+	}
+	if st.Minimize != minimize {
+		return nil, fmt.Errorf("%s was saved from a search with -worst=%t", path, st.Minimize)
+	}
+	if len(st.Population) < 2 {
+		return nil, fmt.Errorf("%s has %d lineups, need at least 2", path, len(st.Population))
+	}
+	for _, idx := range append(st.Population, st.Best) {
+		if err := checkIndices(idx, len(players), size); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return &st, nil
+}
+
+// checkIndices reports whether idx is size distinct roster indices below n.
+func checkIndices(idx []int, n, size int) error {
+	if len(idx) != size {
+		return fmt.Errorf("lineup %v has %d players, want %d", idx, len(idx), size)
+	}
+	seen := make(map[int]bool, len(idx))
+	for _, i := range idx {
+		if i < 0 || i >= n || seen[i] {
+			return fmt.Errorf("lineup %v has a bad or repeated player index %d", idx, i)
+		}
+		seen[i] = true
+	}
+	return nil
+}
+
+// saveGAState writes st to path as indented JSON.
+func saveGAState(path string, st gaState) error {
+	f, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(st); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
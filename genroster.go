@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// rateDist is a normal distribution of a rate stat for -gen-roster.
+type rateDist struct {
+	Mean, StdDev float64
+}
+
+// parseRateDist reads a -gen-obp or -gen-slug spec, "MEAN,STDDEV".
+func parseRateDist(spec string) (rateDist, error) {
+	var d rateDist
+	if _, err := fmt.Sscanf(spec, "%g,%g", &d.Mean, &d.StdDev); err != nil {
+		return d, fmt.Errorf("%q isn't MEAN,STDDEV", spec)
+	}
+	if d.Mean <= 0 || d.StdDev < 0 {
+		return d, fmt.Errorf("%q needs a positive mean and a non-negative stddev", spec)
+	}
+	return d, nil
+}
+
+// genRoster makes n synthetic players, Player01 up, with OBP and SLUG drawn
+// from obp and slug. AVG keeps the league's share of OBP, and draws are
+// clamped so every split is valid: AVG < OBP <= 1 and AVG <= SLUG <= 4 AVG.
+// Both splits get the same line, so handedness doesn't matter.
+func genRoster(n int, obp, slug rateDist, r *rand.Rand) []baseball.Player {
+	players := make([]baseball.Player, n)
+	for i := range players {
+		o := math.Min(math.Max(obp.Mean+obp.StdDev*r.NormFloat64(), 0.1), 0.7)
+		avg := o * baseball.LeagueAVG / baseball.LeagueOBP
+		sl := math.Min(math.Max(slug.Mean+slug.StdDev*r.NormFloat64(), avg), 4*avg)
+		s := baseball.Stats{AVG: round3(avg), OBP: round3(o), SLUG: round3(sl)}
+		players[i] = baseball.Player{
+			FirstName: "Gen",
+			LastName:  fmt.Sprintf("Player%02d", i+1),
+			LHP:       s,
+			RHP:       s,
+		}
+	}
+	return players
+}
+
+// round3 rounds x to three places, the way rate stats are printed.
+func round3(x float64) float64 {
+	return math.Round(x*1000) / 1000
+}
+
+// writeRoster writes players as an indented players file.
+func writeRoster(w io.Writer, players []baseball.Player) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(players)
+}
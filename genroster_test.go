@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestGenRosterLoads(t *testing.T) {
+	withInt(t, lineupSize, 9)
+	// A wide spread so the clamps get exercised.
+	roster := genRoster(15, rateDist{0.320, 0.150}, rateDist{0.410, 0.300}, rand.New(rand.NewSource(1)))
+	var buf bytes.Buffer
+	if err := writeRoster(&buf, roster); err != nil {
+		t.Fatal(err)
+	}
+	players, err := LoadPlayers(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 15 || players[14].LastName != "Player15" {
+		t.Errorf("loaded %d players, last %s", len(players), players[len(players)-1].LastName)
+	}
+	if err := checkSplits(players, false, true); err != nil {
+		t.Errorf("generated roster fails -strict checks: %v", err)
+	}
+
+	for _, spec := range []string{"0.320", "-0.3,0.03", "0.32,-1"} {
+		if _, err := parseRateDist(spec); err == nil {
+			t.Errorf("%q parsed", spec)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// gidpPoint is the best of the swept lineups at one GIDP rate.
+type gidpPoint struct {
+	Rate  float64  `json:"rate"`
+	ID    string   `json:"id"`
+	Order []string `json:"order"`
+	Mean  float64  `json:"mean"`
+	// TopMean is the search's top lineup's mean at this rate.
+	TopMean float64 `json:"top_mean"`
+}
+
+// gidpSweep is the -gidp-sweep report. Stable is true when the search's
+// top lineup is still the best at every rate.
+type gidpSweep struct {
+	Top     string      `json:"top"`
+	Lineups int         `json:"lineups"`
+	Points  []gidpPoint `json:"points"`
+	Stable  bool        `json:"stable"`
+}
+
+// parseGIDPRates reads a -gidp-sweep spec, comma-separated rates in [0, 1].
+func parseGIDPRates(spec string) ([]float64, error) {
+	var rates []float64
+	for _, f := range strings.Split(spec, ",") {
+		r, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil || r < 0 || r > 1 {
+			return nil, fmt.Errorf("%q isn't a GIDP rate between 0 and 1", f)
+		}
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
+
+// sweepGIDP replays results, the top of the search first, for games games
+// at each GIDP rate and reports the best of them at each. Every lineup and
+// rate uses the same seed, so differences come from the rate alone.
+func sweepGIDP(results []lineupResult, cfg baseball.GameConfig, games int, rates []float64, seed int64) gidpSweep {
+	sw := gidpSweep{Top: results[0].ID(), Lineups: len(results), Stable: true}
+	for _, rate := range rates {
+		c := cfg
+		c.GIDPRate = rate
+		means := make([]float64, len(results))
+		var wg sync.WaitGroup
+		for i, res := range results {
+			wg.Add(1)
+			go func(i int, res lineupResult) {
+				defer wg.Done()
+				means[i] = EvaluateLineup(res.lineup, c, games, seed).Mean
+			}(i, res)
+		}
+		wg.Wait()
+		best := 0
+		for i, m := range means {
+			if m > means[best] {
+				best = i
+			}
+		}
+		p := gidpPoint{Rate: rate, ID: results[best].ID(), Order: results[best].Order, Mean: means[best], TopMean: means[0]}
+		if p.ID != sw.Top {
+			sw.Stable = false
+		}
+		sw.Points = append(sw.Points, p)
+	}
+	return sw
+}
+
+// writeGIDPSweep prints sw, one line per rate.
+func writeGIDPSweep(w io.Writer, sw *gidpSweep) {
+	verdict := "the top lineup is best at every rate"
+	if !sw.Stable {
+		verdict = "the best lineup changes with the rate"
+	}
+	fmt.Fprintf(w, "GIDP sweep over the top %d lineups: %s\n", sw.Lineups, verdict)
+	for _, p := range sw.Points {
+		mark := ""
+		if p.ID != sw.Top {
+			mark = fmt.Sprintf("  (top lineup %.3f)", p.TopMean)
+		}
+		fmt.Fprintf(w, "  gidp=%.3f  ID=%s mean=%.3f%s  order=%v\n", p.Rate, p.ID, p.Mean, mark, p.Order)
+	}
+}
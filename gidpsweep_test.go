@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestSweepGIDP(t *testing.T) {
+	rates, err := parseGIDPRates("0, 0.05,0.1,0.15,0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	roster := testRoster(14)
+	strong := lineupResult{Hash: lineupHash(roster[:9]), lineup: roster[:9]}
+	weak := lineupResult{Hash: lineupHash(roster[5:]), lineup: roster[5:]}
+	cfg := baseball.DefaultGameConfig()
+
+	sw := sweepGIDP([]lineupResult{strong, weak}, cfg, 1000, rates, 1)
+	if len(sw.Points) != 5 || !sw.Stable {
+		t.Fatalf("sweep %+v, want five rates all won by the strong lineup", sw)
+	}
+	for i, p := range sw.Points {
+		if p.Rate != rates[i] || p.ID != strong.ID() || p.Mean != p.TopMean {
+			t.Errorf("rate %v: best %s mean %.3f, top %.3f", rates[i], p.ID, p.Mean, p.TopMean)
+		}
+	}
+	if first, last := sw.Points[0], sw.Points[4]; last.Mean >= first.Mean {
+		t.Errorf("%.3f runs at GIDP 0.2, %.3f at 0", last.Mean, first.Mean)
+	}
+
+	// Listed with the weak lineup on top, the sweep flags it as unstable.
+	if sw := sweepGIDP([]lineupResult{weak, strong}, cfg, 1000, rates, 1); sw.Stable {
+		t.Error("sweep with a beaten top lineup is stable")
+	}
+	if _, err := parseGIDPRates("0,1.5"); err == nil {
+		t.Error("a GIDP rate of 1.5 parsed")
+	}
+}
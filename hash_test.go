@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"testing"
+)
+
+func TestLineupHashIsFNVOfKey(t *testing.T) {
+	lineup := testRoster(9)
+	keys := make([]string, len(lineup))
+	for i, p := range lineup {
+		keys[i] = fmt.Sprintf("%d:%s,%s", i, p.LastName, p.FirstName)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(keys, "|")))
+	if got, want := lineupHash(lineup), h.Sum64(); got != want {
+		t.Errorf("lineupHash = %x, want FNV-1a of the key %x", got, want)
+	}
+	if n := testing.AllocsPerRun(100, func() { lineupHash(lineup) }); n != 0 {
+		t.Errorf("lineupHash allocates %v times", n)
+	}
+}
+
+func TestLineupHashNoCollisions(t *testing.T) {
+	// Every order of nine players, by Heap's algorithm.
+	lineup := testRoster(9)
+	seen := make(map[uint64]bool, 362880)
+	var permute func(k int)
+	permute = func(k int) {
+		if k == 1 {
+			h := lineupHash(lineup)
+			if seen[h] {
+				t.Fatalf("%v collides", lineup)
+			}
+			seen[h] = true
+			return
+		}
+		for i := 0; i < k; i++ {
+			permute(k - 1)
+			if k%2 == 0 {
+				lineup[i], lineup[k-1] = lineup[k-1], lineup[i]
+			} else {
+				lineup[0], lineup[k-1] = lineup[k-1], lineup[0]
+			}
+		}
+	}
+	permute(len(lineup))
+	if len(seen) != 362880 {
+		t.Errorf("hashed %d orders, want 362880", len(seen))
+	}
+}
+
+func BenchmarkLineupHash(b *testing.B) {
+	lineup := testRoster(9)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lineupHash(lineup)
+	}
+}
+
+// BenchmarkLineupHashKeyString is the old way, building the key as a string
+// and writing it to hash/fnv, for comparison.
+func BenchmarkLineupHashKeyString(b *testing.B) {
+	lineup := testRoster(9)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h := fnv.New64a()
+		var sb strings.Builder
+		for j, p := range lineup {
+			if j > 0 {
+				sb.WriteByte('|')
+			}
+			sb.WriteString(fmt.Sprintf("%d:%s,%s", j, p.LastName, p.FirstName))
+		}
+		h.Write([]byte(sb.String()))
+		_ = h.Sum64()
+	}
+}
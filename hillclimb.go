@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// swapStep is one hill-climbing move: swapping slots I and J (0-based)
+// took the lineup's mean from From to To.
+type swapStep struct {
+	I     int      `json:"i"`
+	J     int      `json:"j"`
+	From  float64  `json:"from"`
+	To    float64  `json:"to"`
+	Order []string `json:"order"`
+	// Wins is the swap's gain as wins over a season, with -runs-per-win.
+	Wins float64 `json:"wins,omitempty"`
+}
+
+// BestSwap plays every order one swap of two slots away from cur with
+// EvaluateLineup, over the same games and seed as cur was, and returns the
+// best of them and the swap that makes it. Orders are ranked as the search
+// ranks them: by score, the mean less any -cluster-penalty, and -tiebreak.
+// ok is false when no swap ranks above cur: cur is a local optimum. Orders
+// already in cache, which may be nil, aren't played again.
+func BestSwap(cur lineupResult, cfg baseball.GameConfig, games int, seed int64, cache *evalCache) (best lineupResult, step swapStep, ok bool) {
+	cur.Score = cur.Mean - clusterPenalty(cur.lineup, cfg)
+	n := len(cur.lineup)
+	type neighbor struct {
+		i, j int
+		res  lineupResult
+	}
+	var neighbors []neighbor
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			neighbors = append(neighbors, neighbor{i: i, j: j})
+		}
+	}
+	var wg sync.WaitGroup
+	for k := range neighbors {
+		wg.Add(1)
+		go func(nb *neighbor) {
+			defer wg.Done()
+			lineup := append([]baseball.Player(nil), cur.lineup...)
+			lineup[nb.i], lineup[nb.j] = lineup[nb.j], lineup[nb.i]
+			nb.res = cache.evaluate(lineup, cfg, games, seed)
+			nb.res.Score = nb.res.Mean - clusterPenalty(lineup, cfg)
+		}(&neighbors[k])
+	}
+	wg.Wait()
+	best = cur
+	for _, nb := range neighbors {
+		if ranksAbove(nb.res, best) {
+			best, step, ok = nb.res, swapStep{I: nb.i, J: nb.j, From: cur.Mean, To: nb.res.Mean, Order: nb.res.Order, Wins: seasonWins(nb.res.Mean - cur.Mean)}, true
+		}
+	}
+	return best, step, ok
+}
+
+// hillClimb takes BestSwap steps from start, an EvaluateLineup result over
+// games games from seed, until none improves, and returns the local optimum
+// and the steps taken. Each step ranks above the last, so the climb ends.
+// Every step revisits the orders one swap from the last, so cache spares
+// most of the simulating.
+func hillClimb(start lineupResult, cfg baseball.GameConfig, games int, seed int64, cache *evalCache) (lineupResult, []swapStep) {
+	cur := start
+	var steps []swapStep
+	for {
+		next, step, ok := BestSwap(cur, cfg, games, seed, cache)
+		if !ok {
+			return next, steps
+		}
+		cur = next
+		steps = append(steps, step)
+	}
+}
+
+// writeClimb prints the steps of a hill climb from start and where it ended.
+func writeClimb(w io.Writer, start, end lineupResult, steps []swapStep) {
+	fmt.Fprintf(w, "Hill climb from ID=%s mean=%.3f:\n", start.ID(), start.Mean)
+	for k, s := range steps {
+		fmt.Fprintf(w, "%2d) swap slots %d and %d: %.3f -> %.3f (%+.3f)%s  order=%v\n",
+			k+1, s.I+1, s.J+1, s.From, s.To, s.To-s.From, winsText(s.To-s.From), s.Order)
+	}
+	fmt.Fprintf(w, "Local optimum after %d swaps: ID=%s mean=%.3f (%+.3f)%s  order=%v\n",
+		len(steps), end.ID(), end.Mean, end.Mean-start.Mean, winsText(end.Mean-start.Mean), end.Order)
+}
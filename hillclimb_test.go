@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestHillClimbFromABadOrder(t *testing.T) {
+	// The worst hitter leads off and the best bats ninth.
+	players := testRoster(9)
+	for i, j := 0, len(players)-1; i < j; i, j = i+1, j-1 {
+		players[i], players[j] = players[j], players[i]
+	}
+	cfg := baseball.DefaultGameConfig()
+	const games, seed = 200, 1
+	cache := newEvalCache(1000)
+	start := EvaluateLineup(players, cfg, games, seed)
+	end, steps := hillClimb(start, cfg, games, seed, cache)
+
+	if len(steps) == 0 || end.Mean <= start.Mean {
+		t.Fatalf("climbed %d steps from %.3f to %.3f", len(steps), start.Mean, end.Mean)
+	}
+	from := start.Mean
+	for k, s := range steps {
+		if s.From != from || s.To <= s.From {
+			t.Errorf("step %d went from %.3f to %.3f after reaching %.3f", k+1, s.From, s.To, from)
+		}
+		from = s.To
+	}
+	if from != end.Mean {
+		t.Errorf("last step reached %.3f, climb ended at %.3f", from, end.Mean)
+	}
+	if _, step, ok := BestSwap(end, cfg, games, seed, cache); ok {
+		t.Errorf("swapping slots %d and %d still raises the local optimum from %.3f to %.3f", step.I+1, step.J+1, step.From, step.To)
+	}
+}
+
+func TestHillClimbRanksByScoreAndTiebreak(t *testing.T) {
+	withFloat(t, clusterRuns, 5)
+	withFloat(t, clusterOBP, 0.330)
+	// P5 and P6 are the low OBPs, back to back at the bottom.
+	players := testRoster(6)
+	cfg := baseball.DefaultGameConfig()
+	const games, seed = 100, 1
+	start := EvaluateLineup(players, cfg, games, seed)
+	end, steps := hillClimb(start, cfg, games, seed, newEvalCache(1000))
+	if len(steps) == 0 || clusterPenalty(end.lineup, cfg) != 0 {
+		t.Errorf("climbed %d steps to %v, which bats its low OBPs back to back", len(steps), end.Order)
+	}
+
+	// With every score in one band, the climb goes by the tiebreak alone.
+	withFloat(t, clusterRuns, 0)
+	tb, err := parseTiebreak("stddev", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := tiebreaker
+	tiebreaker = tb
+	t.Cleanup(func() { tiebreaker = old })
+	start = EvaluateLineup(players, cfg, games, seed)
+	end, _ = hillClimb(start, cfg, games, seed, nil)
+	if end.StdDev > start.StdDev {
+		t.Errorf("the stddev tiebreak climbed from stddev %.3f to %.3f", start.StdDev, end.StdDev)
+	}
+	if _, _, ok := BestSwap(end, cfg, games, seed, nil); ok {
+		t.Errorf("a swap still ranks above the local optimum %v", end.Order)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// historyEntry is one search's top lineup, as kept by a HistoryStore.
+type historyEntry struct {
+	Time          time.Time `json:"time"`
+	PlayersFile   string    `json:"players_file"`
+	PlayersSHA256 string    `json:"players_sha256,omitempty"`
+	ID            string    `json:"id"`
+	Order         []string  `json:"order"`
+	Mean          float64   `json:"mean"`
+	StdDev        float64   `json:"stddev"`
+	Games         int       `json:"games"`
+}
+
+// HistoryStore keeps the top lineup of each search across invocations.
+// Entries returns everything recorded, oldest first.
+type HistoryStore interface {
+	Append(historyEntry) error
+	Entries() ([]historyEntry, error)
+}
+
+// openHistory returns the store at path. The only backend is a JSON Lines
+// file, one entry per line, which is appended to and never rewritten.
+func openHistory(path string) HistoryStore {
+	return jsonLinesHistory{path: path}
+}
+
+type jsonLinesHistory struct {
+	path string
+}
+
+func (h jsonLinesHistory) Append(e historyEntry) error {
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(e); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (h jsonLinesHistory) Entries() ([]historyEntry, error) {
+	f, err := os.Open(h.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []historyEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e historyEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", h.path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	// Appends from overlapping runs can land out of order.
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, nil
+}
+
+// newHistoryEntry records res as the top lineup of a search of playersFile.
+func newHistoryEntry(res lineupResult, playersFile string, now time.Time) historyEntry {
+	return historyEntry{
+		Time:          now,
+		PlayersFile:   playersFile,
+		PlayersSHA256: fileSHA256(playersFile),
+		ID:            res.ID(),
+		Order:         res.Order,
+		Mean:          res.Mean,
+		StdDev:        res.StdDev,
+		Games:         res.Games,
+	}
+}
+
+// writeTrend prints entries oldest first with each top mean's change from
+// the run before.
+func writeTrend(w io.Writer, entries []historyEntry) {
+	fmt.Fprintf(w, "%-20s %-8s %8s %8s  %-12s %s\n", "time", "id", "mean", "change", "players sha", "order")
+	for i, e := range entries {
+		change := ""
+		if i > 0 {
+			change = fmt.Sprintf("%+.3f", e.Mean-entries[i-1].Mean)
+		}
+		sha := e.PlayersSHA256
+		if len(sha) > 12 {
+			sha = sha[:12]
+		}
+		fmt.Fprintf(w, "%-20s %-8s %8.3f %8s  %-12s %v\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.ID, e.Mean, change, sha, e.Order)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryAppendsInOrder(t *testing.T) {
+	h := openHistory(filepath.Join(t.TempDir(), "history.jsonl"))
+	first := time.Date(2024, 4, 1, 19, 5, 0, 0, time.UTC)
+	// The later run lands first, as overlapping runs can.
+	for _, e := range []historyEntry{
+		{Time: first.Add(24 * time.Hour), ID: "bbbbbb", Order: []string{"B"}, Mean: 4.6},
+		{Time: first, ID: "aaaaaa", Order: []string{"A"}, Mean: 4.5},
+	} {
+		if err := h.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := h.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != "aaaaaa" || entries[1].ID != "bbbbbb" || !entries[0].Time.Equal(first) {
+		t.Errorf("entries %+v, want aaaaaa then bbbbbb", entries)
+	}
+}
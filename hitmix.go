@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// hitMixReport is the top lineup's realized single/double/triple/home-run
+// mix next to what the model computes for the hitters who got the hits, so
+// a user tuning the hit-type model can see whether its clamps move the
+// realized mix away from their targets.
+type hitMixReport struct {
+	Games int `json:"games"`
+	Hits  int `json:"hits"`
+	// Observed and Model are shares of hits, single through home run.
+	// Model averages baseball.HitMix over the hits, each with its batter's
+	// split as given (against a fixed pitcher hand, or both averaged), so
+	// game context such as a starter or -run-env shows up as a gap.
+	Observed [4]float64 `json:"observed"`
+	Model    [4]float64 `json:"model"`
+	// BasesPerHit is the realized total bases per hit and InputBasesPerHit
+	// the splits' SLUG / AVG over the same hits.
+	BasesPerHit      float64 `json:"bases_per_hit"`
+	InputBasesPerHit float64 `json:"input_bases_per_hit"`
+}
+
+// measureHitMix replays lineup for games seeded games and tallies its hits
+// by type from the play-by-play.
+func measureHitMix(lineup []baseball.Player, cfg baseball.GameConfig, games int, seed int64) hitMixReport {
+	rep := hitMixReport{Games: games}
+	var bases, inputBases float64
+	hands := pitcherHands(cfg)
+	cfg.Trace = func(p baseball.Play) {
+		var kind int
+		switch p.Outcome {
+		case baseball.HIT_SINGLE:
+			kind = 0
+		case baseball.HIT_DOUBLE:
+			kind = 1
+		case baseball.HIT_TRIPLE:
+			kind = 2
+		case baseball.HIT_HOMERUN:
+			kind = 3
+		default:
+			return
+		}
+		rep.Hits++
+		rep.Observed[kind]++
+		bases += float64(kind + 1)
+		for _, hand := range hands {
+			s := p.Batter.SituationalSplit(hand, p.Before.SecondBase != nil || p.Before.ThirdBase != nil)
+			mix := baseball.HitMix(s, p.Batter.Speed, cfg)
+			for i := range mix {
+				rep.Model[i] += mix[i] / float64(len(hands))
+			}
+			if s.AVG > 0 {
+				inputBases += s.SLUG / s.AVG / float64(len(hands))
+			}
+		}
+	}
+	r := rand.New(rand.NewSource(seed))
+	for g := 0; g < games; g++ {
+		baseball.SimulateGame(lineup, cfg, r)
+	}
+	if rep.Hits > 0 {
+		n := float64(rep.Hits)
+		for i := range rep.Observed {
+			rep.Observed[i] /= n
+			rep.Model[i] /= n
+		}
+		rep.BasesPerHit = bases / n
+		rep.InputBasesPerHit = inputBases / n
+	}
+	return rep
+}
+
+// pitcherHands lists the hands the model mix is averaged over: cfg's fixed
+// pitcher hand, or both.
+func pitcherHands(cfg baseball.GameConfig) []string {
+	if cfg.PitcherHand == "left" || cfg.PitcherHand == "right" {
+		return []string{cfg.PitcherHand}
+	}
+	return []string{"left", "right"}
+}
+
+func writeHitMix(w io.Writer, h hitMixReport) {
+	fmt.Fprintf(w, "Top lineup's hit mix over %d games (%d hits):\n", h.Games, h.Hits)
+	fmt.Fprintf(w, "%9s %7s %7s %7s %7s %9s\n", "", "1B", "2B", "3B", "HR", "TB/hit")
+	fmt.Fprintf(w, "%9s %6.1f%% %6.1f%% %6.1f%% %6.1f%% %9.3f\n", "observed", 100*h.Observed[0], 100*h.Observed[1], 100*h.Observed[2], 100*h.Observed[3], h.BasesPerHit)
+	fmt.Fprintf(w, "%9s %6.1f%% %6.1f%% %6.1f%% %6.1f%% %9.3f\n", "model", 100*h.Model[0], 100*h.Model[1], 100*h.Model[2], 100*h.Model[3], h.InputBasesPerHit)
+}
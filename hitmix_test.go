@@ -0,0 +1,28 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestHitMixMatchesModel(t *testing.T) {
+	cfg := baseball.DefaultGameConfig()
+	h := measureHitMix(nineOf(testPlayer("Avg", 0.340, 0.450)), cfg, 2000, 1)
+	if h.Hits < 10000 {
+		t.Fatalf("only %d hits in 2000 games", h.Hits)
+	}
+	for i := range h.Observed {
+		if math.Abs(h.Observed[i]-h.Model[i]) > 0.01 {
+			t.Errorf("hit type %d: observed %.4f of hits, model %.4f", i, h.Observed[i], h.Model[i])
+		}
+	}
+	model := 0.0
+	for i, share := range h.Model {
+		model += float64(i+1) * share
+	}
+	if math.Abs(h.BasesPerHit-model) > 0.02 {
+		t.Errorf("%.3f bases per hit, the model's mix gives %.3f", h.BasesPerHit, model)
+	}
+}
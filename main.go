@@ -1,15 +1,21 @@
 package main
 
 import (
-	"container/heap"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"hash/fnv"
-	"io/ioutil"
+	"io"
+	"io/fs"
 	"log"
+	"math"
 	"math/rand"
+	"os"
 	"runtime"
+	"runtime/pprof"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -18,11 +24,47 @@ import (
 	baseball "github.com/genghisjahn/battinglineup/batting"
 )
 
-// Agg holds aggregate stats per unique lineup key.
+// Agg holds aggregate stats per unique lineup key. The counters saturate at
+// math.MaxInt64 rather than wrapping, which no realistic run approaches:
+// at 100 runs a game a lineup would need 9e16 games. A saturated Agg's mean
+// is only approximate. (Game's per-game counters are plain ints, bounded by
+// one game's plate appearances.)
 type Agg struct {
 	Games int64
 	Runs  int64
 	Hits  int64
+	// PAs and Outs let rates be rebuilt from the aggregate: PAs-Outs is
+	// the times the lineup's batters reached or were put out on the bases.
+	PAs  int64
+	Outs int64
+	// Walks counts walks and hit-by-pitches, so PAs-Walks is at-bats.
+	Walks int64
+}
+
+// add folds games, runs, hits, plate appearances, outs and walks into a,
+// safe for concurrent use.
+func (a *Agg) add(games, runs, hits, pas, outs, walks int64) {
+	addSaturating(&a.Games, games)
+	addSaturating(&a.Runs, runs)
+	addSaturating(&a.Hits, hits)
+	addSaturating(&a.PAs, pas)
+	addSaturating(&a.Outs, outs)
+	addSaturating(&a.Walks, walks)
+}
+
+// addSaturating atomically adds a non-negative d to *p, stopping at
+// math.MaxInt64 instead of wrapping negative.
+func addSaturating(p *int64, d int64) {
+	for {
+		old := atomic.LoadInt64(p)
+		next := old + d
+		if next < old {
+			next = math.MaxInt64
+		}
+		if atomic.CompareAndSwapInt64(p, old, next) {
+			return
+		}
+	}
 }
 
 // lineupStats maps lineup hash -> aggregates. Safe for concurrent use.
@@ -30,16 +72,123 @@ var lineupStats sync.Map
 
 // lineupResult holds summary for a single ordered lineup.
 type lineupResult struct {
-	Mean  float64
-	Order []string
-	Hash  uint64
+	// Score is the ranking objective: mean runs, less any -cluster-penalty,
+	// or win probability in -opponent mode.
+	Score float64  `json:"score"`
+	Mean  float64  `json:"mean"`
+	Order []string `json:"order"`
+	Hash  uint64   `json:"hash"`
+
+	Games  int     `json:"games"`
+	StdDev float64 `json:"stddev"`
+	// RunsPerPA is total runs over total plate appearances, separating the
+	// scoring rate from the extra trips a good lineup earns.
+	RunsPerPA float64 `json:"runs_per_pa"`
+
+	// Split-specific means, set in -platoon mode.
+	LHPMean float64 `json:"lhp_mean,omitempty"`
+	RHPMean float64 `json:"rhp_mean,omitempty"`
+
+	// Head-to-head results, set in -opponent mode. Ties are games called
+	// level at -max-extra; the rest of the games were lost.
+	Wins    int     `json:"wins,omitempty"`
+	Ties    int     `json:"ties,omitempty"`
+	WinPct  float64 `json:"win_pct,omitempty"`
+	RunDiff float64 `json:"run_diff,omitempty"`
+
+	// Players is the per-slot breakdown from a fresh replay, filled in for
+	// the top lineup in JSON output, and NoRBIRuns the replay's runs per
+	// game that no slot drove in.
+	Players   []slotLine `json:"players,omitempty"`
+	NoRBIRuns float64    `json:"no_rbi_runs,omitempty"`
+
+	// Range is the floor and ceiling of the lineup's games, set with
+	// -floor-ceiling.
+	Range *runRange `json:"range,omitempty"`
+
+	// Center is the median and mode of the lineup's games, set with
+	// -median-mode, to read against Mean.
+	Center *runCenter `json:"center,omitempty"`
+
+	// TimedOut is set when -max-duration-per-lineup cut the lineup's games
+	// short; its stats cover only the games played.
+	TimedOut bool `json:"timed_out,omitempty"`
+
+	// Tiebreak is the -tiebreak value, higher better, that decides between
+	// lineups with near-equal scores.
+	Tiebreak float64 `json:"tiebreak,omitempty"`
+
+	lineup []baseball.Player
+	tally  runTally
+	hist   runsHistogram // kept only when keepHistogram
+	// runs and pa are the totals behind RunsPerPA.
+	runs, pa int64
+}
+
+// ID returns the short hex identifier printed alongside each lineup.
+func (r lineupResult) ID() string {
+	return fmt.Sprintf("%x", r.Hash)[:6]
+}
+
+// label is the ID as text output shows it, followed by the mnemonic name
+// under -mnemonic.
+func (r lineupResult) label() string {
+	if *mnemonics {
+		return r.ID() + " (" + mnemonic(r.Hash) + ")"
+	}
+	return r.ID()
+}
+
+// MarshalJSON adds the short ID, and the mnemonic name under -mnemonic, to
+// the encoded result.
+func (r lineupResult) MarshalJSON() ([]byte, error) {
+	type plain lineupResult
+	var name string
+	if *mnemonics {
+		name = mnemonic(r.Hash)
+	}
+	return json.Marshal(struct {
+		ID   string `json:"id"`
+		Name string `json:"name,omitempty"`
+		plain
+	}{r.ID(), name, plain(r)})
+}
+
+// runRange is a lineup's floor and ceiling: the 10th and 90th percentile
+// runs of its games.
+type runRange struct {
+	Floor   int `json:"p10"`
+	Ceiling int `json:"p90"`
+}
+
+// runCenter is a lineup's median runs and its mode, the most frequent
+// single-game run total. Run distributions are discrete and skewed, so the
+// two often differ from the mean and from each other.
+type runCenter struct {
+	Median float64 `json:"median"`
+	Mode   int     `json:"mode"`
+}
+
+// ranksAbove reports whether a ranks ahead of b: a higher Score, or on an
+// exact tie the lower Hash. Every heap, threshold and sort over results uses
+// it, so which of several tied lineups makes the top or bottom K doesn't
+// depend on the order workers finish in. With -tiebreak, scores in the same
+// band of its epsilon are decided by the tiebreak value first; see tiebreak.
+func ranksAbove(a, b lineupResult) bool {
+	if above, ok := tiebreaker.order(a, b); ok {
+		return above
+	}
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Hash < b.Hash
 }
 
-// min-heap by Median
+// resultHeap is a min-heap by rank, so its root is the weakest kept result.
 type resultHeap []lineupResult
 
 func (h resultHeap) Len() int            { return len(h) }
-func (h resultHeap) Less(i, j int) bool  { return h[i].Mean < h[j].Mean }
+func (h resultHeap) Less(i, j int) bool  { return ranksAbove(h[j], h[i]) }
 func (h resultHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
 func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.(lineupResult)) }
 func (h *resultHeap) Pop() interface{} {
@@ -50,20 +199,17 @@ func (h *resultHeap) Pop() interface{} {
 	return x
 }
 
-var (
-	topK    = 256
-	hmu     sync.Mutex
-	topHeap resultHeap
-)
+var topK = 256
 
-const bottomK = 10
+// bottomK is how many of the lowest-scoring lineups are kept, set by -bottom.
+var bottomK = 10
 
-var bmu sync.Mutex
+const consistentK = 10
 
 type maxResultHeap []lineupResult
 
 func (h maxResultHeap) Len() int            { return len(h) }
-func (h maxResultHeap) Less(i, j int) bool  { return h[i].Mean > h[j].Mean } // max-heap by Mean
+func (h maxResultHeap) Less(i, j int) bool  { return ranksAbove(h[i], h[j]) } // max-heap by rank
 func (h maxResultHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
 func (h *maxResultHeap) Push(x interface{}) { *h = append(*h, x.(lineupResult)) }
 func (h *maxResultHeap) Pop() interface{} {
@@ -74,20 +220,371 @@ func (h *maxResultHeap) Pop() interface{} {
 	return x
 }
 
-var bottomHeap maxResultHeap
+// stdDevHeap is a max-heap by StdDev, so its root is the least consistent
+// kept result; ties go to the lower mean, then by hash.
+type stdDevHeap []lineupResult
+
+func (h stdDevHeap) Len() int { return len(h) }
+func (h stdDevHeap) Less(i, j int) bool {
+	if h[i].StdDev != h[j].StdDev {
+		return h[i].StdDev > h[j].StdDev
+	}
+	return ranksAbove(h[j], h[i])
+}
+func (h stdDevHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *stdDevHeap) Push(x interface{}) { *h = append(*h, x.(lineupResult)) }
+func (h *stdDevHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+var (
+	lineupSize     = flag.Int("lineup-size", 9, "batters in a lineup, at least 6, e.g. 10 for slow-pitch softball")
+	playersPath    = flag.String("players", "player_files/phillies.json", "roster file to optimize")
+	playersDir     = flag.String("players-dir", "", "optimize every *.json roster in this directory instead of -players and rank the teams")
+	roundRobinN    = flag.Int("round-robin", 0, "with -players-dir, play every pair of teams' best lineups against each other this many games, alternating home, and print standings instead of the ranking")
+	dirJobs        = flag.Int("dir-jobs", 2, "rosters searched at once in -players-dir mode")
+	games          = flag.Int("games", 200, "games simulated per lineup")
+	outFormat      = flag.String("format", "text", `result format: "text", "json", "csv", "markdown" tables of the top and bottom lineups, "card" for a printable lineup card of the top lineup, or "parquet" for every searched lineup's hash, order, games, runs, hits, mean and stddev as a Parquet table (builds with -tags parquet)`)
+	outPath        = flag.String("out", "", "write results to this file instead of stdout")
+	streamMode     = flag.Bool("stream", false, "write each new best lineup to stdout as a JSON line while the search runs")
+	streamInterval = flag.Duration("stream-interval", 250*time.Millisecond, "minimum time between streamed lineups")
+	sinkSpecs      sinkFlags
+	dumpAll        = flag.String("dump-all", "", "also write every simulated lineup as JSON Lines to stdout, file:PATH or an http(s) URL")
+	outsPerInning  = flag.Int("outs", 3, "outs per inning")
+	imputeStats    = flag.Bool("impute", false, "estimate a missing avg, obp or slug from the other two instead of failing")
+	strictData     = flag.Bool("strict", false, "treat suspicious player data, such as OBP equal to AVG, as an error, and check every simulated base state")
+	handScript     = flag.String("pitcher-hands", "", "comma-separated pitcher hand (L or R) for each inning, the last holding for later innings, e.g. R,R,R,R,R,R,L,R,L")
+	relievers      = flag.Int("relievers", 1, "most pitching changes per game")
+	gidpRate       = flag.Float64("gidp", 0.11, "chance an out with a runner on first becomes a double play")
+	noGIDP         = flag.Bool("no-gidp", false, "disable double plays (same as -gidp 0)")
+	hbpShare       = flag.Float64("hbp-share", 0.09, "fraction of non-hit times on base that are hit-by-pitches")
+	runCap         = flag.Int("run-cap", 0, "debugging check: log a warning, with the plays, for any simulated inning scoring more than this many runs (0 disables)")
+	extraOnThrow   = flag.Float64("extra-base-on-throw", 0, "chance the trailing runner takes an extra base on the throw home when a single or double scores a run (0 disables)")
+	scoreFromFirst = flag.Float64("score-from-first", 0, "chance a faster-than-average runner alone on first scores on a single, scaled up with speed and aggression (0 disables)")
+	outStretching  = flag.Float64("out-stretching", 0, "chance a double is a single with the batter thrown out trying for second, the runners advancing as on a double (0 disables)")
+	secondOnDouble = flag.Float64("score-from-second-on-double", 1, "chance an unforced runner on second scores on a double rather than being held at third")
+	scoreFromThird = flag.Float64("score-from-third", 1, "chance an unforced runner on third scores on a single")
+	droppedThird   = flag.Float64("dropped-third-strike", 0, "chance a strikeout with first base open or two outs is a dropped third strike on which the batter reaches first (needs k_rate in the players file; 0 disables)")
+	secondToThird  = flag.Float64("second-to-third-on-out", 0, "chance an out moves a runner alone on second to third, as on a ground ball to the right side, with fewer than two outs")
+	productiveOut  = flag.Float64("productive-out", 0, "chance an out with a runner in scoring position moves the lead runner up a base")
+	extraRunner    = flag.Int("extra-runner", 0, "base (1-3) of the runner placed to start each extra half-inning with -opponent; 0 disables it")
+	extraHalves    = flag.String("extra-runner-halves", "both", "which extra half-innings get -extra-runner: both, top or bottom")
+	stealAttempt   = flag.Float64("steal-attempt", 0, "chance a league-average runner on first tries to steal second before each plate appearance; 0 disables steals")
+	stealSuccess   = flag.Float64("steal-success", baseball.DefaultSteals.SuccessRate, "a league-average runner's chance of stealing second safely")
+	stealNet       = flag.Bool("steal-value", false, "replay the top lineup with and without steals and report the running game's net runs")
+	ibb            = flag.Bool("ibb", false, "intentionally walk .500 sluggers from the 8th inning of close games with first base open and a runner in scoring position")
+	statScale      = flag.Float64("stat-scale", 1, "multiply every batter's AVG, OBP and SLUG (on top of -model's stat_scale) to calibrate the scoring level")
+	modelPath      = flag.String("model", "", "JSON file of engine calibration constants overriding the defaults (see baseball.Model)")
+	evaluatePath   = flag.String("evaluate", "", "instead of searching, play the lineup in this JSON file (- for stdin) in the order given and report its stats")
+	calibrateRPG   = flag.Float64("calibrate", 0, "instead of searching, report a league-average lineup's runs per game and the -stat-scale that scores this many")
+	infieldIn      = flag.Bool("infield-in", false, "play the infield in from the 7th inning of close games with a runner on third")
+	homeFactor     = flag.Float64("home-factor", 1, "multiplier on the home team's AVG/OBP/SLUG in -opponent games")
+	parkDouble     = flag.Float64("park-2b", 1, "park factor for doubles")
+	parkTriple     = flag.Float64("park-3b", 1, "park factor for triples")
+	parkHR         = flag.Float64("park-hr", 1, "park factor for home runs")
+	platoon        = flag.Bool("platoon", false, "simulate every lineup against both LHP and RHP and rank by the blended mean")
+	lhpShare       = flag.Float64("lhp-share", 0.3, "share of games against left-handed pitching in -platoon mode")
+	rankSets       = flag.String("rank-sets", "", `also rank distinct player sets by their "best" or "avg" ordering mean`)
+	lineupIndex    = flag.Int64("lineup-index", -1, "instead of searching, play the lineup at this 0-based index of the exhaustive enumeration, as -explain reports it, and report its stats")
+	sampleSize     = flag.Int("sample", 0, "instead of every lineup, simulate this many distinct lineups drawn at random without replacement from the search space, in an order fixed by -seed (0 searches them all)")
+	maxLineups     = flag.Float64("max-lineups", 1e8, "refuse exhaustive searches larger than this many lineups unless -force is set")
+	force          = flag.Bool("force", false, "run the exhaustive search even when it exceeds -max-lineups")
+	minGamesCI     = flag.Float64("min-games-ci", 0, "after the search, re-simulate the top lineups until each mean's 95% CI half-width is at most this many runs (0 disables)")
+	ciStep         = flag.Int("ci-step", 200, "games added per batch in -min-games-ci mode")
+	bottomCount    = flag.Int("bottom", 10, "how many of the lowest-scoring lineups to report")
+	bottomGames    = flag.Int("bottom-games", 0, "after the search, re-simulate the bottom lineups over this many games each and re-rank them (0 keeps the search's games)")
+	ciMaxGames     = flag.Int("ci-max-games", 20000, "game cap per lineup in -min-games-ci mode")
+	minMean        = flag.Float64("min-mean", 0, "list only the top lineups averaging at least this many runs, with a count of how many qualified (0 lists all)")
+	topUnique      = flag.Int("top-unique", 0, "hide top lineups within this many adjacent swaps of a better one already listed (0 shows all)")
+	positions      = flag.Bool("positions", false, "only consider nine-player sets that can field a legal defensive alignment")
+	inFlight       = flag.Int("in-flight", 1024, "most lineups generated but not yet simulated; bounds the search's memory")
+	quiet          = flag.Bool("quiet", false, "print only results and warnings: no progress reports or status lines")
+	progressEvery  = flag.Duration("progress", 5*time.Second, "interval between progress reports on stderr (0 disables)")
+	opponentPath   = flag.String("opponent", "", "rank lineups by win probability against the first -lineup-size players of this file, in order")
+	firstInning    = flag.String("first-inning", "", "start each game's first inning with outs already recorded and runners on, e.g. outs=1,bases=2 (bases as in -state); the runners are the batters before the top of the order")
+	gameState      = flag.String("state", "", "with -opponent, rank lineups by win probability from a game state, e.g. inning=8,half=bottom,outs=1,bases=1,score=3-5 (our score first); the lineup's first batter is due up")
+	seasonPath     = flag.String("season", "", "with -opponent, a JSON array of the opposing starter for each game, cycled over -games")
+	repGame        = flag.Bool("rep-game", false, "replay the top lineup and print the box score of its median-scoring game")
+	maxGame        = flag.Bool("max-game", false, "replay the top lineup and print the box score of its highest-scoring game, with the seed that reproduces it")
+	gameSeed       = flag.Int64("game-seed", 0, "replay the top lineup's game from this seed, as printed by -rep-game or -max-game, and print its box score")
+	dumpFields     = flag.Bool("dump-field-states", false, "debug baserunning: play one game of the top or -evaluate lineup, from -game-seed if set, and print the bases before and after every plate appearance")
+	traceGame      = flag.Bool("trace", false, "with -rep-game, -max-game or -game-seed, also print the play-by-play")
+	explainDiff    = flag.String("explain-diff", "", "instead of the report, attribute the run difference between two lineups, given as ID,ID, to their batting slots")
+	explainID      = flag.String("explain", "", "instead of the report, print a breakdown of the lineup with this ID or hash and its rank in the search")
+	gaGenerations  = flag.Int("ga", 0, "instead of the exhaustive search, evolve lineups with a genetic search for this many generations")
+	gaPopulation   = flag.Int("ga-pop", 50, "lineups per generation with -ga")
+	gaPatience     = flag.Int("ga-patience", 0, "with -ga, stop early once the best score has moved less than -ga-tolerance over this many generations (0 runs them all)")
+	gaTolerance    = flag.Float64("ga-tolerance", 0.01, "runs of improvement over -ga-patience generations below which -ga stops early")
+	gaBurnIn       = flag.Int("ga-burn-in", 0, "generations -ga always runs before -ga-patience can stop it")
+	gaHistory      = flag.String("ga-history", "", "with -ga, write the best score by generation to this file, as CSV when it ends in .csv and JSON otherwise")
+	gaSave         = flag.String("ga-save", "", "with -ga, write the final population and best lineup to this file for -continue")
+	gaContinue     = flag.String("continue", "", "with -ga, pick up the search saved by -ga-save in this file and run -ga more generations (its population size replaces -ga-pop)")
+	worst          = flag.Bool("worst", false, "report the lowest-scoring lineup under the same constraints instead of the top lineups")
+	fixSpec        = flag.String("fix", "", "slot:last-name of a player who bats in that 1-based slot in every lineup, e.g. 9:Nola for an NL pitcher, while the other slots are searched")
+	leadoffOBP     = flag.Bool("leadoff-obp", false, "fix the leadoff slot to the highest-OBP player (LHP/RHP weighted by -lhp-share) and order the other eight")
+	baseline       = flag.String("baseline", "file", `naive order to compare the top lineup against: "file" (first players as listed), "obp" (best players by OBP) or "none"`)
+	versusPath     = flag.String("vs", "", "JSON file of an alternative order to compare the top lineup with, reporting how often a random game of the top lineup outscores one of it")
+	finalists      = flag.Int("finalists", 0, "re-score this many top lineups as the average of their means under -finalist-seeds independent seeds, with the cross-seed standard error")
+	finalistSeeds  = flag.Int("finalist-seeds", 5, "independent seeds each -finalists lineup is played under")
+	gidpSweepSpec  = flag.String("gidp-sweep", "", "comma-separated GIDP rates, e.g. 0,0.05,0.1,0.15,0.2, at which to replay the top -gidp-sweep-top lineups and report the best at each and whether the top lineup holds")
+	gidpSweepTop   = flag.Int("gidp-sweep-top", 10, "top lineups replayed at each -gidp-sweep rate")
+	seedChecks     = flag.Int("seed-check", 0, "replay the top two lineups under this many independent seeds and report whether the lead of #1 over #2 exceeds the Monte Carlo error of their difference")
+	slotSplits     = flag.Bool("slot-splits", false, "also list the top lineup slot by slot with each batter's AVG/OBP/SLUG against each pitcher hand simulated")
+	slotFreq       = flag.Bool("slot-matrix", false, "also report how often each player bats in each slot across the top lineups")
+	doubleSwitches = flag.String("double-switch", "", "comma-separated inning:out-slot:last-name:bat-slot double switches made before an inning: the player replaces the batter in out-slot and bats in bat-slot, whose batter moves to out-slot, e.g. 7:9:Stott:4")
+	backupSpec     = flag.String("backup", "", "comma-separated starter:backup last names; the backup bats for a starter who sits out a game by their availability (without one they're an automatic out)")
+	pinchRunSpec   = flag.String("pinch-run", "", "comma-separated last names of fast bench players from the players file who run for a slower runner reaching base from the 8th inning of close games, and a players-used report for the top lineup")
+	pinchRunSpeed  = flag.Float64("pinch-run-speed", baseball.DefaultPinchRunning.MaxSpeed, "sprint speed (ft/s) below which -pinch-run replaces a runner")
+	pinchHitSpec   = flag.String("pinch-hit", "", "comma-separated inning:slot:last-name pinch hits from the players file, e.g. 7:9:Stott, and a players-used report for the top lineup")
+	recentWeight   = flag.Float64("recent-weight", 0, "blend this share of each player's recent_lhp/recent_rhp splits into their season splits (0 = season only)")
+	mnemonics      = flag.Bool("mnemonic", false, "show a memorable adjective-noun name derived from each lineup's hash next to its ID")
+	clusterRuns    = flag.Float64("cluster-penalty", 0, "runs taken off a lineup's score for each adjacent pair of hitters below -cluster-obp (0 disables)")
+	clusterOBP     = flag.Float64("cluster-obp", 0.300, "OBP below which -cluster-penalty counts a hitter as low")
+	runEnv         = flag.Float64("run-env", 1, "run environment dial: >1 for a slugfest, <1 for a pitcher's duel; scales on-base and extra-base rates")
+	wildPitch      = flag.Float64("wild-pitch", 0, "chance of a wild pitch before each plate appearance with a runner on (0 disables)")
+	wpThird        = flag.Float64("wp-score-third", 1, "chance the runner on third scores on a wild pitch rather than holding")
+	re24Format     = flag.String("re24", "", `instead of searching, play the first -lineup-size players in file order and write their base-out run-expectancy matrix as "text", "dot" (Graphviz) or "html"`)
+	slotWeightSpec = flag.String("slot-weights", "", "with -prefilter, comma-separated per-slot weights on the heuristic, one per -lineup-size slot, e.g. 1,1,1.2,1.2,1.2,1,1,1,1 to value the 3-4-5 slots more")
+	prefilter      = flag.Float64("prefilter", 1, "simulate only about this fraction of lineups, those with the best OBP-by-slot heuristic (1 simulates all)")
+	oppDefense     = flag.Float64("defense", 1, "multiplier on the chance a ball in play falls for a hit, for the defense the lineup faces: below 1 is better than average (0.95 takes away 5% of those hits), above 1 worse")
+	shrinkPA       = flag.Float64("shrink-pa", 0, "regress each split with a pa count toward -shrink-to, keeping pa/(pa+this) of its own rates (0 disables)")
+	shrinkTo       = flag.String("shrink-to", "", "AVG,OBP,SLUG that -shrink-pa regresses toward (default league average)")
+	minWalkRate    = flag.Float64("min-walk-rate", 0, "raise every split's OBP to at least AVG plus this, so thin data can't stop a hitter from walking")
+	historyPath    = flag.String("history", "", "append the top lineup, with the time and the players file's hash, to this JSON Lines history file")
+	historyTrend   = flag.String("history-trend", "", "instead of searching, print the top lineups recorded in this -history file, oldest first, with each mean's change")
+	genRosterN     = flag.Int("gen-roster", 0, "instead of searching, write a synthetic players file of this many players, drawn with -seed")
+	genOBP         = flag.String("gen-obp", "0.320,0.030", "MEAN,STDDEV of OBP for -gen-roster")
+	genSLUG        = flag.String("gen-slug", "0.410,0.060", "MEAN,STDDEV of SLUG for -gen-roster")
+	evalCacheSize  = flag.Int("eval-cache", 100000, "most lineup results -ga and -hill-climb keep for re-use instead of simulating the order again, least recently used dropped first (0 disables)")
+	hillClimbMode  = flag.Bool("hill-climb", false, "with -evaluate, repeatedly make the best swap of two slots until none raises the mean, printing each step and the local optimum")
+	lineupLimit    = flag.Duration("max-duration-per-lineup", 0, "with -evaluate or -compare-orders, stop a lineup's games after this long and report the ones played as timed out (0 disables)")
+	candidatesPath = flag.String("candidates", "", "instead of searching, play only the orders in this JSON file, an array of arrays of last names from -players, over the same games and rank them like -compare-orders")
+	compareMode    = flag.Bool("compare-orders", false, "instead of searching, play the lineups in the JSON files given as arguments (- for stdin) over the same games and rank them with significance against the best")
+	onlyPlayers    = flag.String("only", "", "comma-separated last names of exactly -lineup-size players to use, searching only their batting orders")
+	tiebreakSpec   = flag.String("tiebreak", "", `decide between lineups whose scores fall in the same -tiebreak-eps band by "floor" (higher 10th-percentile runs), "stddev" (lower) or "atleast:N" (more games of N+ runs)`)
+	tiebreakEps    = flag.Float64("tiebreak-eps", 0.01, "width of the score bands whose lineups -tiebreak decides between")
+	floorCeiling   = flag.Bool("floor-ceiling", false, "report each listed lineup's floor and ceiling: the 10th and 90th percentile runs of its games")
+	medianMode     = flag.Bool("median-mode", false, "report each listed lineup's median and mode (most frequent single-game runs) alongside its mean")
+	showTiers      = flag.Bool("tiers", false, "group the top lineups into tiers whose means aren't significantly different at 95%")
+	hitMix         = flag.Bool("hit-mix", false, "replay the top lineup and report its realized single/double/triple/home-run mix against the hit-type model's")
+	streaks        = flag.Bool("streaks", false, "replay the top lineup and report the distribution of its scoring streaks: runs of plate appearances without an out")
+	maxExtra       = flag.Int("max-extra", 15, "call a matchup a tie after this many extra innings (0 plays until someone wins)")
+	warmup         = flag.Int("warmup", 0, "play and discard this many games per lineup before the ones that count; costs their time, and only -seed-mode lineup or shared makes results independent of worker scheduling")
+	consistentMin  = flag.Float64("consistent", 0, "also list the lowest-stddev lineups among those with at least this mean")
+	showEfficiency = flag.Bool("efficiency", false, "after the search, report its CPU time against wall time and the implied parallel speedup and per-worker throughput on stderr")
+	runsPerWin     = flag.Float64("runs-per-win", 0, "also express the run value of swaps, bench players and the baseline comparison as wins over -season-games at this many runs per win, e.g. 10 (0 disables)")
+	seasonGames    = flag.Int("season-games", 162, "games in the season -runs-per-win counts wins over")
+	benchMode      = flag.Bool("bench", false, "rank each player left out of the top lineup by the runs gained swapping them in")
+	benchLineup    = flag.Int("benchmark-lineup", 0, "instead of searching, play the first -lineup-size players in file order this many games and report engine throughput")
+	cpuProfile     = flag.String("cpuprofile", "", "write a CPU profile to this file")
+	summary        = flag.Bool("summary", false, "print each player's LHP/RHP slash lines and the team averages, then exit")
+	validatePath   = flag.String("validate-results", "", "instead of searching, check that a -format json results file's lineups are consistent with the players file")
+	diffMode       = flag.Bool("diff", false, "compare two -format json result files, given as arguments, instead of searching")
+	onPanic        = flag.String("on-panic", "abort", `when simulating a lineup panics: "abort" the search or "skip" the lineup`)
+	lineupSeed     = flag.Bool("lineup-seed", false, "seed each lineup's games from its hash and -seed so results don't depend on which worker ran it (same as -seed-mode lineup)")
+	seedMode       = flag.String("seed-mode", "worker", `how games are seeded from -seed: "worker" (a stream per worker; fastest, but which games a lineup gets depends on scheduling), "lineup" (each lineup from its hash; every lineup's stats reproduce exactly at a small reseeding cost) or "shared" (one stream in generation order on a single worker; fully reproducible and slowest)`)
+	seed           = flag.Int64("seed", 0, "base random seed; 0 seeds from the clock")
+)
+
+// clockSeed is the seed a run without -seed uses, read from the clock once
+// so every stream in the run derives from the one seed its config records.
+var clockSeed = time.Now().UnixNano()
+
+// baseSeed returns -seed, or clockSeed when it's unset.
+func baseSeed() int64 {
+	if *seed != 0 {
+		return *seed
+	}
+	return clockSeed
+}
+
+// lineupRandSeed derives the per-lineup seed used with -lineup-seed by mixing
+// the lineup's hash with the base seed, so the same lineup and seed always
+// replay the same games while neighbouring lineups get unrelated streams.
+func lineupRandSeed(hash uint64) int64 {
+	return int64(hash ^ uint64(baseSeed())*0x9e3779b97f4a7c15)
+}
+
+// Errors returned by LoadPlayers and loadPlayersFromFile, wrapped with the
+// cause so callers can tell failures apart with errors.Is.
+var (
+	ErrFileNotFound  = errors.New("player file not found")
+	ErrInvalidJSON   = errors.New("invalid player JSON")
+	ErrTooFewPlayers = errors.New("too few players for a lineup")
+)
 
+// LoadPlayers parses a JSON array of players from r and checks there are
+// enough for a -lineup-size lineup.
+func LoadPlayers(r io.Reader) ([]baseball.Player, error) {
+	var players []baseball.Player
+	if err := json.NewDecoder(r).Decode(&players); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	if len(players) < *lineupSize {
+		return nil, fmt.Errorf("%w: have %d, need at least %d", ErrTooFewPlayers, len(players), *lineupSize)
+	}
+	return players, nil
+}
+
+// loadPlayersFromFile opens filePath and parses it with LoadPlayers, adding
+// the path to any error.
 func loadPlayersFromFile(filePath string) ([]baseball.Player, error) {
-	data, err := ioutil.ReadFile(filePath)
+	f, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+	}
 	if err != nil {
 		return nil, err
 	}
-	var players []baseball.Player
-	if err := json.Unmarshal(data, &players); err != nil {
-		return nil, err
+	defer f.Close()
+	players, err := LoadPlayers(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
 	}
 	return players, nil
 }
 
+// infof logs a status line unless -quiet is set. Warnings and errors go
+// through log directly so they're never silenced.
+func infof(format string, args ...interface{}) {
+	if !*quiet {
+		log.Printf(format, args...)
+	}
+}
+
+// profile is the -cpuprofile file while the profile runs.
+var profile *os.File
+
+// stopProfile stops -cpuprofile, if it's running, and closes its file. The
+// profile is only complete once it has stopped.
+func stopProfile() {
+	if profile == nil {
+		return
+	}
+	pprof.StopCPUProfile()
+	if err := profile.Close(); err != nil {
+		log.Printf("Failed to write CPU profile: %v", err)
+	}
+	profile = nil
+}
+
+// fatal and fatalf are log.Fatal and log.Fatalf for main's exits: they stop
+// -cpuprofile first, as the deferred stop never runs on os.Exit.
+func fatal(v ...interface{}) {
+	stopProfile()
+	log.Fatal(v...)
+}
+
+func fatalf(format string, args ...interface{}) {
+	stopProfile()
+	log.Fatalf(format, args...)
+}
+
+// loadFailure describes a loadPlayersFromFile error for the user.
+func loadFailure(what string, err error) string {
+	switch {
+	case errors.Is(err, ErrFileNotFound):
+		return fmt.Sprintf("Missing %s file: %v", what, err)
+	case errors.Is(err, ErrInvalidJSON):
+		return fmt.Sprintf("Malformed %s file (expected a JSON array of players): %v", what, err)
+	case errors.Is(err, ErrTooFewPlayers):
+		return fmt.Sprintf("Not enough %s: %v", what, err)
+	}
+	return fmt.Sprintf("Failed to load %s: %v", what, err)
+}
+
+// checkSplits rejects players with missing split fields, or fills a single
+// missing field per split with a league-average estimate when impute is set.
+// A split whose OBP doesn't exceed its AVG, loaded or imputed, can never
+// walk; that's a warning, or an error when strict is set.
+func checkSplits(players []baseball.Player, impute, strict bool) error {
+	for i := range players {
+		p := &players[i]
+		for _, split := range []struct {
+			name  string
+			stats *baseball.Stats
+		}{{"LHP", &p.LHP}, {"RHP", &p.RHP}} {
+			if missing := split.stats.Missing(); len(missing) > 0 {
+				if !impute {
+					return fmt.Errorf("%s %s %s split is missing %s (use -impute to estimate)",
+						p.FirstName, p.LastName, split.name, strings.Join(missing, ", "))
+				}
+				field, err := split.stats.FillMissing()
+				if err != nil {
+					return fmt.Errorf("%s %s %s split: %w", p.FirstName, p.LastName, split.name, err)
+				}
+				infof("Imputed %s for %s %s %s split", field, p.FirstName, p.LastName, split.name)
+			}
+			// An imputed split is checked like a loaded one.
+			if split.stats.NoWalks() && *minWalkRate <= 0 {
+				msg := fmt.Sprintf("%s %s %s split has OBP %.3f <= AVG %.3f, so they'll never walk",
+					p.FirstName, p.LastName, split.name, split.stats.OBP, split.stats.AVG)
+				if strict {
+					return errors.New(msg)
+				}
+				log.Printf("Warning: %s", msg)
+			}
+		}
+		for _, recent := range []struct {
+			name  string
+			stats *baseball.Stats
+		}{{"recent LHP", p.RecentLHP}, {"recent RHP", p.RecentRHP}, {"RISP LHP", p.RISPLHP}, {"RISP RHP", p.RISPRHP}} {
+			if recent.stats == nil {
+				continue
+			}
+			if missing := recent.stats.Missing(); len(missing) > 0 {
+				return fmt.Errorf("%s %s %s split is missing %s", p.FirstName, p.LastName, recent.name, strings.Join(missing, ", "))
+			}
+			if s := recent.stats; s.OBP > 1 || s.AVG > s.OBP {
+				return fmt.Errorf("%s %s %s split needs AVG <= OBP <= 1, got %+v", p.FirstName, p.LastName, recent.name, *s)
+			}
+		}
+		for _, split := range []struct {
+			name  string
+			stats baseball.Stats
+		}{{"LHP", p.LHP}, {"RHP", p.RHP}} {
+			s := split.stats
+			if s.Double < 0 || s.Triple < 0 || s.HomeRun < 0 || s.Double+s.Triple+s.HomeRun > 1 {
+				return fmt.Errorf("%s %s %s split's double, triple and home_run shares must be non-negative and sum to at most 1", p.FirstName, p.LastName, split.name)
+			}
+			if s.PA < 0 {
+				return fmt.Errorf("%s %s %s split's pa must not be negative, got %d", p.FirstName, p.LastName, split.name, s.PA)
+			}
+		}
+		if p.Aggression < 0 {
+			return fmt.Errorf("%s %s aggression must not be negative, got %v", p.FirstName, p.LastName, p.Aggression)
+		}
+		if p.KRate < 0 || p.KRate > 1 {
+			return fmt.Errorf("%s %s k_rate must be between 0 and 1, got %v", p.FirstName, p.LastName, p.KRate)
+		}
+		if p.Availability < 0 || p.Availability > 1 {
+			return fmt.Errorf("%s %s availability must be between 0 and 1, got %v", p.FirstName, p.LastName, p.Availability)
+		}
+	}
+	return nil
+}
+
+// lineupSpace returns how many ordered k-player lineups can be drawn from n
+// players, n!/(n-k)!. It's a float64 so huge rosters report a magnitude
+// instead of overflowing.
+func lineupSpace(n, k int) float64 {
+	total := 1.0
+	for i := 0; i < k; i++ {
+		total *= float64(n - i)
+	}
+	return total
+}
+
+// searchAllowed reports whether a search of total lineups is within
+// -max-lineups or forced with -force.
+func searchAllowed(total float64) bool {
+	return total <= *maxLineups || *force
+}
+
 // combinations generates all k-combinations of numbers 0..n-1.
 // For each combination, it calls yield with a slice of indices.
 // If yield returns false, iteration stops.
@@ -137,182 +634,940 @@ func permutations(idx []int, yield func([]int) bool) {
 	rec(0)
 }
 
-// lineupHash returns a stable 64-bit FNV-1a hash for the ordered 9-player lineup.
-// It incorporates batting ORDER and uses LastName,FirstName for identity.
+// lineupHash returns a stable 64-bit FNV-1a hash for the ordered lineup.
+// It incorporates batting ORDER and uses LastName,FirstName for identity,
+// hashing the key 0:Last,First|1:Last,First|...|8:Last,First. The key's
+// bytes are fed to the hash as they're produced rather than built into a
+// string first, since this runs once per lineup simulated.
 func lineupHash(lineup []baseball.Player) uint64 {
-	h := fnv.New64a()
-	// Build a compact key like: 0:Last,First|1:Last,First|...|8:Last,First
-	var b strings.Builder
-	b.Grow(9 * 20) // heuristic to reduce reallocs
-	for i := 0; i < len(lineup); i++ {
+	h := uint64(fnvOffset64)
+	var num [20]byte
+	for i := range lineup {
 		if i > 0 {
-			b.WriteByte('|')
+			h = fnvByte(h, '|')
 		}
-		b.WriteString(fmt.Sprintf("%d:%s,%s", i, lineup[i].LastName, lineup[i].FirstName))
+		h = fnvBytes(h, strconv.AppendInt(num[:0], int64(i), 10))
+		h = fnvByte(h, ':')
+		h = fnvString(h, lineup[i].LastName)
+		h = fnvByte(h, ',')
+		h = fnvString(h, lineup[i].FirstName)
+	}
+	return h
+}
+
+// FNV-1a, as in hash/fnv, inlined so lineupHash doesn't allocate.
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+func fnvByte(h uint64, c byte) uint64 { return (h ^ uint64(c)) * fnvPrime64 }
+
+func fnvBytes(h uint64, b []byte) uint64 {
+	for _, c := range b {
+		h = fnvByte(h, c)
+	}
+	return h
+}
+
+func fnvString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h = fnvByte(h, s[i])
+	}
+	return h
+}
+
+// lineupSetHash returns a 64-bit FNV-1a hash of the lineup's players ignoring
+// batting order: identities are sorted before hashing, so every permutation of
+// the same nine players shares one key.
+func lineupSetHash(lineup []baseball.Player) uint64 {
+	ids := make([]string, len(lineup))
+	for i := range lineup {
+		ids[i] = lineup[i].LastName + "," + lineup[i].FirstName
 	}
-	h.Write([]byte(b.String()))
+	sort.Strings(ids)
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(ids, "|")))
 	return h.Sum64()
 }
 
+// setAgg summarizes every ordering of one nine-player set in -rank-sets mode.
+type setAgg struct {
+	Hash      uint64   `json:"hash"`
+	Players   []string `json:"players"` // last names, sorted
+	Orders    int64    `json:"orders"`
+	SumMean   float64  `json:"-"`
+	BestMean  float64  `json:"best_mean"`
+	BestOrder []string `json:"best_order"`
+}
+
+// AvgMean is the mean over all of the set's orderings.
+func (a *setAgg) AvgMean() float64 {
+	return a.SumMean / float64(a.Orders)
+}
+
 func main() {
+	flag.Var(&sinkSpecs, "sink", "also send each lineup promoted into the top-K to stdout, file:PATH, or an http(s) webhook URL (repeatable)")
+	flag.Parse()
 
-	players, err := loadPlayersFromFile("player_files/phillies.json")
-	if err != nil {
-		log.Fatalf("Failed to load players: %v", err)
+	if *strictData {
+		baseball.CheckBases = true
+	}
+	switch *outFormat {
+	case "text", "json", "csv", "card", "markdown":
+	case "parquet":
+		if !parquetSupport {
+			fatalf("-format parquet needs a build with -tags parquet")
+		}
+	default:
+		fatalf(`-format must be "text", "json", "csv", "card", "markdown" or "parquet", got %q`, *outFormat)
+	}
+	// Every mode sizes its lineups from here on, so check it first.
+	if *lineupSize < baseball.MinLineupSize {
+		fatalf("-lineup-size must be at least %d, got %d", baseball.MinLineupSize, *lineupSize)
+	}
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			fatalf("Failed to create CPU profile: %v", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fatalf("Failed to start CPU profile: %v", err)
+		}
+		profile = f
+		defer stopProfile()
+	}
+	if *historyTrend != "" {
+		entries, err := openHistory(*historyTrend).Entries()
+		if err != nil {
+			fatalf("Failed to read history: %v", err)
+		}
+		writeTrend(os.Stdout, entries)
+		return
+	}
+	if *genRosterN != 0 {
+		if *genRosterN < 0 {
+			fatalf("-gen-roster must be positive, got %d", *genRosterN)
+		}
+		obp, err := parseRateDist(*genOBP)
+		if err != nil {
+			fatalf("Invalid -gen-obp: %v", err)
+		}
+		slug, err := parseRateDist(*genSLUG)
+		if err != nil {
+			fatalf("Invalid -gen-slug: %v", err)
+		}
+		s := baseSeed()
+		roster := genRoster(*genRosterN, obp, slug, rand.New(rand.NewSource(s)))
+		if err := checkSplits(roster, false, true); err != nil {
+			fatalf("Generated an invalid roster: %v", err)
+		}
+		out := io.Writer(os.Stdout)
+		if *outPath != "" {
+			f, err := createOutput(*outPath)
+			if err != nil {
+				fatal(err)
+			}
+			defer f.Close()
+			out = f
+		}
+		if err := writeRoster(out, roster); err != nil {
+			fatalf("Failed to write roster: %v", err)
+		}
+		infof("Generated %d players with seed %d", len(roster), s)
+		return
+	}
+	if *validatePath != "" {
+		rep, err := loadReport(*validatePath)
+		if err != nil {
+			fatalf("Failed to load results: %v", err)
+		}
+		path, size := *playersPath, *lineupSize
+		if rc := rep.Config; rc != nil {
+			if rc.PlayersFile != "" && rc.PlayersFile != "-" {
+				path = rc.PlayersFile
+			}
+			if rc.LineupSize > 0 {
+				size = rc.LineupSize
+			}
+			if rc.PlayersSHA256 != "" && fileSHA256(path) != rc.PlayersSHA256 {
+				log.Printf("Warning: %s has changed since the results were written", path)
+			}
+		}
+		players, err := loadPlayersFromFile(path)
+		if err != nil {
+			fatal(loadFailure("players", err))
+		}
+		if err := validateResults(rep, players, size); err != nil {
+			fatalf("%s: %v", *validatePath, err)
+		}
+		infof("%s: %d lineups OK", *validatePath, len(rep.Top)+len(rep.Bottom)+len(rep.Consistent))
+		return
+	}
+	if *diffMode {
+		if flag.NArg() != 2 {
+			fatalf("usage: -diff old.json new.json")
+		}
+		old, err := loadReport(flag.Arg(0))
+		if err != nil {
+			fatalf("Failed to load results: %v", err)
+		}
+		cur, err := loadReport(flag.Arg(1))
+		if err != nil {
+			fatalf("Failed to load results: %v", err)
+		}
+		if old.Config != nil && cur.Config != nil {
+			settings, inputs := configDiff(old.Config, cur.Config)
+			if len(settings) > 0 {
+				log.Printf("Warning: the runs used different settings (%s); ranks may not be comparable", strings.Join(settings, ", "))
+			}
+			if len(inputs) > 0 {
+				infof("The runs read different inputs (%s)", strings.Join(inputs, ", "))
+			}
+		}
+		if err := writeDiff(os.Stdout, *outFormat, diffReports(old, cur)); err != nil {
+			fatalf("Failed to write diff: %v", err)
+		}
+		return
+	}
+	if *playersDir != "" && (*streamMode || len(sinkSpecs) > 0 || *dumpAll != "" || *repGame || *maxGame || *gameSeed != 0 || *dumpFields || *explainID != "" || *explainDiff != "") {
+		fatalf("-players-dir can't be combined with -stream, -sink, -dump-all, -rep-game, -max-game, -game-seed, -dump-field-states, -explain or -explain-diff")
+	}
+	if *explainDiff != "" && len(strings.Split(*explainDiff, ",")) != 2 {
+		fatalf("-explain-diff takes two lineup IDs separated by a comma, got %q", *explainDiff)
+	}
+	switch *baseline {
+	case "file", "obp", "none":
+	default:
+		fatalf(`-baseline must be "file", "obp" or "none", got %q`, *baseline)
+	}
+	switch *onPanic {
+	case "abort", "skip":
+	default:
+		fatalf(`-on-panic must be "abort" or "skip", got %q`, *onPanic)
+	}
+	if (*explainID != "" || *explainDiff != "") && *outFormat == "csv" {
+		fatalf(`-explain and -explain-diff write "text" or "json", not "csv"`)
 	}
 
-	if len(players) < 9 {
-		log.Fatalf("Need at least 9 players, have %d", len(players))
+	// In -stream mode stdout carries only JSON Lines; the final report goes to
+	// stderr unless -out names a file.
+	var out io.Writer = os.Stdout
+	var sinks []ResultSink
+	if *streamMode {
+		out = os.Stderr
+		sinks = append(sinks, newBestSink(newJSONLinesSink(os.Stdout, nil), *streamInterval))
+	}
+	for _, spec := range sinkSpecs {
+		s, err := openSink(spec)
+		if err != nil {
+			fatalf("Invalid -sink: %v", err)
+		}
+		sinks = append(sinks, s)
 	}
 
-	lineupCount := 200
+	var opponent []baseball.Player
+	if *opponentPath != "" {
+		if *platoon || *minGamesCI > 0 {
+			fatalf("-opponent can't be combined with -platoon or -min-games-ci")
+		}
+		opp, err := loadPlayersFromFile(*opponentPath)
+		if err != nil {
+			fatal(loadFailure("opponent", err))
+		}
+		if err := checkSplits(opp, *imputeStats, *strictData); err != nil {
+			fatalf("Invalid opponent data: %v", err)
+		}
+		opponent = opp[:*lineupSize]
+	}
+	var schedule []baseball.Pitcher
+	if *seasonPath != "" {
+		if opponent == nil {
+			fatalf("-season needs -opponent")
+		}
+		var err error
+		if schedule, err = loadSchedule(*seasonPath); err != nil {
+			fatalf("Invalid schedule: %v", err)
+		}
+	}
 
-	// Concurrent lineup processing
-	lineupCh := make(chan []baseball.Player, 1024)
-	var wg sync.WaitGroup
-	var count uint64
-	workers := runtime.NumCPU()
+	cfg := baseball.DefaultGameConfig()
+	cfg.OutsPerInning = *outsPerInning
+	cfg.GIDPRate = *gidpRate
+	cfg.MaxPitcherChanges = *relievers
+	if *noGIDP {
+		cfg.GIDPRate = 0
+	}
+	cfg.HomeFieldFactor = *homeFactor
+	cfg.ScoreFromThirdOnSingle = *scoreFromThird
+	cfg.ScoreFromSecondOnDouble = *secondOnDouble
+	cfg.OutStretching = *outStretching
+	cfg.ScoreFromFirstOnSingle = *scoreFromFirst
+	cfg.ExtraBaseOnThrow = *extraOnThrow
+	if *runCap > 0 {
+		cfg.RunCap, cfg.OverRunCap = *runCap, warnRunCap
+		defer reportRunCap()
+	}
+	cfg.HBPShare = *hbpShare
+	cfg.ProductiveOutRate = *productiveOut
+	cfg.SecondToThirdOnOut = *secondToThird
+	cfg.DroppedThirdStrike = *droppedThird
+	cfg.RecentWeight = *recentWeight
+	cfg.WildPitchRate = *wildPitch
+	cfg.ScoreFromThirdOnWildPitch = *wpThird
+	cfg.RunEnvironment = *runEnv
+	cfg.MinWalkRate = *minWalkRate
+	cfg.ShrinkPA = *shrinkPA
+	cfg.OpponentDefense = *oppDefense
+	if *shrinkTo != "" {
+		var t baseball.Stats
+		if _, err := fmt.Sscanf(*shrinkTo, "%g,%g,%g", &t.AVG, &t.OBP, &t.SLUG); err != nil {
+			fatalf("Invalid -shrink-to %q: want AVG,OBP,SLUG", *shrinkTo)
+		}
+		cfg.ShrinkTo = &t
+	}
+	cfg.ExtraInningRunner = *extraRunner
+	cfg.ExtraInningRunnerHalves = *extraHalves
+	cfg.MaxExtraInnings = *maxExtra
+	if *infieldIn {
+		cfg.InfieldIn = baseball.DefaultInfieldIn
+	}
+	if *ibb {
+		cfg.IntentionalWalk = baseball.DefaultIntentionalWalk
+	}
+	if *stealAttempt > 0 {
+		cfg.Steals = baseball.StealModel{AttemptRate: *stealAttempt, SuccessRate: *stealSuccess}
+	}
+	if *handScript != "" {
+		if *platoon {
+			fatalf("-pitcher-hands can't be combined with -platoon")
+		}
+		for _, h := range strings.Split(*handScript, ",") {
+			switch strings.ToUpper(strings.TrimSpace(h)) {
+			case "L", "LEFT":
+				cfg.PitcherHandByInning = append(cfg.PitcherHandByInning, "left")
+			case "R", "RIGHT":
+				cfg.PitcherHandByInning = append(cfg.PitcherHandByInning, "right")
+			default:
+				fatalf("-pitcher-hands: unknown hand %q", h)
+			}
+		}
+	}
+	cfg.Model = baseball.DefaultModel
+	if *modelPath != "" {
+		data, err := os.ReadFile(*modelPath)
+		if err != nil {
+			fatalf("Failed to read model: %v", err)
+		}
+		if err := json.Unmarshal(data, &cfg.Model); err != nil {
+			fatalf("Invalid model %s: %v", *modelPath, err)
+		}
+	}
+	if cfg.Model.StatScale == 0 {
+		cfg.Model.StatScale = 1
+	}
+	cfg.Model.StatScale *= *statScale
+	cfg.Park = baseball.ParkFactors{Double: *parkDouble, Triple: *parkTriple, HomeRun: *parkHR}
+	if *firstInning != "" {
+		if *opponentPath != "" {
+			fatalf("-first-inning doesn't apply to -opponent games; use -state")
+		}
+		st, err := parseFirstInning(*firstInning)
+		if err != nil {
+			fatalf("Invalid -first-inning: %v", err)
+		}
+		cfg.FirstInning = &st
+	}
+	if err := cfg.Validate(); err != nil {
+		fatalf("Invalid game config: %v", err)
+	}
+	if *inFlight < 1 {
+		fatalf("-in-flight must be positive, got %d", *inFlight)
+	}
+	if *games < 1 {
+		fatalf("-games must be positive, got %d", *games)
+	}
+	switch *rankSets {
+	case "", "best", "avg":
+	default:
+		fatalf(`-rank-sets must be "best" or "avg", got %q`, *rankSets)
+	}
+	if *minGamesCI < 0 {
+		fatalf("-min-games-ci must not be negative, got %v", *minGamesCI)
+	}
+	if *minGamesCI > 0 && *platoon {
+		fatalf("-min-games-ci can't be combined with -platoon")
+	}
+	if *minGamesCI > 0 && *ciStep < 1 {
+		fatalf("-ci-step must be positive, got %d", *ciStep)
+	}
+	if *lhpShare < 0 || *lhpShare > 1 {
+		fatalf("-lhp-share must be between 0 and 1, got %v", *lhpShare)
+	}
+	if *positions && *lineupSize != len(baseball.FieldingPositions) {
+		fatalf("-positions needs -lineup-size %d", len(baseball.FieldingPositions))
+	}
+	switch *seedMode {
+	case "worker":
+		if *lineupSeed {
+			*seedMode = "lineup"
+		}
+	case "lineup":
+		*lineupSeed = true
+	case "shared":
+		if *lineupSeed {
+			fatalf("-lineup-seed can't be combined with -seed-mode shared")
+		}
+	default:
+		fatalf(`-seed-mode must be "worker", "lineup" or "shared", got %q`, *seedMode)
+	}
+	if *warmup < 0 {
+		fatalf("-warmup must not be negative, got %d", *warmup)
+	}
+	if *bottomCount < 1 {
+		fatalf("-bottom must be positive, got %d", *bottomCount)
+	}
+	bottomK = *bottomCount
+	if *tiebreakSpec != "" {
+		t, err := parseTiebreak(*tiebreakSpec, *tiebreakEps)
+		if err != nil {
+			fatalf("Invalid -tiebreak: %v", err)
+		}
+		tiebreaker = t
+	}
+	if *bottomGames < 0 {
+		fatalf("-bottom-games must not be negative, got %d", *bottomGames)
+	}
+	if *evalCacheSize < 0 {
+		fatalf("-eval-cache must not be negative, got %d", *evalCacheSize)
+	}
+	if *finalists < 0 || *finalistSeeds < 2 {
+		fatalf("-finalists must not be negative and -finalist-seeds must be at least 2, got %d and %d", *finalists, *finalistSeeds)
+	}
+	var gidpRates []float64
+	if *gidpSweepSpec != "" {
+		var err error
+		if gidpRates, err = parseGIDPRates(*gidpSweepSpec); err != nil {
+			fatalf("-gidp-sweep: %v", err)
+		}
+		if *gidpSweepTop < 1 {
+			fatalf("-gidp-sweep-top must be positive, got %d", *gidpSweepTop)
+		}
+	}
+	if *seedChecks == 1 || *seedChecks < 0 {
+		fatalf("-seed-check needs at least 2 seeds, got %d", *seedChecks)
+	}
 
-	// Start workers
-	wg.Add(workers)
-	for w := 0; w < workers; w++ {
-		go func(workerID int) {
-			defer wg.Done()
-			r := rand.New(rand.NewSource(time.Now().UnixNano() + int64(workerID)*9973))
-			for lineup := range lineupCh {
-				// Compute unique key for this ordered lineup
-				hash := lineupHash(lineup)
-				orderNames := make([]string, 9)
-				for i := 0; i < 9; i++ {
-					orderNames[i] = lineup[i].LastName
-				}
-				runs := make([]int, 0, lineupCount)
-				var runsSum, hitsSum int64
-				for g := 0; g < lineupCount; g++ {
-					// --- Begin single-game simulation for this lineup ---
-					game := baseball.Game{}
-					game.StartPitcher(r)
-					var pitcherChanged bool
-					batterIndex := 0
-					for inning := 1; inning <= 9; inning++ {
-						game.MaybeChangePitcher(inning, &pitcherChanged, r)
-						outs := 0
-						for outs < 3 {
-							game.Field.AtBat = &lineup[batterIndex]
-							result := lineup[batterIndex].PlateAppearance("right", r)
-							switch result {
-							case baseball.HIT_OUT:
-								outs++
-								if game.Field.FirstBase != nil && outs < 2 {
-									if r.Float64() < 0.11 {
-										outs++
-										game.Field.FirstBase = nil
-									}
-								}
-							case baseball.HIT_BY_PITCH_WALK:
-								game.Hit(baseball.HIT_BY_PITCH_WALK)
-							case baseball.HIT_SINGLE:
-								game.Hit(baseball.HIT_SINGLE)
-							case baseball.HIT_DOUBLE:
-								game.Hit(baseball.HIT_DOUBLE)
-							case baseball.HIT_TRIPLE:
-								game.Hit(baseball.HIT_TRIPLE)
-							case baseball.HIT_HOMERUN:
-								game.Hit(baseball.HIT_HOMERUN)
-							}
-							game.Field.AtBat = nil
-							batterIndex++
-							if batterIndex >= 9 {
-								batterIndex = 0
-							}
-						}
-						lob := game.Field.LOB()
-						game.AddLOB(lob)
-						game.Field.FirstBase, game.Field.SecondBase, game.Field.ThirdBase = nil, nil, nil
-					}
-					// --- End single-game simulation ---
-					runs = append(runs, game.Runs)
-					runsSum += int64(game.Runs)
-					hitsSum += int64(game.Hits)
-				}
+	if *compareMode {
+		if flag.NArg() < 2 {
+			fatalf("usage: -compare-orders a.json b.json [more.json ...]")
+		}
+		var lineups [][]baseball.Player
+		for _, path := range flag.Args() {
+			lineup, err := readLineup(path)
+			if err != nil {
+				fatal(loadFailure("lineup", err))
+			}
+			if err := checkSplits(lineup, *imputeStats, *strictData); err != nil {
+				fatalf("Invalid lineup data in %s: %v", path, err)
+			}
+			lineups = append(lineups, lineup)
+		}
+		cmp := compareOrders(flag.Args(), lineups, cfg, *games, baseSeed(), *lineupLimit)
+		if err := writeComparison(os.Stdout, *outFormat, *games, cmp); err != nil {
+			fatalf("Failed to write results: %v", err)
+		}
+		return
+	}
+	if *evaluatePath != "" {
+		lineup, err := readLineup(*evaluatePath)
+		if err != nil {
+			fatal(loadFailure("lineup", err))
+		}
+		if err := checkSplits(lineup, *imputeStats, *strictData); err != nil {
+			fatalf("Invalid lineup data: %v", err)
+		}
+		rc := newRunConfig(cfg)
+		rc.PlayersFile, rc.PlayersSHA256 = *evaluatePath, ""
+		if *evaluatePath != "-" {
+			rc.PlayersSHA256 = fileSHA256(*evaluatePath)
+		}
+		res := evaluateWithin(lineup, cfg, *games, baseSeed(), *lineupLimit)
+		if err := writeEvaluation(os.Stdout, *outFormat, res, rc); err != nil {
+			fatalf("Failed to write results: %v", err)
+		}
+		if *hillClimbMode {
+			cache := newEvalCache(*evalCacheSize)
+			start := cache.evaluate(lineup, cfg, *games, baseSeed())
+			end, steps := hillClimb(start, cfg, *games, baseSeed(), cache)
+			writeClimb(os.Stdout, start, end, steps)
+			if cache != nil {
+				infof("Hill climb simulated %d orders and re-used %d", cache.misses, cache.hits)
+			}
+		}
+		if *dumpFields {
+			dumpFieldStates(os.Stdout, res, cfg, dumpSeed())
+		}
+		return
+	}
+	if *calibrateRPG > 0 {
+		writeCalibration(os.Stdout, calibrate(cfg, *games, *calibrateRPG, baseSeed()))
+		return
+	}
 
-				mean := float64(runsSum) / float64(lineupCount)
+	if *roundRobinN != 0 && (*playersDir == "" || *roundRobinN < 0) {
+		fatalf("-round-robin needs -players-dir and a positive number of games, got %d", *roundRobinN)
+	}
+	if *playersDir != "" {
+		if *dirJobs < 1 {
+			fatalf("-dir-jobs must be positive, got %d", *dirJobs)
+		}
+		teams, err := searchDir(*playersDir, *dirJobs, opponent, schedule, cfg, *games)
+		if err != nil {
+			fatalf("Failed to search %s: %v", *playersDir, err)
+		}
+		if *outPath != "" {
+			f, err := createOutput(*outPath)
+			if err != nil {
+				fatalf("Failed to write results: %v", err)
+			}
+			defer f.Close()
+			out = f
+		}
+		if *roundRobinN > 0 {
+			if len(teams) < 2 {
+				fatalf("-round-robin needs at least two teams, got %d", len(teams))
+			}
+			table := roundRobin(teams, cfg, *roundRobinN, baseSeed())
+			if err := writeStandings(out, *outFormat, table, *roundRobinN); err != nil {
+				fatalf("Failed to write results: %v", err)
+			}
+			return
+		}
+		if err := writeTeams(out, *outFormat, teams); err != nil {
+			fatalf("Failed to write results: %v", err)
+		}
+		return
+	}
 
-				// Maintain top-K by mean
-				hmu.Lock()
-				if len(topHeap) < topK {
-					heap.Push(&topHeap, lineupResult{Mean: mean, Order: orderNames, Hash: hash})
-				} else if topHeap[0].Mean < mean {
-					heap.Pop(&topHeap)
-					heap.Push(&topHeap, lineupResult{Mean: mean, Order: orderNames, Hash: hash})
-				}
-				hmu.Unlock()
-
-				// Maintain bottom-K by mean
-				bmu.Lock()
-				if len(bottomHeap) < bottomK {
-					heap.Push(&bottomHeap, lineupResult{Mean: mean, Order: orderNames, Hash: hash})
-				} else if bottomHeap[0].Mean > mean {
-					heap.Pop(&bottomHeap)
-					heap.Push(&bottomHeap, lineupResult{Mean: mean, Order: orderNames, Hash: hash})
-				}
-				bmu.Unlock()
-
-				// Update global aggregates once per lineup
-				val, _ := lineupStats.LoadOrStore(hash, &Agg{})
-				agg := val.(*Agg)
-				atomic.AddInt64(&agg.Games, int64(lineupCount))
-				atomic.AddInt64(&agg.Runs, runsSum)
-				atomic.AddInt64(&agg.Hits, hitsSum)
-
-				// Progress counter
-				if atomic.AddUint64(&count, 1)%100000 == 0 {
-					fmt.Printf("Processed %d permutations...\n", atomic.LoadUint64(&count))
-				}
+	players, err := loadPlayersFromFile(*playersPath)
+	if err != nil {
+		fatal(loadFailure("players", err))
+	}
+	if err := checkSplits(players, *imputeStats, *strictData); err != nil {
+		fatalf("Invalid player data: %v", err)
+	}
+	var versus []baseball.Player
+	if *versusPath != "" {
+		if versus, err = readLineup(*versusPath); err != nil {
+			fatal(loadFailure("lineup", err))
+		}
+		if err := checkSplits(versus, *imputeStats, *strictData); err != nil {
+			fatalf("Invalid lineup data in %s: %v", *versusPath, err)
+		}
+	}
+	if *backupSpec != "" {
+		if cfg.Backups, err = parseBackups(*backupSpec, players); err != nil {
+			fatalf("Invalid -backup: %v", err)
+		}
+	}
+	if *pinchHitSpec != "" {
+		if cfg.PinchHits, err = parsePinchHits(*pinchHitSpec, players); err != nil {
+			fatalf("Invalid -pinch-hit: %v", err)
+		}
+	}
+	if *pinchRunSpec != "" {
+		if cfg.PinchRunning.Runners, err = parsePinchRunners(*pinchRunSpec, players); err != nil {
+			fatalf("Invalid -pinch-run: %v", err)
+		}
+		cfg.PinchRunning.FromInning = baseball.DefaultPinchRunning.FromInning
+		cfg.PinchRunning.MaxMargin = baseball.DefaultPinchRunning.MaxMargin
+		cfg.PinchRunning.MaxSpeed = *pinchRunSpeed
+	}
+	if *doubleSwitches != "" {
+		if cfg.DoubleSwitches, err = parseDoubleSwitches(*doubleSwitches, players); err != nil {
+			fatalf("Invalid -double-switch: %v", err)
+		}
+	}
+	if *onlyPlayers != "" {
+		if players, err = onlySet(players, *onlyPlayers); err != nil {
+			fatalf("Invalid -only: %v", err)
+		}
+	}
+	if *summary {
+		writeSummary(os.Stdout, players)
+		return
+	}
+	if *re24Format != "" {
+		re := measureRE24(players[:*lineupSize], cfg, *games, baseSeed())
+		if err := writeRE24(os.Stdout, *re24Format, re); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	if *benchLineup > 0 {
+		benchmarkLineup(os.Stdout, players[:*lineupSize], cfg, *benchLineup, rand.New(rand.NewSource(baseSeed())))
+		return
+	}
+	if *candidatesPath != "" {
+		sources, lineups, err := readCandidates(*candidatesPath, players, *lineupSize)
+		if err != nil {
+			fatalf("Invalid -candidates: %v", err)
+		}
+		cmp := compareOrders(sources, lineups, cfg, *games, baseSeed(), *lineupLimit)
+		if err := writeComparison(os.Stdout, *outFormat, *games, cmp); err != nil {
+			fatalf("Failed to write results: %v", err)
+		}
+		return
+	}
+
+	workers := runtime.NumCPU()
+	if *seedMode == "shared" {
+		workers = 1
+	}
+	s := newSearch(players, opponent, cfg, *games, sinks)
+	s.schedule = schedule
+	if *gameState != "" {
+		if opponent == nil {
+			fatalf("-state needs -opponent")
+		}
+		st, err := parseState(*gameState)
+		if err == nil {
+			err = st.Validate(cfg)
+		}
+		if err != nil {
+			fatalf("Invalid -state: %v", err)
+		}
+		s.state = &st
+	}
+	if *leadoffOBP && *fixSpec != "" {
+		fatalf("-fix and -leadoff-obp each fix a player; use one")
+	}
+	if *leadoffOBP {
+		s.fixed = bestOBP(players, *lhpShare)
+		p := players[s.fixed]
+		infof("Leading off with %s %s, the best OBP on the roster", p.FirstName, p.LastName)
+	}
+	if *fixSpec != "" {
+		if s.fixed, s.fixedSlot, err = parseFix(*fixSpec, players); err != nil {
+			fatalf("Invalid -fix: %v", err)
+		}
+		p := players[s.fixed]
+		infof("Fixing %s %s in slot %d and ordering the others around them", p.FirstName, p.LastName, s.fixedSlot+1)
+	}
+
+	if *lineupIndex >= 0 {
+		if float64(*lineupIndex) >= s.space() {
+			fatalf("-lineup-index %d is out of range: the enumeration has %.0f lineups", *lineupIndex, s.space())
+		}
+		lineup := s.lineupOf(s.lineupAt(uint64(*lineupIndex)))
+		infof("Lineup #%d of %.0f in enumeration order", *lineupIndex, s.space())
+		res := evaluateWithin(lineup, cfg, *games, baseSeed(), *lineupLimit)
+		if err := writeEvaluation(out, *outFormat, res, newRunConfig(cfg)); err != nil {
+			fatalf("Failed to write results: %v", err)
+		}
+		if *dumpFields {
+			dumpFieldStates(out, res, cfg, dumpSeed())
+		}
+		return
+	}
+
+	if *gaGenerations > 0 {
+		if opponent != nil || *platoon {
+			fatalf("-ga doesn't support -opponent or -platoon yet")
+		}
+		if *gaPopulation < 2 {
+			fatalf("-ga-pop must be at least 2, got %d", *gaPopulation)
+		}
+		if *gaPatience < 0 || *gaBurnIn < 0 {
+			fatalf("-ga-patience and -ga-burn-in must not be negative")
+		}
+		opt := gaOptions{Generations: *gaGenerations, Population: *gaPopulation, Minimize: *worst, Cache: newEvalCache(*evalCacheSize)}
+		opt.Patience, opt.BurnIn, opt.Tolerance = *gaPatience, *gaBurnIn, *gaTolerance
+		seed := baseSeed()
+		if *gaContinue != "" {
+			st, err := loadGAState(*gaContinue, players, *lineupSize, *worst)
+			if err != nil {
+				fatalf("Invalid -continue: %v", err)
+			}
+			opt.Resume, seed = st, st.Seed
+			infof("Continuing from generation %d (best mean %.3f when saved)", st.Generations, st.BestMean)
+		}
+		res, n, st, history := s.runGA(opt, rand.New(rand.NewSource(seed)))
+		if *gaHistory != "" {
+			if err := saveGAHistory(*gaHistory, history); err != nil {
+				fatalf("Failed to write -ga-history: %v", err)
 			}
-		}(w)
+		}
+		if *gaSave != "" {
+			if err := saveGAState(*gaSave, st); err != nil {
+				fatalf("Failed to write -ga-save: %v", err)
+			}
+		}
+		o := optimum{Config: newRunConfig(cfg), Method: "ga", Minimize: *worst, Generations: st.Generations, Evaluated: n, Lineup: res}
+		o.History, o.Converged = history, len(history)-1 < *gaGenerations
+		o.FileOrder = fileOrder(players, cfg)
+		if err := writeOptimum(out, *outFormat, o); err != nil {
+			fatalf("Failed to write results: %v", err)
+		}
+		return
 	}
 
-	// Loop over all possible 9-player lineups (generator feeding workers)
-	go func() {
-		combinations(len(players), 9, func(idx []int) bool {
-			permutations(idx, func(order []int) bool {
-				lineup := make([]baseball.Player, 9)
-				for i := 0; i < 9; i++ {
-					lineup[i] = players[order[i]]
-				}
-				lineupCh <- lineup
-				return true
+	if *sampleSize < 0 {
+		fatalf("-sample must be at least 0, got %d", *sampleSize)
+	}
+	if *sampleSize > 0 && s.space() >= math.MaxInt64 {
+		fatalf("-sample can't index %.3g lineups; trim the roster or use -ga", s.space())
+	}
+	total := s.space()
+	if *prefilter <= 0 || *prefilter > 1 {
+		fatalf("-prefilter must be in (0, 1], got %v", *prefilter)
+	}
+	if *slotWeightSpec != "" {
+		if *prefilter == 1 {
+			fatalf("-slot-weights needs -prefilter")
+		}
+		w, err := parseSlotWeights(*slotWeightSpec, *lineupSize)
+		if err != nil {
+			fatalf("-slot-weights: %v", err)
+		}
+		s.slotWeights = w
+	}
+	if *prefilter < 1 {
+		s.setHeuristicFloor(*prefilter, rand.New(rand.NewSource(baseSeed())))
+		total *= *prefilter
+	}
+	if *sampleSize > 0 {
+		total = math.Min(total, float64(*sampleSize))
+	}
+	if !searchAllowed(total) {
+		fatalf("Exhaustive search over %d players is %.3g lineups (limit %.3g); trim the roster, raise -max-lineups, use -ga, or pass -force",
+			len(players), total, *maxLineups)
+	}
+	if *sampleSize > 0 {
+		infof("Sampling %.0f of %.0f lineups from %d players without replacement", total, s.space(), len(players))
+	} else {
+		infof("Searching %.0f lineups from %d players", total, len(players))
+	}
+	if *dumpAll != "" {
+		dump, err := openSink(*dumpAll)
+		if err != nil {
+			fatalf("Invalid -dump-all: %v", err)
+		}
+		// Roughly 250 bytes per JSON line.
+		log.Printf("Warning: -dump-all will write all %.0f lineups, about %.0f MB", total, total*250/1e6)
+		s.dump = dump
+	}
+	if *outFormat == "parquet" {
+		s.table = &lineupTable{}
+	}
+
+	stopProgress := func() {}
+	if *progressEvery > 0 && !*quiet {
+		snaps, stop := s.subscribeProgress(*progressEvery, total)
+		printed := printProgress(os.Stderr, snaps, time.Now())
+		stopProgress = func() {
+			stop()
+			<-printed
+		}
+	}
+	searchStart := time.Now()
+	cpuStart, cpuOK := processCPU()
+	err = s.run(workers)
+	stopProgress()
+	if *showEfficiency {
+		if cpuEnd, ok := processCPU(); ok && cpuOK {
+			writeEfficiency(os.Stderr, efficiency{
+				Wall:    time.Since(searchStart),
+				CPU:     cpuEnd - cpuStart,
+				Workers: workers,
+				Lineups: atomic.LoadUint64(&s.count),
 			})
-			return true
-		})
-		close(lineupCh)
-	}()
-
-	wg.Wait()
-
-	// Output top-K by mean runs
-	hmu.Lock()
-	results := make([]lineupResult, len(topHeap))
-	copy(results, topHeap)
-	hmu.Unlock()
-	sort.Slice(results, func(i, j int) bool { return results[i].Mean > results[j].Mean })
-	fmt.Println("Top lineups by average runs:")
-	for i, r := range results {
-		id := fmt.Sprintf("%x", r.Hash)[:6]
-		fmt.Printf("%2d) ID=%s mean=%.3f  order=%v\n", i+1, id, r.Mean, r.Order)
-	}
-
-	// Output bottom-K by mean runs
-	bmu.Lock()
-	bresults := make([]lineupResult, len(bottomHeap))
-	copy(bresults, bottomHeap)
-	bmu.Unlock()
-
-	sort.Slice(bresults, func(i, j int) bool { return bresults[i].Mean < bresults[j].Mean })
-	fmt.Println("Bottom lineups by average runs:")
-	for i, r := range bresults {
-		id := fmt.Sprintf("%x", r.Hash)[:6]
-		fmt.Printf("%2d) ID=%s mean=%.3f  order=%v\n", i+1, id, r.Mean, r.Order)
+		} else {
+			log.Printf("Warning: -efficiency needs process CPU time, which isn't available here")
+		}
+	}
+	if s.dump != nil {
+		closeAll([]ResultSink{s.dump})
+	}
+	if err != nil {
+		// Close the sinks so what they've received so far is flushed.
+		closeAll(sinks)
+		fatalf("Search aborted: %v", err)
+	}
+	if n := atomic.LoadUint64(&s.panics); n > 0 {
+		log.Printf("Skipped %d lineups that panicked", n)
+	}
+
+	if s.hcorr != nil {
+		infof("Pruned %d lineups by heuristic; among those simulated it correlates r=%.3f with mean runs", s.pruned, s.hcorr.r())
+	}
+	if *positions {
+		infof("Rejected %d of %d player combinations without a valid fielding alignment", s.rejected, s.combos)
+		if s.rejected == s.combos {
+			log.Printf("No nine-player combination can field %v", baseball.FieldingPositions)
+		}
+	}
+
+	closeAll(sinks)
+	if *outPath != "" {
+		f, err := createOutput(*outPath)
+		if err != nil {
+			fatalf("Failed to write results: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if *explainDiff != "" {
+		ids := strings.Split(*explainDiff, ",")
+		a, b := s.explainedLineup(ids[0]), s.explainedLineup(ids[1])
+		d := diffSlots(a, b, cfg, *games, baseSeed())
+		if err := writeSlotDiff(out, *outFormat, d); err != nil {
+			fatalf("Failed to write explanation: %v", err)
+		}
+		return
+	}
+	if *explainID != "" {
+		res := s.explainedLineup(*explainID)
+		r := rand.New(rand.NewSource(baseSeed()))
+		e := explainLineup(res.lineup, res.Hash, cfg, *games, r)
+		if e.Index, err = s.indexOf(res.lineup); err != nil {
+			fatalf("Failed to index lineup %s: %v", e.ID, err)
+		}
+		if err := writeExplanation(out, *outFormat, e); err != nil {
+			fatalf("Failed to write explanation: %v", err)
+		}
+		return
+	}
+
+	if *worst {
+		bresults := s.bottomResults()
+		if len(bresults) == 0 {
+			fatalf("No lineups were searched")
+		}
+		o := optimum{Config: newRunConfig(cfg), Method: "exhaustive", Minimize: true, Evaluated: int(atomic.LoadUint64(&s.count)), Lineup: bresults[0]}
+		o.FileOrder = fileOrder(players, cfg)
+		if err := writeOptimum(out, *outFormat, o); err != nil {
+			fatalf("Failed to write results: %v", err)
+		}
+		return
+	}
+
+	// Output top-K by score
+	results := s.topResults()
+	if *minGamesCI > 0 {
+		results = refineToCI(results, cfg, *minGamesCI, *ciStep, *ciMaxGames, workers)
+	}
+	if *topUnique > 0 {
+		results = uniqueResults(results, *topUnique)
+	}
+	var cut *meanCut
+	if *minMean > 0 {
+		var c meanCut
+		results, c = atLeastMean(results, *minMean)
+		cut = &c
+	}
+
+	// Output bottom-K by score
+	bresults := s.bottomResults()
+	if *bottomGames > 0 && opponent == nil && !*platoon {
+		bresults = resimulateBottom(bresults, cfg, *bottomGames)
+	}
+
+	if *outFormat == "json" && len(results) > 0 {
+		r := rand.New(rand.NewSource(baseSeed()))
+		results[0].Players, results[0].NoRBIRuns = slotBreakdown(results[0].lineup, cfg, *games, r)
+	}
+
+	rep := report{Config: newRunConfig(cfg), Top: results, Bottom: bresults, MinMean: cut}
+	if s.table != nil {
+		rep.table = s.table.sorted()
+	}
+	if *rankSets != "" {
+		rep.Sets = s.rankedSets(*rankSets)
+	}
+	if *baseline != "none" && opponent == nil && !*platoon && len(results) > 0 {
+		base := baselineLineup(players, *baseline, *lhpShare)
+		b := compareBaseline(*baseline, base, results[0].lineup, cfg, *games)
+		rep.Baseline = &b
+	}
+	if *finalists > 0 && opponent == nil && !*platoon && len(results) > 0 {
+		n := *finalists
+		if n > len(results) {
+			n = len(results)
+		}
+		rep.Finalists = rescoreFinalists(results[:n], cfg, *games, *finalistSeeds, baseSeed())
+	}
+	if versus != nil && opponent == nil && !*platoon && len(results) > 0 {
+		v := compareVersus(*versusPath, results[0].lineup, versus, cfg, *games, baseSeed())
+		rep.Versus = &v
+	}
+	if gidpRates != nil && opponent == nil && !*platoon && len(results) > 0 {
+		n := *gidpSweepTop
+		if n > len(results) {
+			n = len(results)
+		}
+		sw := sweepGIDP(results[:n], cfg, *games, gidpRates, baseSeed())
+		rep.GIDPSweep = &sw
+	}
+	if *seedChecks > 0 && opponent == nil && !*platoon && len(results) > 1 {
+		c := checkSeeds(results[0].lineup, results[1].lineup, results[0].Mean-results[1].Mean, cfg, *games, *seedChecks, baseSeed())
+		rep.SeedCheck = &c
+	}
+	if *consistentMin > 0 {
+		rep.Consistent = s.consistentResults()
+	}
+	if *slotFreq {
+		rep.SlotMatrix = newSlotMatrix(results)
+	}
+	if *stealNet && opponent == nil && !*platoon && len(results) > 0 {
+		v := measureSteals(results[0].lineup, cfg, *games, baseSeed())
+		rep.Steals = &v
+	}
+	if len(results) > 0 {
+		rep.TeamLine = newTeamLine(results[0], cfg)
+	}
+	if *slotSplits && len(results) > 0 {
+		rep.SlotSplits = newSlotSplits(results[0].lineup, cfg)
+	}
+	if *historyPath != "" && len(results) > 0 {
+		if err := openHistory(*historyPath).Append(newHistoryEntry(results[0], *playersPath, time.Now())); err != nil {
+			log.Printf("Warning: failed to record history: %v", err)
+		}
+	}
+	if *showTiers && opponent == nil && !*platoon {
+		rep.Tiers = groupTiers(results)
+	}
+	if *hitMix && opponent == nil && !*platoon && len(results) > 0 {
+		h := measureHitMix(results[0].lineup, cfg, *games, baseSeed())
+		rep.HitMix = &h
+	}
+	if *streaks && opponent == nil && !*platoon && len(results) > 0 {
+		st := measureStreaks(results[0].lineup, cfg, *games, baseSeed())
+		rep.Streaks = &st
+	}
+	if (len(cfg.PinchHits)+len(cfg.DoubleSwitches)+len(cfg.PinchRunning.Runners) > 0 || sitsOut(results)) && opponent == nil && len(results) > 0 {
+		sr := substitutions(results[0].lineup, cfg, *games, baseSeed())
+		rep.Subs = &sr
+	}
+	if *benchMode && len(results) > 0 {
+		rep.BenchBase, rep.Bench = benchValues(results[0].lineup, players, cfg, *games, baseSeed())
+	}
+
+	if err := writeReport(out, *outFormat, rep); err != nil {
+		fatalf("Failed to write results: %v", err)
+	}
+	if *repGame && len(results) > 0 {
+		printRepresentativeGame(out, results[0], cfg, *games, baseSeed(), *traceGame)
+	}
+	if *maxGame && len(results) > 0 {
+		printHighestGame(out, results[0], cfg, *games, baseSeed(), *traceGame)
+	}
+	if *dumpFields && len(results) > 0 {
+		dumpFieldStates(out, results[0], cfg, dumpSeed())
+	}
+	if *gameSeed != 0 && len(results) > 0 {
+		printGame(out, results[0], cfg, *gameSeed, *traceGame, fmt.Sprintf("Game for lineup ID=%s from seed %d:\n", results[0].ID(), *gameSeed))
 	}
 }
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// The tests simulate with the engine's base-state assertions on, as
+// -strict does.
+func init() {
+	baseball.CheckBases = true
+}
+
+// testPlayer returns a player with the same split against both hands: OBP
+// obp, AVG 70 points below it and SLUG slug.
+func testPlayer(last string, obp, slug float64) baseball.Player {
+	s := baseball.Stats{AVG: obp - 0.07, OBP: obp, SLUG: slug}
+	return baseball.Player{FirstName: "Test", LastName: last, LHP: s, RHP: s}
+}
+
+// testRoster returns n players, the first the best hitter and each one
+// after a little worse.
+func testRoster(n int) []baseball.Player {
+	players := make([]baseball.Player, n)
+	for i := range players {
+		players[i] = testPlayer(fmt.Sprintf("P%d", i+1), 0.380-0.015*float64(i), 0.520-0.025*float64(i))
+	}
+	return players
+}
+
+// withInt, withInt64, withFloat, withBool and withString set a flag for
+// the rest of the test.
+func withInt(t *testing.T, p *int, v int) {
+	old := *p
+	*p = v
+	t.Cleanup(func() { *p = old })
+}
+
+func withInt64(t *testing.T, p *int64, v int64) {
+	old := *p
+	*p = v
+	t.Cleanup(func() { *p = old })
+}
+
+func withFloat(t *testing.T, p *float64, v float64) {
+	old := *p
+	*p = v
+	t.Cleanup(func() { *p = old })
+}
+
+func withBool(t *testing.T, p *bool, v bool) {
+	old := *p
+	*p = v
+	t.Cleanup(func() { *p = old })
+}
+
+func withString(t *testing.T, p *string, v string) {
+	old := *p
+	*p = v
+	t.Cleanup(func() { *p = old })
+}
+
+// runSearch searches every order of size-player lineups from players over
+// games games each, with a fixed seed, and returns the finished search.
+func runSearch(t *testing.T, players []baseball.Player, size int, cfg baseball.GameConfig, games int, sinks ...ResultSink) *search {
+	t.Helper()
+	withInt(t, lineupSize, size)
+	if *seed == 0 {
+		withInt64(t, seed, 1)
+	}
+	s := newSearch(players, nil, cfg, games, sinks)
+	if err := s.run(2); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSetHashIgnoresOrder(t *testing.T) {
+	a := testRoster(9)
+	b := append([]baseball.Player(nil), a...)
+	b[0], b[8] = b[8], b[0]
+	b[3], b[4] = b[4], b[3]
+	if lineupSetHash(a) != lineupSetHash(b) {
+		t.Error("two orders of the same nine have different set hashes")
+	}
+	if lineupHash(a) == lineupHash(b) {
+		t.Error("two orders of the same nine have the same ordered hash")
+	}
+	c := append([]baseball.Player(nil), a...)
+	c[0] = testPlayer("Bench", 0.300, 0.400)
+	if lineupSetHash(a) == lineupSetHash(c) {
+		t.Error("different nines share a set hash")
+	}
+}
+
+func TestSearchSizeGuard(t *testing.T) {
+	total := lineupSpace(26, 9) // a full 26-man roster
+	if searchAllowed(total) {
+		t.Errorf("%.3g lineups passed the %.3g limit", total, *maxLineups)
+	}
+	if !searchAllowed(lineupSpace(9, 9)) {
+		t.Error("nine players' orders were refused")
+	}
+	withBool(t, force, true)
+	if !searchAllowed(total) {
+		t.Error("-force didn't lift the limit")
+	}
+}
+
+func TestLoadPlayersErrors(t *testing.T) {
+	withInt(t, lineupSize, 9)
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	for _, tc := range []struct {
+		name string
+		path string
+		want error
+	}{
+		{"missing", filepath.Join(dir, "nope.json"), ErrFileNotFound},
+		{"bad json", write("bad.json", `[{"last_name": `), ErrInvalidJSON},
+		{"short roster", write("short.json", `[{"last_name": "Solo"}]`), ErrTooFewPlayers},
+	} {
+		_, err := loadPlayersFromFile(tc.path)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestNoWalksWarning(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	free := testPlayer("Free", 0.300, 0.450)
+	free.RHP.OBP = free.RHP.AVG
+
+	if err := checkSplits([]baseball.Player{free}, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Test Free RHP split has OBP 0.230 <= AVG 0.230, so they'll never walk") {
+		t.Errorf("no warning logged, got %q", out)
+	}
+	if checkSplits([]baseball.Player{free}, false, true) == nil {
+		t.Error("-strict accepted a split that can't walk")
+	}
+
+	cfg := baseball.DefaultGameConfig()
+	cfg.PitcherHand = "right"
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		if g := baseball.SimulateGame(nineOf(free), cfg, r); g.Walks+g.HBP > 0 {
+			t.Fatalf("game %d: %d walks and %d HBP against righties", i+1, g.Walks, g.HBP)
+		}
+	}
+}
+
+func TestLoadPlayersFromReader(t *testing.T) {
+	withInt(t, lineupSize, 2)
+	players, err := LoadPlayers(strings.NewReader(`[
+		{"first_name": "A", "last_name": "One", "LHP": {"avg": 0.25, "obp": 0.32, "slug": 0.4}},
+		{"first_name": "B", "last_name": "Two", "RHP": {"avg": 0.28, "obp": 0.35, "slug": 0.45}}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 2 || players[0].LastName != "One" || players[1].RHP.OBP != 0.35 {
+		t.Errorf("loaded %+v", players)
+	}
+
+	for _, tc := range []struct {
+		in   string
+		want error
+	}{
+		{`{"last_name": "Not a list"}`, ErrInvalidJSON},
+		{`[{"last_name": "One"},`, ErrInvalidJSON},
+		{`[{"last_name": "One"}]`, ErrTooFewPlayers},
+	} {
+		if _, err := LoadPlayers(strings.NewReader(tc.in)); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.in, err, tc.want)
+		}
+	}
+}
+
+// runMain runs the program with args in a child process and returns what it
+// wrote to stdout and stderr.
+func runMain(t *testing.T, args ...string) (stdout, stderr string) {
+	t.Helper()
+	return runMainStdin(t, "", args...)
+}
+
+// runMainStdin is runMain with stdin as the program's standard input.
+func runMainStdin(t *testing.T, stdin string, args ...string) (stdout, stderr string) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMainProcess$")
+	cmd.Env = append(os.Environ(), "BATTINGLINEUP_ARGS="+strings.Join(args, "\n"))
+	cmd.Stdin = strings.NewReader(stdin)
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("%v: %v\n%s", args, err, errOut.String())
+	}
+	return out.String(), errOut.String()
+}
+
+// TestMainProcess is the child process of runMain.
+func TestMainProcess(t *testing.T) {
+	args := os.Getenv("BATTINGLINEUP_ARGS")
+	if args == "" {
+		t.Skip("only runs as runMain's child process")
+	}
+	os.Args = append([]string{"battinglineup"}, strings.Split(args, "\n")...)
+	main()
+	os.Exit(0)
+}
+
+func TestQuietKeepsResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roster.json")
+	data, _ := json.Marshal(testRoster(6))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"-players", path, "-lineup-size", "6", "-games", "50", "-seed", "1", "-progress", "1ms"}
+
+	stdout, stderr := runMain(t, args...)
+	if !strings.Contains(stderr, "Processed") || !strings.Contains(stdout, "ID=") {
+		t.Fatalf("without -quiet: stdout %q, stderr %q", stdout, stderr)
+	}
+	quietOut, quietErr := runMain(t, append(args, "-quiet")...)
+	if quietErr != "" {
+		t.Errorf("-quiet wrote to stderr:\n%s", quietErr)
+	}
+	if quietOut != stdout {
+		t.Errorf("-quiet changed the results:\n%s\nwant\n%s", quietOut, stdout)
+	}
+}
+
+func TestAggSaturates(t *testing.T) {
+	// An inning of 40 straight home runs.
+	n := 0
+	cfg := baseball.DefaultGameConfig()
+	cfg.OutcomeOverride = func(int, int) (baseball.PlateOutcome, bool) {
+		n++
+		if n <= 40 {
+			return baseball.HIT_HOMERUN, true
+		}
+		return baseball.HIT_OUT, true
+	}
+	r := rand.New(rand.NewSource(1))
+	var g baseball.Game
+	runs, _, _ := baseball.SimulateInning(&g, nineOf(testPlayer("Slugger", 0.400, 0.700)), 0, cfg, r)
+	if runs != 40 {
+		t.Fatalf("scored %d, want 40", runs)
+	}
+
+	a := Agg{Games: 1, Runs: math.MaxInt64 - 10, Hits: 5}
+	a.add(1, int64(runs), int64(g.Hits), int64(g.PA), int64(g.TotalOuts), 0)
+	if a.Runs != math.MaxInt64 {
+		t.Errorf("runs wrapped to %d", a.Runs)
+	}
+	if a.Games != 2 || a.Hits != 45 || a.PAs != 43 || a.Outs != 3 {
+		t.Errorf("aggregate %+v", a)
+	}
+	a.add(0, math.MaxInt64, 0, 0, 0, 0)
+	if a.Runs != math.MaxInt64 {
+		t.Errorf("adding MaxInt64 to a full count gave %d", a.Runs)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// meanCut records a -min-mean filter over the top lineups: how many of Of
+// listed lineups averaged at least Min runs, and the best mean among them
+// all, so an empty list says how far short it fell.
+type meanCut struct {
+	Min       float64 `json:"min"`
+	Qualified int     `json:"qualified"`
+	Of        int     `json:"of"`
+	Best      float64 `json:"best,omitempty"`
+}
+
+// atLeastMean keeps the results whose mean is at least min, in order. The
+// kept slice is never nil, so JSON output lists an empty top as [].
+func atLeastMean(results []lineupResult, min float64) ([]lineupResult, meanCut) {
+	cut := meanCut{Min: min, Of: len(results)}
+	kept := []lineupResult{}
+	for _, r := range results {
+		if r.Mean > cut.Best {
+			cut.Best = r.Mean
+		}
+		if r.Mean >= min {
+			kept = append(kept, r)
+		}
+	}
+	cut.Qualified = len(kept)
+	return kept, cut
+}
+
+// writeMeanCut prints how many top lineups cleared -min-mean.
+func writeMeanCut(w io.Writer, c *meanCut) {
+	if c.Qualified == 0 {
+		fmt.Fprintf(w, "No top lineup averages at least %.3f runs; the best of %d averages %.3f.\n", c.Min, c.Of, c.Best)
+		return
+	}
+	fmt.Fprintf(w, "%d of %d top lineups average at least %.3f runs.\n", c.Qualified, c.Of, c.Min)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMinMeanAboveEveryLineup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roster.json")
+	data, _ := json.Marshal(testRoster(6))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"-players", path, "-lineup-size", "6", "-games", "50", "-seed", "1", "-quiet", "-min-mean", "100"}
+
+	stdout, _ := runMain(t, append(args, "-format", "json")...)
+	var rep struct {
+		Top     []json.RawMessage `json:"top"`
+		MinMean *meanCut          `json:"min_mean"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &rep); err != nil {
+		t.Fatalf("%v:\n%s", err, stdout)
+	}
+	if rep.Top == nil || len(rep.Top) != 0 || !strings.Contains(stdout, `"top": []`) {
+		t.Errorf("top lineups %v, want an empty list", rep.Top)
+	}
+	if c := rep.MinMean; c == nil || c.Min != 100 || c.Qualified != 0 || c.Of == 0 || c.Best <= 0 {
+		t.Errorf("min_mean %+v", c)
+	}
+
+	stdout, _ = runMain(t, args...)
+	if !strings.Contains(stdout, "No top lineup averages at least 100.000 runs; the best of ") {
+		t.Errorf("text report:\n%s", stdout)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+// mnemonicAdjectives and mnemonicNouns are fixed; reordering or editing them
+// renames every lineup, so only ever append. Each has 128 entries, giving
+// 16384 names.
+var mnemonicAdjectives = [128]string{
+	"able", "amber", "ancient", "arctic", "ashen", "autumn", "balmy", "bold",
+	"brave", "breezy", "bright", "brisk", "bronze", "calm", "candid", "clever",
+	"cobalt", "cosmic", "crimson", "crisp", "curly", "daring", "dawn", "deft",
+	"dusky", "dusty", "eager", "early", "easy", "electric", "elder", "emerald",
+	"epic", "fabled", "fair", "fancy", "fearless", "fiery", "firm", "fleet",
+	"foggy", "frosty", "gentle", "giant", "gilded", "glad", "golden", "grand",
+	"green", "hardy", "hasty", "hazy", "hidden", "hollow", "humble", "icy",
+	"idle", "indigo", "iron", "ivory", "jade", "jolly", "keen", "kind",
+	"lively", "lone", "loud", "lucky", "lunar", "mellow", "merry", "mighty",
+	"misty", "modest", "molten", "mossy", "muddy", "narrow", "nimble", "noble",
+	"north", "oaken", "odd", "olive", "pale", "patient", "plain", "polar",
+	"proud", "quick", "quiet", "rapid", "rare", "rowdy", "royal", "ruby",
+	"rugged", "rusty", "sandy", "scarlet", "secret", "shady", "sharp", "silent",
+	"silver", "sleek", "slow", "snowy", "solar", "sour", "spry", "steady",
+	"stormy", "sturdy", "sunny", "swift", "tame", "tawny", "tidy", "timber",
+	"true", "velvet", "vivid", "wandering", "wild", "windy", "wise", "zesty",
+}
+
+var mnemonicNouns = [128]string{
+	"anchor", "antler", "arrow", "aspen", "badger", "banner", "barn", "beacon",
+	"bear", "beaver", "birch", "bison", "bobcat", "boulder", "bramble", "bridge",
+	"brook", "buffalo", "canyon", "cardinal", "cedar", "comet", "condor", "coral",
+	"cougar", "coyote", "crane", "creek", "crow", "cypress", "delta", "dune",
+	"eagle", "ember", "falcon", "fern", "ferret", "finch", "fjord", "fox",
+	"gazelle", "geyser", "glacier", "grove", "gull", "harbor", "hawk", "heron",
+	"hickory", "hornet", "island", "jackal", "jaguar", "juniper", "kestrel", "lagoon",
+	"lantern", "lark", "lynx", "magpie", "maple", "marlin", "meadow", "mesa",
+	"moose", "moth", "nebula", "oak", "ocelot", "orca", "osprey", "otter",
+	"owl", "panther", "pebble", "pelican", "pine", "plover", "prairie", "puma",
+	"quail", "quarry", "raven", "reef", "ridge", "river", "robin", "rocket",
+	"saddle", "salmon", "sparrow", "spruce", "squall", "stag", "summit", "swallow",
+	"thicket", "thistle", "thrush", "tiger", "timber", "trail", "trout", "tundra",
+	"valley", "viper", "vole", "walnut", "walrus", "warbler", "weasel", "willow",
+	"wolf", "wren", "yak", "yarrow", "zebra", "zephyr", "acorn", "basin",
+	"cinder", "dingo", "egret", "gopher", "harrier", "iguana", "kite", "mantis",
+}
+
+// mnemonic names a lineup hash with an adjective-noun pair taken from its
+// top 14 bits.
+func mnemonic(hash uint64) string {
+	return fmt.Sprintf("%s-%s", mnemonicAdjectives[hash>>57], mnemonicNouns[(hash>>50)&127])
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMnemonicIsStableAndSpread(t *testing.T) {
+	order := testRoster(9)
+	h := lineupHash(order)
+	if a, b := mnemonic(h), mnemonic(lineupHash(testRoster(9))); a != b {
+		t.Errorf("one lineup named %q and %q", a, b)
+	}
+
+	// 100 draws from 16384 names collide about 0.3 times on average.
+	r := rand.New(rand.NewSource(1))
+	seen := map[string]uint64{}
+	collisions := 0
+	for i := 0; i < 100; i++ {
+		r.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		h := lineupHash(order)
+		name := mnemonic(h)
+		if prev, ok := seen[name]; ok && prev != h {
+			collisions++
+		}
+		seen[name] = h
+	}
+	if collisions > 2 {
+		t.Errorf("%d of 100 lineups share a name", collisions)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// onlySet returns the players named in -only's comma-separated last names,
+// in that order. It needs exactly -lineup-size distinct names, each naming
+// one player, so the search has a single set to order.
+func onlySet(players []baseball.Player, names string) ([]baseball.Player, error) {
+	var set []baseball.Player
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%s is listed twice", name)
+		}
+		seen[strings.ToLower(name)] = true
+		var match []baseball.Player
+		for _, p := range players {
+			if strings.EqualFold(p.LastName, name) {
+				match = append(match, p)
+			}
+		}
+		switch len(match) {
+		case 0:
+			return nil, fmt.Errorf("no player named %s", name)
+		case 1:
+			set = append(set, match[0])
+		default:
+			return nil, fmt.Errorf("%s names %d players", name, len(match))
+		}
+	}
+	if len(set) != *lineupSize {
+		return nil, fmt.Errorf("named %d players, need exactly %d", len(set), *lineupSize)
+	}
+	return set, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestOnlySearchesOneSetsOrders(t *testing.T) {
+	withInt(t, lineupSize, 9)
+	roster := testRoster(12)
+	set, err := onlySet(roster, "p12, P3,p1,P7,P9 ,P2,P10,P5,P4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != 9 || set[0].LastName != "P12" || set[4].LastName != "P9" {
+		t.Fatalf("-only picked %v", set)
+	}
+
+	for _, names := range []string{"P1,P2", "P1,P2,P3,P4,P5,P6,P7,P8,Nobody", "P1,P2,P3,P4,P5,P6,P7,P8,p1"} {
+		if _, err := onlySet(roster, names); err == nil {
+			t.Errorf("-only %s was accepted", names)
+		}
+	}
+
+	if testing.Short() {
+		t.Skip("playing all 9! orders")
+	}
+	s := runSearch(t, set, 9, baseball.DefaultGameConfig(), 1)
+	if s.count != 362880 || s.combos != 1 {
+		t.Errorf("processed %d lineups from %d sets, want 9! = 362880 from 1", s.count, s.combos)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// playMatchup plays one game of lineup against opp, with lineup batting last
+// when home is set, and returns each side's offense. A non-nil starter is
+// opp's pitcher for the game.
+func playMatchup(lineup, opp []baseball.Player, home bool, starter *baseball.Pitcher, cfg baseball.GameConfig, r *rand.Rand) (us, them baseball.Game) {
+	if home {
+		cfg.AwayStarter = starter
+		m := baseball.SimulateMatchup(lineup, opp, cfg, r)
+		return m.Home, m.Away
+	}
+	cfg.HomeStarter = starter
+	m := baseball.SimulateMatchup(opp, lineup, cfg, r)
+	return m.Away, m.Home
+}
+
+// playFrom plays the rest of a game against opp from st, with lineup as the
+// team batting in st.
+func playFrom(lineup, opp []baseball.Player, st baseball.GameState, starter *baseball.Pitcher, cfg baseball.GameConfig, r *rand.Rand) (us, them baseball.Game) {
+	if st.Bottom {
+		cfg.AwayStarter = starter
+		m := baseball.SimulateMatchupFrom(lineup, opp, st, cfg, r)
+		return m.Home, m.Away
+	}
+	cfg.HomeStarter = starter
+	m := baseball.SimulateMatchupFrom(opp, lineup, st, cfg, r)
+	return m.Away, m.Home
+}
+
+// parseFirstInning reads a -first-inning spec, outs=N,bases=B as in
+// parseState, which are the only keys that apply to a first inning.
+func parseFirstInning(spec string) (baseball.GameState, error) {
+	for _, kv := range strings.Split(spec, ",") {
+		if k, _, _ := strings.Cut(strings.TrimSpace(kv), "="); k != "outs" && k != "bases" {
+			return baseball.GameState{}, fmt.Errorf("%q: only outs and bases apply to the first inning", kv)
+		}
+	}
+	return parseState(spec)
+}
+
+// parseState reads a -state spec: comma-separated inning=N, half=top|bottom,
+// outs=N, bases= the occupied bases as digits (e.g. 13 for first and third,
+// empty or 0 for none) and score=US-THEM, from the batting team's side.
+func parseState(spec string) (baseball.GameState, error) {
+	st := baseball.GameState{Inning: 1}
+	var us, them int
+	for _, kv := range strings.Split(spec, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			return st, fmt.Errorf("%q isn't key=value", kv)
+		}
+		var err error
+		switch k {
+		case "inning":
+			st.Inning, err = strconv.Atoi(v)
+		case "half":
+			switch v {
+			case "top":
+				st.Bottom = false
+			case "bottom":
+				st.Bottom = true
+			default:
+				err = fmt.Errorf(`want "top" or "bottom"`)
+			}
+		case "outs":
+			st.Outs, err = strconv.Atoi(v)
+		case "bases":
+			for _, c := range v {
+				switch c {
+				case '0':
+				case '1':
+					st.First = true
+				case '2':
+					st.Second = true
+				case '3':
+					st.Third = true
+				default:
+					err = fmt.Errorf("unknown base %q", c)
+				}
+			}
+		case "score":
+			_, err = fmt.Sscanf(v, "%d-%d", &us, &them)
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return st, fmt.Errorf("%s=%s: %v", k, v, err)
+		}
+	}
+	st.HomeRuns, st.AwayRuns = them, us
+	if st.Bottom {
+		st.HomeRuns, st.AwayRuns = us, them
+	}
+	return st, nil
+}
+
+// loadSchedule reads a -season file: a JSON array of the opposing starters,
+// one per game in order.
+func loadSchedule(path string) ([]baseball.Pitcher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var schedule []baseball.Pitcher
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(schedule) == 0 {
+		return nil, fmt.Errorf("%s: schedule is empty", path)
+	}
+	for i, p := range schedule {
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("%s game %d: %w", path, i+1, err)
+		}
+	}
+	return schedule, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestOpponentRanksStrongerOffenseFirst(t *testing.T) {
+	withInt(t, lineupSize, 6)
+	withInt64(t, seed, 1)
+	players := []baseball.Player{
+		testPlayer("Strong1", 0.400, 0.550),
+		testPlayer("Strong2", 0.400, 0.550),
+		testPlayer("Strong3", 0.400, 0.550),
+		testPlayer("Strong4", 0.400, 0.550),
+		testPlayer("Strong5", 0.400, 0.550),
+		testPlayer("Strong6", 0.400, 0.550),
+		testPlayer("Weak", 0.150, 0.120),
+	}
+	opponent := nineOf(testPlayer("Opp", 0.330, 0.420))[:6]
+	s := newSearch(players, opponent, baseball.DefaultGameConfig(), 100, nil)
+	s.fixed = 0 // Strong1 leads off, leaving 720 orders to play
+	if err := s.run(2); err != nil {
+		t.Fatal(err)
+	}
+	top, bottom := s.topResults()[0], s.bottomResults()[0]
+	if strings.Contains(strings.Join(top.Order, " "), "Weak") {
+		t.Errorf("top lineup %v includes the weak hitter", top.Order)
+	}
+	if !strings.Contains(strings.Join(bottom.Order, " "), "Weak") {
+		t.Errorf("bottom lineup %v leaves out the weak hitter", bottom.Order)
+	}
+	if top.Score != top.WinPct || top.WinPct <= bottom.WinPct || top.RunDiff <= bottom.RunDiff {
+		t.Errorf("top wins %.3f by %.2f a game, bottom %.3f by %.2f", top.WinPct, top.RunDiff, bottom.WinPct, bottom.RunDiff)
+	}
+}
+
+func TestSeasonWeakStarterGivesUpMoreRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "season.json")
+	data := `[
+		{"name": "Ace", "hand": "right", "avg": 0.190, "obp": 0.250, "slug": 0.300},
+		{"name": "Mop", "hand": "right", "avg": 0.300, "obp": 0.380, "slug": 0.520}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := loadSchedule(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lineup := nineOf(testPlayer("Us", 0.330, 0.420))
+	opponent := nineOf(testPlayer("Them", 0.330, 0.420))
+	cfg := baseball.DefaultGameConfig()
+	r := rand.New(rand.NewSource(1))
+	// Game g faces schedule[g%2], as the search plays a season, at home
+	// every other pair of games so each starter sees both sides.
+	var runs [2]int
+	for g := 0; g < 2000; g++ {
+		us, _ := playMatchup(lineup, opponent, g%4 < 2, &schedule[g%len(schedule)], cfg, r)
+		runs[g%2] += us.Runs
+	}
+	if runs[1] <= runs[0] {
+		t.Errorf("scored %d runs off %s and %d off %s", runs[0], schedule[0].Name, runs[1], schedule[1].Name)
+	}
+}
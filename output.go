@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// report is everything written at the end of a search.
+type report struct {
+	Config *runConfig     `json:"config,omitempty"`
+	Top    []lineupResult `json:"top"`
+	Bottom []lineupResult `json:"bottom"`
+	Sets   []*setAgg      `json:"sets,omitempty"`
+	// MinMean is how many lineups cleared -min-mean, which Top lists.
+	MinMean *meanCut `json:"min_mean,omitempty"`
+	// Consistent is the -consistent list, steadiest first.
+	Consistent []lineupResult `json:"consistent,omitempty"`
+
+	Baseline  *baselineResult `json:"baseline,omitempty"`
+	SeedCheck *seedCheck      `json:"seed_check,omitempty"`
+	Finalists []finalist      `json:"finalists,omitempty"`
+	GIDPSweep *gidpSweep      `json:"gidp_sweep,omitempty"`
+	Versus    *versusResult   `json:"versus,omitempty"`
+	Steals    *stealValue     `json:"steals,omitempty"`
+	Subs      *subReport      `json:"substitutions,omitempty"`
+	Streaks   *streakSummary  `json:"streaks,omitempty"`
+	HitMix    *hitMixReport   `json:"hit_mix,omitempty"`
+	Tiers     []tier          `json:"tiers,omitempty"`
+	// TeamLine is the top lineup's simulated batting line.
+	TeamLine *teamLine `json:"team_line,omitempty"`
+	// SlotSplits is the top lineup slot by slot with each batter's splits.
+	SlotSplits []slotSplit `json:"slot_splits,omitempty"`
+
+	SlotMatrix *slotMatrix `json:"slot_matrix,omitempty"`
+
+	// BenchBase is the top lineup's mean in the -bench replay, and Bench
+	// each bench player's best swap into it.
+	BenchBase float64      `json:"bench_base,omitempty"`
+	Bench     []benchValue `json:"bench,omitempty"`
+
+	// table is every searched lineup's row, which "parquet" writes in
+	// place of the report.
+	table []tableRow
+}
+
+// writeReport renders rep to w as "text", "json", "csv", "card",
+// "markdown" or "parquet".
+func writeReport(w io.Writer, format string, rep report) error {
+	switch format {
+	case "parquet":
+		return writeParquet(w, rep.table)
+	case "text":
+		return writeText(w, rep)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	case "csv":
+		return writeCSV(w, rep)
+	case "card":
+		return writeCard(w, rep)
+	case "markdown":
+		return writeMarkdown(w, rep)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+func writeText(w io.Writer, rep report) error {
+	if rep.MinMean != nil {
+		writeMeanCut(w, rep.MinMean)
+	}
+	if *seasonPath != "" {
+		fmt.Fprintln(w, "Top lineups by win probability against the scheduled starters:")
+		for i, r := range rep.Top {
+			fmt.Fprintf(w, "%2d) ID=%s record=%d-%d-%d win=%.3f diff=%+.3f mean=%.3f  order=%v\n", i+1, r.label(), r.Wins, r.Games-r.Wins-r.Ties, r.Ties, r.WinPct, r.RunDiff, r.Mean, r.Order)
+		}
+		fmt.Fprintln(w, "Bottom lineups by win probability against the scheduled starters:")
+		for i, r := range rep.Bottom {
+			fmt.Fprintf(w, "%2d) ID=%s record=%d-%d-%d win=%.3f diff=%+.3f mean=%.3f  order=%v\n", i+1, r.label(), r.Wins, r.Games-r.Wins-r.Ties, r.Ties, r.WinPct, r.RunDiff, r.Mean, r.Order)
+		}
+		return nil
+	}
+	if *opponentPath != "" {
+		fmt.Fprintln(w, "Top lineups by win probability:")
+		for i, r := range rep.Top {
+			fmt.Fprintf(w, "%2d) ID=%s win=%.3f diff=%+.3f mean=%.3f  order=%v\n", i+1, r.label(), r.WinPct, r.RunDiff, r.Mean, r.Order)
+		}
+		fmt.Fprintln(w, "Bottom lineups by win probability:")
+		for i, r := range rep.Bottom {
+			fmt.Fprintf(w, "%2d) ID=%s win=%.3f diff=%+.3f mean=%.3f  order=%v\n", i+1, r.label(), r.WinPct, r.RunDiff, r.Mean, r.Order)
+		}
+		return nil
+	}
+
+	if *fixSpec != "" && len(rep.Top) > 0 {
+		slot, _, _ := strings.Cut(*fixSpec, ":")
+		n, _ := strconv.Atoi(slot)
+		fmt.Fprintf(w, "%s is fixed in slot %s; the other slots are optimized around them.\n", rep.Top[0].Order[n-1], slot)
+	}
+	fmt.Fprintln(w, "Top lineups by average runs:")
+	for i, r := range rep.Top {
+		if *minGamesCI > 0 {
+			fmt.Fprintf(w, "%2d) ID=%s mean=%.3f ±%.3f games=%d  order=%v\n", i+1, r.label(), r.Mean, r.tally.HalfWidth95(), r.Games, r.Order)
+			continue
+		}
+		var center string
+		if c := r.Center; c != nil {
+			center = fmt.Sprintf(" median=%.1f mode=%d", c.Median, c.Mode)
+		}
+		if g := r.Range; g != nil {
+			fmt.Fprintf(w, "%2d) ID=%s mean=%.3f%s floor=%d ceiling=%d r/pa=%.4f  order=%v\n", i+1, r.label(), r.Mean, center, g.Floor, g.Ceiling, r.RunsPerPA, r.Order)
+			continue
+		}
+		fmt.Fprintf(w, "%2d) ID=%s mean=%.3f%s r/pa=%.4f  order=%v\n", i+1, r.label(), r.Mean, center, r.RunsPerPA, r.Order)
+	}
+	if *platoon && len(rep.Top) > 0 {
+		best := rep.Top[0]
+		fmt.Fprintf(w, "Top lineup vs LHP=%.3f  vs RHP=%.3f  blended=%.3f (%.0f%% LHP)\n",
+			best.LHPMean, best.RHPMean, best.Mean, *lhpShare*100)
+	}
+
+	if len(rep.Tiers) > 0 {
+		writeTiers(w, rep.Tiers)
+	}
+
+	if b := rep.Baseline; b != nil {
+		fmt.Fprintf(w, "Top lineup replayed at %.3f vs %s-order baseline %.3f: %+.3f runs (%+.1f%%)%s  baseline order=%v\n",
+			b.TopMean, b.Method, b.Mean, b.Gain, b.GainPct, winsText(b.Gain), b.Order)
+	}
+
+	if rep.SlotMatrix != nil && len(rep.SlotMatrix.Rows) > 0 {
+		writeSlotMatrix(w, rep.SlotMatrix)
+	}
+
+	if len(rep.Finalists) > 0 {
+		fmt.Fprintf(w, "Finalists averaged over %d seeds:\n", *finalistSeeds)
+		for i, f := range rep.Finalists {
+			fmt.Fprintf(w, "%2d) ID=%s mean=%.3f ±%.3f (search %.3f)  order=%v\n", i+1, f.ID, f.Mean, f.StdErr, f.SearchMean, f.Order)
+		}
+	}
+
+	if rep.Versus != nil {
+		writeVersus(w, rep.Versus)
+	}
+
+	if rep.GIDPSweep != nil {
+		writeGIDPSweep(w, rep.GIDPSweep)
+	}
+
+	if c := rep.SeedCheck; c != nil {
+		verdict := "significant"
+		if !c.Significant {
+			verdict = "NOT significant; a rerun could reorder them"
+		}
+		fmt.Fprintf(w, "Top lineup over %d independent seeds: stderr=%.3f, lead over #2 %.3f (stderr %.3f) is %s\n",
+			c.Seeds, c.StdErr, c.Gap, c.GapStdErr, verdict)
+	}
+
+	if v := rep.Steals; v != nil {
+		fmt.Fprintf(w, "Running game for the top lineup: %.3f runs with steals vs %.3f without, net %+.3f  (%.2f SB, %.2f CS per game)\n",
+			v.With, v.Without, v.Net, v.SB, v.CS)
+	}
+
+	if t := rep.TeamLine; t != nil {
+		fmt.Fprintf(w, "Top lineup's team line over %d games: %.2f hits/game, AVG %.3f OBP %.3f (players' splits: AVG %.3f OBP %.3f)\n",
+			t.Games, t.HitsPerGame, t.AVG, t.OBP, t.InputAVG, t.InputOBP)
+	}
+
+	if len(rep.SlotSplits) > 0 {
+		writeSlotSplits(w, rep.SlotSplits)
+	}
+
+	if rep.HitMix != nil {
+		writeHitMix(w, *rep.HitMix)
+	}
+
+	if rep.Streaks != nil {
+		writeStreaks(w, *rep.Streaks)
+	}
+
+	if sr := rep.Subs; sr != nil {
+		fmt.Fprintf(w, "Substitutions for the top lineup over %d games:\n", sr.Games)
+		for _, c := range sr.Log {
+			if c.Inning == 0 {
+				fmt.Fprintf(w, "  unavailable, slot %d: %s for %s in %d games\n", c.Slot+1, c.In, c.Out, c.Games)
+				continue
+			}
+			if c.Runner {
+				fmt.Fprintf(w, "  inning %d, slot %d: %s runs for %s in %d games\n", c.Inning, c.Slot+1, c.In, c.Out, c.Games)
+				continue
+			}
+			if c.Moved != "" {
+				fmt.Fprintf(w, "  inning %d, slot %d: %s for %s, %s moving to slot %d, in %d games\n", c.Inning, c.Slot+1, c.In, c.Out, c.Moved, c.MovedTo+1, c.Games)
+				continue
+			}
+			fmt.Fprintf(w, "  inning %d, slot %d: %s for %s in %d games\n", c.Inning, c.Slot+1, c.In, c.Out, c.Games)
+		}
+		fmt.Fprintln(w, "Players used (games):")
+		for _, u := range sr.Used {
+			fmt.Fprintf(w, "  %-14s %d\n", u.Name, u.Games)
+		}
+	}
+
+	fmt.Fprintln(w, "Bottom lineups by average runs:")
+	for i, r := range rep.Bottom {
+		fmt.Fprintf(w, "%2d) ID=%s mean=%.3f  order=%v\n", i+1, r.label(), r.Mean, r.Order)
+	}
+
+	if len(rep.Consistent) > 0 {
+		fmt.Fprintf(w, "Most consistent lineups with mean >= %.3f:\n", *consistentMin)
+		for i, r := range rep.Consistent {
+			fmt.Fprintf(w, "%2d) ID=%s stddev=%.3f mean=%.3f  order=%v\n", i+1, r.label(), r.StdDev, r.Mean, r.Order)
+		}
+	}
+
+	if len(rep.Sets) > 0 {
+		fmt.Fprintf(w, "Player sets by %s ordering mean:\n", *rankSets)
+		for i, a := range rep.Sets {
+			fmt.Fprintf(w, "%2d) set=%s best=%.3f avg=%.3f orders=%d  players=%v  best order=%v\n",
+				i+1, fmt.Sprintf("%x", a.Hash)[:6], a.BestMean, a.AvgMean(), a.Orders, a.Players, a.BestOrder)
+		}
+	}
+
+	if len(rep.Bench) > 0 {
+		fmt.Fprintf(w, "Bench players by marginal value over the top lineup (%.3f):\n", rep.BenchBase)
+		for i, b := range rep.Bench {
+			fmt.Fprintf(w, "%2d) %-22s gain=%+.3f%s mean=%.3f replacing %s  order=%v\n", i+1, b.Name, b.Gain, winsText(b.Gain), b.Mean, b.Replaces, b.Order)
+		}
+	}
+	return nil
+}
+
+// writeCard prints the top lineup as a lineup card: one numbered line per
+// slot with the player's full name and position. Positions come from a
+// valid fielding alignment when the players have one, and otherwise from
+// the players file as listed.
+func writeCard(w io.Writer, rep report) error {
+	if len(rep.Top) == 0 {
+		return fmt.Errorf("no lineup to print")
+	}
+	lineup := rep.Top[0].lineup
+	positions, ok := baseball.Alignment(lineup)
+	if !ok {
+		positions = make([]string, len(lineup))
+		for i, p := range lineup {
+			positions[i] = p.Position
+		}
+	}
+	fmt.Fprintf(w, "LINEUP CARD  (ID %s, %.2f runs/game)\n", rep.Top[0].label(), rep.Top[0].Mean)
+	for i, p := range lineup {
+		fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("%2d.  %-26s %s", i+1, p.FirstName+" "+p.LastName, positions[i]), " "))
+	}
+	return nil
+}
+
+// writeMarkdown writes the top and bottom lineups as Markdown tables, with
+// players' full names in batting order.
+func writeMarkdown(w io.Writer, rep report) error {
+	for _, list := range []struct {
+		title   string
+		results []lineupResult
+	}{{"Top lineups", rep.Top}, {"Bottom lineups", rep.Bottom}} {
+		fmt.Fprintf(w, "### %s\n\n", list.title)
+		fmt.Fprintln(w, "| Rank | ID | Order | Mean | StdDev |")
+		fmt.Fprintln(w, "|---:|---|---|---:|---:|")
+		for i, r := range list.results {
+			names := r.Order
+			if r.lineup != nil {
+				names = make([]string, len(r.lineup))
+				for j, p := range r.lineup {
+					names[j] = p.FirstName + " " + p.LastName
+				}
+			}
+			for j := range names {
+				names[j] = markdownEscape(names[j])
+			}
+			fmt.Fprintf(w, "| %d | %s | %s | %.3f | %.3f |\n", i+1, r.label(), strings.Join(names, ", "), r.Mean, r.StdDev)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// markdownEscape escapes the characters that would break a Markdown table
+// cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ").Replace(s)
+}
+
+// writeCSV writes one row per lineup with a column per batting slot.
+func writeCSV(w io.Writer, rep report) error {
+	cw := csv.NewWriter(w)
+	header := []string{"list", "rank", "id", "hash", "mean", "stddev", "games", "runs_per_pa"}
+	if *mnemonics {
+		header = append(header, "name")
+	}
+	if *floorCeiling {
+		header = append(header, "p10", "p90")
+	}
+	if *medianMode {
+		header = append(header, "median", "mode")
+	}
+	for i := 1; i <= *lineupSize; i++ {
+		header = append(header, "slot"+strconv.Itoa(i))
+	}
+	cw.Write(header)
+	for _, list := range []struct {
+		name    string
+		results []lineupResult
+	}{{"top", rep.Top}, {"bottom", rep.Bottom}, {"consistent", rep.Consistent}} {
+		for i, r := range list.results {
+			row := []string{
+				list.name,
+				strconv.Itoa(i + 1),
+				r.ID(),
+				strconv.FormatUint(r.Hash, 10),
+				strconv.FormatFloat(r.Mean, 'f', 4, 64),
+				strconv.FormatFloat(r.StdDev, 'f', 4, 64),
+				strconv.Itoa(r.Games),
+				strconv.FormatFloat(r.RunsPerPA, 'f', 4, 64),
+			}
+			if *mnemonics {
+				row = append(row, mnemonic(r.Hash))
+			}
+			if *floorCeiling {
+				var lo, hi string
+				if g := r.Range; g != nil {
+					lo, hi = strconv.Itoa(g.Floor), strconv.Itoa(g.Ceiling)
+				}
+				row = append(row, lo, hi)
+			}
+			if *medianMode {
+				var med, mode string
+				if c := r.Center; c != nil {
+					med, mode = strconv.FormatFloat(c.Median, 'f', 1, 64), strconv.Itoa(c.Mode)
+				}
+				row = append(row, med, mode)
+			}
+			row = append(row, r.Order...)
+			cw.Write(row)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// createOutput opens path for writing, creating any missing parent directories.
+func createOutput(path string) (*os.File, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create output directory: %w", err)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create output file: %w", err)
+	}
+	return f, nil
+}
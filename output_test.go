@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// smallReport searches the orders of six test players and returns
+// the report main would write.
+func smallReport(t *testing.T) report {
+	t.Helper()
+	cfg := baseball.DefaultGameConfig()
+	s := runSearch(t, testRoster(6), 6, cfg, 20)
+	return report{Config: newRunConfig(cfg), Top: s.topResults(), Bottom: s.bottomResults()}
+}
+
+func TestJSONOutputFile(t *testing.T) {
+	rep := smallReport(t)
+	path := filepath.Join(t.TempDir(), "runs", "results.json")
+	f, err := createOutput(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeReport(f, "json", rep); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Top) != len(rep.Top) || len(got.Bottom) != len(rep.Bottom) {
+		t.Fatalf("read %d top and %d bottom lineups, wrote %d and %d", len(got.Top), len(got.Bottom), len(rep.Top), len(rep.Bottom))
+	}
+	for i := range rep.Top {
+		if got.Top[i].Hash != rep.Top[i].Hash || got.Top[i].Mean != rep.Top[i].Mean {
+			t.Errorf("top #%d: read %x mean %v, wrote %x mean %v", i+1, got.Top[i].Hash, got.Top[i].Mean, rep.Top[i].Hash, rep.Top[i].Mean)
+		}
+	}
+	if got.Config == nil || got.Config.LineupSize != 6 {
+		t.Errorf("config = %+v, want lineup size 6", got.Config)
+	}
+}
+
+func TestCardListsEverySlot(t *testing.T) {
+	rep := smallReport(t)
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "card", rep); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 7 || !strings.HasPrefix(lines[0], "LINEUP CARD  (ID "+rep.Top[0].ID()) {
+		t.Fatalf("card:\n%s", buf.String())
+	}
+	for i, p := range rep.Top[0].lineup {
+		want := fmt.Sprintf("%2d.  Test %s", i+1, p.LastName)
+		if !strings.HasPrefix(lines[i+1], want) {
+			t.Errorf("slot %d = %q, want it to start %q", i+1, lines[i+1], want)
+		}
+	}
+}
+
+func TestMarkdownTables(t *testing.T) {
+	rep := smallReport(t)
+	rep.Top[0].lineup[0].LastName = "Pipe|Name"
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "markdown", rep); err != nil {
+		t.Fatal(err)
+	}
+	sections := strings.Split(strings.TrimSpace(buf.String()), "\n\n")
+	if len(sections) != 4 || sections[0] != "### Top lineups" || sections[2] != "### Bottom lineups" {
+		t.Fatalf("markdown:\n%s", buf.String())
+	}
+	for i, results := range [][]lineupResult{rep.Top, rep.Bottom} {
+		rows := strings.Split(sections[2*i+1], "\n")
+		if len(rows) != len(results)+2 || rows[0] != "| Rank | ID | Order | Mean | StdDev |" || rows[1] != "|---:|---|---|---:|---:|" {
+			t.Errorf("table %d:\n%s", i+1, sections[2*i+1])
+			continue
+		}
+		for j, row := range rows[2:] {
+			if !strings.HasPrefix(row, fmt.Sprintf("| %d | %s | ", j+1, results[j].ID())) || strings.Count(row, " | ") != 4 {
+				t.Errorf("table %d row %d = %q", i+1, j+1, row)
+			}
+		}
+	}
+	if !strings.Contains(sections[1], `Test Pipe\|Name`) {
+		t.Errorf("a | in a name wasn't escaped:\n%s", sections[1])
+	}
+}
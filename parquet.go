@@ -0,0 +1,285 @@
+//go:build parquet
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// parquetSupport reports whether this build can write -format parquet.
+const parquetSupport = true
+
+// Parquet's physical types, converted types and encodings, and the Thrift
+// compact protocol's field types, as far as writeParquet uses them; see
+// parquet.thrift in apache/parquet-format.
+const (
+	pqInt64     = 2
+	pqDouble    = 5
+	pqByteArray = 6
+
+	pqUTF8   = 0
+	pqUint64 = 14
+
+	pqPlain = 0
+	pqRLE   = 3
+
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetMagic opens and closes every Parquet file.
+const parquetMagic = "PAR1"
+
+// parquetColumn is one required column of the table, its values PLAIN
+// encoded into data.
+type parquetColumn struct {
+	name      string
+	typ       int32
+	converted int32 // -1 for none
+	data      bytes.Buffer
+}
+
+func (c *parquetColumn) putInt64(v int64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(v))
+	c.data.Write(b[:])
+}
+
+func (c *parquetColumn) putDouble(v float64) {
+	c.putInt64(int64(math.Float64bits(v)))
+}
+
+func (c *parquetColumn) putString(s string) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(len(s)))
+	c.data.Write(b[:])
+	c.data.WriteString(s)
+}
+
+// writeParquet writes rows to w as a Parquet file: one row group with a
+// column each for the hash, every batting slot's last name, games, runs,
+// hits, mean and standard deviation, uncompressed in a single data page
+// per column. The file is written without a Parquet library, so it keeps
+// to the core of the format that every reader handles.
+func writeParquet(w io.Writer, rows []tableRow) error {
+	slots := 0
+	if len(rows) > 0 {
+		slots = len(rows[0].Order)
+	}
+	hash := &parquetColumn{name: "hash", typ: pqInt64, converted: pqUint64}
+	order := make([]*parquetColumn, slots)
+	for i := range order {
+		order[i] = &parquetColumn{name: fmt.Sprintf("slot_%d", i+1), typ: pqByteArray, converted: pqUTF8}
+	}
+	games := &parquetColumn{name: "games", typ: pqInt64, converted: -1}
+	runs := &parquetColumn{name: "runs", typ: pqInt64, converted: -1}
+	hits := &parquetColumn{name: "hits", typ: pqInt64, converted: -1}
+	mean := &parquetColumn{name: "mean", typ: pqDouble, converted: -1}
+	stddev := &parquetColumn{name: "stddev", typ: pqDouble, converted: -1}
+	for _, r := range rows {
+		if len(r.Order) != slots {
+			return fmt.Errorf("lineup %x has %d slots, want %d", r.Hash, len(r.Order), slots)
+		}
+		hash.putInt64(int64(r.Hash))
+		for i, name := range r.Order {
+			order[i].putString(name)
+		}
+		games.putInt64(r.Games)
+		runs.putInt64(r.Runs)
+		hits.putInt64(r.Hits)
+		mean.putDouble(r.Mean)
+		stddev.putDouble(r.StdDev)
+	}
+	cols := append(append([]*parquetColumn{hash}, order...), games, runs, hits, mean, stddev)
+
+	cw := &countingWriter{w: w}
+	io.WriteString(cw, parquetMagic)
+	offsets := make([]int64, len(cols))
+	sizes := make([]int64, len(cols))
+	for i, c := range cols {
+		header := parquetPageHeader(c.data.Len(), len(rows))
+		offsets[i] = cw.n
+		cw.Write(header)
+		cw.Write(c.data.Bytes())
+		sizes[i] = cw.n - offsets[i]
+	}
+	footer := parquetFooter(cols, offsets, sizes, len(rows))
+	cw.Write(footer)
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(footer)))
+	cw.Write(n[:])
+	io.WriteString(cw, parquetMagic)
+	return cw.err
+}
+
+// parquetPageHeader is the PageHeader of a PLAIN data page of size bytes
+// holding values values. Required top-level columns have no repetition or
+// definition levels, so the page is the values alone.
+func parquetPageHeader(size, values int) []byte {
+	var t thriftWriter
+	t.begin()
+	t.i32(1, 0) // DATA_PAGE
+	t.i32(2, int32(size))
+	t.i32(3, int32(size))
+	t.structField(5)
+	t.i32(1, int32(values))
+	t.i32(2, pqPlain)
+	t.i32(3, pqRLE)
+	t.i32(4, pqRLE)
+	t.end()
+	t.end()
+	return t.Bytes()
+}
+
+// parquetFooter is the FileMetaData for cols, whose chunks start at offsets
+// and run sizes bytes, over rows rows in one row group.
+func parquetFooter(cols []*parquetColumn, offsets, sizes []int64, rows int) []byte {
+	var t thriftWriter
+	t.begin()
+	t.i32(1, 1) // version
+	t.list(2, thriftStruct, len(cols)+1)
+	t.begin()
+	t.str(4, "schema")
+	t.i32(5, int32(len(cols)))
+	t.end()
+	for _, c := range cols {
+		t.begin()
+		t.i32(1, c.typ)
+		t.i32(3, 0) // REQUIRED
+		t.str(4, c.name)
+		if c.converted >= 0 {
+			t.i32(6, c.converted)
+		}
+		t.end()
+	}
+	t.i64(3, int64(rows))
+	t.list(4, thriftStruct, 1)
+	t.begin()
+	t.list(1, thriftStruct, len(cols))
+	var total int64
+	for i, c := range cols {
+		total += sizes[i]
+		t.begin()
+		t.i64(2, offsets[i])
+		t.structField(3)
+		t.i32(1, c.typ)
+		t.list(2, thriftI32, 2)
+		t.varint(pqPlain)
+		t.varint(pqRLE)
+		t.list(3, thriftBinary, 1)
+		t.binary(c.name)
+		t.i32(4, 0) // UNCOMPRESSED
+		t.i64(5, int64(rows))
+		t.i64(6, sizes[i])
+		t.i64(7, sizes[i])
+		t.i64(9, offsets[i])
+		t.end()
+		t.end()
+	}
+	t.i64(2, total)
+	t.i64(3, int64(rows))
+	t.end()
+	t.str(6, "battinglineup")
+	t.end()
+	return t.Bytes()
+}
+
+// thriftWriter encodes structs in the Thrift compact protocol, as much of
+// it as Parquet's metadata needs. begin and end bracket each struct; list
+// elements that are structs are bracketed the same way.
+type thriftWriter struct {
+	bytes.Buffer
+	last []int16 // the previous field id in each open struct
+}
+
+func (t *thriftWriter) begin() { t.last = append(t.last, 0) }
+
+func (t *thriftWriter) end() {
+	t.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+// field writes the header of field id of the given type, as a delta from
+// the previous field's id when that fits.
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.last[len(t.last)-1]
+	if d := id - *last; d > 0 && d <= 15 {
+		t.WriteByte(byte(d)<<4 | typ)
+	} else {
+		t.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+// varint writes v zigzag encoded, as compact Thrift writes every integer.
+func (t *thriftWriter) varint(v int64) {
+	t.uvarint(uint64(v<<1 ^ v>>63))
+}
+
+func (t *thriftWriter) binary(s string) {
+	t.uvarint(uint64(len(s)))
+	t.WriteString(s)
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) str(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.binary(s)
+}
+
+// list writes the header of a list field of n elements of type elem; the
+// caller writes the elements.
+func (t *thriftWriter) list(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.WriteByte(byte(n)<<4 | elem)
+		return
+	}
+	t.WriteByte(0xf0 | elem)
+	t.uvarint(uint64(n))
+}
+
+// structField opens a struct field; close it with end.
+func (t *thriftWriter) structField(id int16) {
+	t.field(id, thriftStruct)
+	t.begin()
+}
+
+// countingWriter tracks the offset into the file and the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
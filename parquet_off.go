@@ -0,0 +1,15 @@
+//go:build !parquet
+
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+// parquetSupport reports whether this build can write -format parquet.
+const parquetSupport = false
+
+func writeParquet(io.Writer, []tableRow) error {
+	return errors.New("this build can't write Parquet; rebuild with -tags parquet")
+}
@@ -0,0 +1,20 @@
+//go:build !parquet
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParquetNeedsItsBuildTag(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeReport(&buf, "parquet", report{})
+	if err == nil || !strings.Contains(err.Error(), "-tags parquet") {
+		t.Errorf("writing parquet without the tag: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %d bytes", buf.Len())
+	}
+}
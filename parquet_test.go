@@ -0,0 +1,148 @@
+//go:build parquet
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// thriftReader decodes the Thrift compact protocol into maps from field id
+// to value, enough to read back what writeParquet writes.
+type thriftReader struct {
+	buf *bytes.Reader
+}
+
+func (t thriftReader) varint() int64 {
+	u, err := binary.ReadUvarint(t.buf)
+	if err != nil {
+		panic(err)
+	}
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (t thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return t.varint()
+	case thriftBinary:
+		n, _ := binary.ReadUvarint(t.buf)
+		b := make([]byte, n)
+		t.buf.Read(b)
+		return string(b)
+	case thriftList:
+		h, _ := t.buf.ReadByte()
+		n := int(h >> 4)
+		if n == 15 {
+			u, _ := binary.ReadUvarint(t.buf)
+			n = int(u)
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = t.value(h & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return t.fields()
+	}
+	panic("unexpected thrift type")
+}
+
+func (t thriftReader) fields() map[int16]interface{} {
+	m := make(map[int16]interface{})
+	var id int16
+	for {
+		h, _ := t.buf.ReadByte()
+		if h == 0 {
+			return m
+		}
+		if d := int16(h >> 4); d != 0 {
+			id += d
+		} else {
+			id = int16(t.varint())
+		}
+		m[id] = t.value(h & 0x0f)
+	}
+}
+
+// readParquetFooter returns the FileMetaData of the Parquet file in data.
+func readParquetFooter(data []byte) (map[int16]interface{}, error) {
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		return nil, errors.New("missing PAR1 magic")
+	}
+	n := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-n : len(data)-8]
+	return thriftReader{bytes.NewReader(footer)}.fields(), nil
+}
+
+// parquetColumnData returns the values of column col, PLAIN encoded, from
+// the file's single row group.
+func parquetColumnData(data []byte, meta map[int16]interface{}, col int) []byte {
+	group := meta[4].([]interface{})[0].(map[int16]interface{})
+	chunk := group[1].([]interface{})[col].(map[int16]interface{})
+	offset := chunk[3].(map[int16]interface{})[9].(int64)
+	r := bytes.NewReader(data[offset:])
+	header := thriftReader{r}.fields()
+	start := len(data) - r.Len()
+	return data[start : start+int(header[3].(int64))]
+}
+
+func TestWriteParquetReadsBack(t *testing.T) {
+	rows := []tableRow{
+		{Hash: 1, Order: []string{"Turner", "Harper"}, Games: 10, Runs: 41, Hits: 80, Mean: 4.1, StdDev: 2.5},
+		{Hash: math.MaxUint64, Order: []string{"Harper", "Turner"}, Games: 10, Runs: 38, Hits: 77, Mean: 3.8, StdDev: 2.25},
+		{Hash: 3, Order: []string{"Harper", "Schwarber"}, Games: 12, Runs: 60, Hits: 99, Mean: 5, StdDev: 3},
+	}
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := readParquetFooter(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := meta[3].(int64); got != int64(len(rows)) {
+		t.Fatalf("num_rows = %d, want %d", got, len(rows))
+	}
+	schema := meta[2].([]interface{})
+	var names []string
+	for _, el := range schema[1:] {
+		names = append(names, el.(map[int16]interface{})[4].(string))
+	}
+	want := []string{"hash", "slot_1", "slot_2", "games", "runs", "hits", "mean", "stddev"}
+	if len(names) != len(want) {
+		t.Fatalf("columns = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("columns = %v, want %v", names, want)
+		}
+	}
+
+	hashes := parquetColumnData(buf.Bytes(), meta, 0)
+	if got := binary.LittleEndian.Uint64(hashes[8:]); got != math.MaxUint64 {
+		t.Errorf("row 2 hash = %d, want %d", got, uint64(math.MaxUint64))
+	}
+	means := parquetColumnData(buf.Bytes(), meta, 6)
+	if got := math.Float64frombits(binary.LittleEndian.Uint64(means[16:])); got != 5 {
+		t.Errorf("row 3 mean = %v, want 5", got)
+	}
+	slot2 := parquetColumnData(buf.Bytes(), meta, 2)
+	n := binary.LittleEndian.Uint32(slot2)
+	if got := string(slot2[4 : 4+n]); got != "Harper" {
+		t.Errorf("row 1 slot_2 = %q, want Harper", got)
+	}
+}
+
+func TestWriteParquetRejectsRaggedOrders(t *testing.T) {
+	rows := []tableRow{
+		{Hash: 1, Order: []string{"Turner", "Harper"}},
+		{Hash: 2, Order: []string{"Turner"}},
+	}
+	if err := writeParquet(&bytes.Buffer{}, rows); err == nil {
+		t.Fatal("want an error for lineups of different sizes")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// prefilterSample is how many random lineups set the -prefilter cutoff.
+const prefilterSample = 4096
+
+// setHeuristicFloor sets the heuristic score a lineup needs to be
+// simulated so that about frac of the search space passes, estimated from
+// random lineups drawn the way the search draws them.
+func (s *search) setHeuristicFloor(frac float64, r *rand.Rand) {
+	pool := s.pool()
+	scores := make([]float64, prefilterSample)
+	for k := range scores {
+		p := append([]int(nil), pool...)
+		r.Shuffle(len(p), func(i, j int) { p[i], p[j] = p[j], p[i] })
+		scores[k] = s.heuristic(s.lineupOf(s.place(p[:s.free()])))
+	}
+	sort.Float64s(scores)
+	s.heuristicFloor = scores[int(float64(len(scores))*(1-frac))]
+	s.hcorr = &heuristicCorr{}
+}
+
+// heuristic scores lineup for -prefilter, weighting slots by -slot-weights
+// when set.
+func (s *search) heuristic(lineup []baseball.Player) float64 {
+	return baseball.WeightedHeuristicScore(lineup, s.slotWeights)
+}
+
+// parseSlotWeights reads a -slot-weights spec: size comma-separated
+// non-negative weights, at least one of them positive.
+func parseSlotWeights(spec string, size int) ([]float64, error) {
+	fields := strings.Split(spec, ",")
+	if len(fields) != size {
+		return nil, fmt.Errorf("%q has %d weights, want one per slot (%d)", spec, len(fields), size)
+	}
+	weights := make([]float64, size)
+	total := 0.0
+	for i, f := range fields {
+		w, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("slot %d weight %q isn't a non-negative number", i+1, f)
+		}
+		weights[i] = w
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%q needs at least one positive weight", spec)
+	}
+	return weights, nil
+}
+
+// heuristicCorr accumulates the Pearson correlation between the heuristic
+// score and the simulated mean of the lineups that passed -prefilter.
+type heuristicCorr struct {
+	mu                    sync.Mutex
+	n                     float64
+	sx, sy, sxx, syy, sxy float64
+}
+
+func (c *heuristicCorr) add(x, y float64) {
+	c.mu.Lock()
+	c.n++
+	c.sx, c.sy = c.sx+x, c.sy+y
+	c.sxx, c.syy, c.sxy = c.sxx+x*x, c.syy+y*y, c.sxy+x*y
+	c.mu.Unlock()
+}
+
+// r returns the correlation, or NaN with fewer than two lineups or no
+// spread.
+func (c *heuristicCorr) r() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cov := c.n*c.sxy - c.sx*c.sy
+	vx, vy := c.n*c.sxx-c.sx*c.sx, c.n*c.syy-c.sy*c.sy
+	if c.n < 2 || vx <= 0 || vy <= 0 {
+		return math.NaN()
+	}
+	return cov / math.Sqrt(vx*vy)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progressSnapshot is the state of a running search at one moment.
+type progressSnapshot struct {
+	At        time.Time
+	Processed uint64
+	Total     float64
+	// BestMean is the mean runs of the lineup currently ranked first, or
+	// NaN before any has finished.
+	BestMean float64
+}
+
+// subscribeProgress samples s every interval and delivers the snapshots on
+// the returned channel. The channel holds only the latest snapshot: a slow
+// consumer skips the ones it missed and never holds up the sampler, let
+// alone the workers. stop ends sampling and closes the channel.
+func (s *search) subscribeProgress(interval time.Duration, total float64) (snaps <-chan progressSnapshot, stop func()) {
+	ch := make(chan progressSnapshot, 1)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(ch)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-t.C:
+				snap := progressSnapshot{At: now, Processed: atomic.LoadUint64(&s.count), Total: total, BestMean: s.bestMean()}
+				// Latest wins: replace an unread snapshot rather than wait.
+				select {
+				case <-ch:
+				default:
+				}
+				ch <- snap
+			}
+		}
+	}()
+	return ch, func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// bestMean returns the mean of the top-ranked lineup so far, or NaN.
+func (s *search) bestMean() float64 {
+	s.hmu.Lock()
+	defer s.hmu.Unlock()
+	if len(s.top) == 0 {
+		return math.NaN()
+	}
+	best := s.top[0]
+	for _, r := range s.top[1:] {
+		if ranksAbove(r, best) {
+			best = r
+		}
+	}
+	return best.Mean
+}
+
+// printProgress reports each snapshot's processed count, percent of total,
+// throughput since the previous one, an ETA and the best mean so far to w
+// until snaps is closed. done is closed once it returns.
+func printProgress(w io.Writer, snaps <-chan progressSnapshot, start time.Time) (done <-chan struct{}) {
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		last, lastAt := uint64(0), start
+		for snap := range snaps {
+			n := snap.Processed
+			rate := float64(n-last) / snap.At.Sub(lastAt).Seconds()
+			last, lastAt = n, snap.At
+			eta := "unknown"
+			if rate > 0 && float64(n) < snap.Total {
+				eta = (time.Duration((snap.Total - float64(n)) / rate * float64(time.Second))).Round(time.Second).String()
+			}
+			fmt.Fprintf(w, "Processed %d/%.0f lineups (%.1f%%)  %.0f lineups/sec  ETA %s  best %.3f\n",
+				n, snap.Total, 100*float64(n)/snap.Total, rate, eta, snap.BestMean)
+		}
+	}()
+	return finished
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestPrintProgress(t *testing.T) {
+	start := time.Unix(1000, 0)
+	snaps := make(chan progressSnapshot)
+	var buf bytes.Buffer
+	done := printProgress(&buf, snaps, start)
+	for i := 1; i <= 3; i++ {
+		snaps <- progressSnapshot{At: start.Add(time.Duration(i) * time.Second), Processed: uint64(100 * i), Total: 400, BestMean: 4.5}
+	}
+	close(snaps)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("printProgress didn't return after its channel closed")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("printed %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		n := 100 * (i + 1)
+		want := fmt.Sprintf("Processed %d/400 lineups (%.1f%%)  100 lineups/sec", n, float64(n)/4)
+		if !strings.HasPrefix(line, want) {
+			t.Errorf("line %d = %q, want it to start %q", i+1, line, want)
+		}
+	}
+	if !strings.Contains(lines[0], "ETA 3s") || !strings.Contains(lines[2], "ETA 1s") {
+		t.Errorf("ETAs don't count down:\n%s", buf.String())
+	}
+}
+
+func TestSubscribeProgressStops(t *testing.T) {
+	s := newSearch(testRoster(5), nil, baseball.DefaultGameConfig(), 1, nil)
+	before := runtime.NumGoroutine()
+	snaps, stop := s.subscribeProgress(time.Millisecond, 120)
+	<-snaps
+	stop()
+	for range snaps {
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines after stop, %d before", n, before)
+	}
+}
+
+func TestSubscribeProgressRises(t *testing.T) {
+	withInt(t, lineupSize, 6)
+	withInt64(t, seed, 1)
+	s := newSearch(testRoster(6), nil, baseball.DefaultGameConfig(), 100, nil)
+	snaps, stop := s.subscribeProgress(time.Millisecond, 720)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.run(2)
+		stop()
+	}()
+
+	var got []progressSnapshot
+	for snap := range snaps {
+		got = append(got, snap)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) < 2 {
+		t.Fatalf("got %d snapshots", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Processed < got[i-1].Processed || !got[i].At.After(got[i-1].At) {
+			t.Errorf("snapshot %d (%d at %v) went back from %d at %v", i+1, got[i].Processed, got[i].At, got[i-1].Processed, got[i-1].At)
+		}
+	}
+	if first, last := got[0], got[len(got)-1]; last.Processed <= first.Processed || last.Total != 720 {
+		t.Errorf("progress went from %d to %d of %v", first.Processed, last.Processed, last.Total)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math/rand"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// baseStates names the eight base states in matrix order, as occupied
+// bases: "---" is empty, "1-3" first and third.
+var baseStates = [8]string{"---", "1--", "-2-", "12-", "--3", "1-3", "-23", "123"}
+
+// runExpectancy is the base-out run-expectancy matrix: the mean runs scored
+// on plate appearances from each state to the end of the half-inning.
+type runExpectancy struct {
+	Games int          `json:"games"`
+	Order []string     `json:"order"`
+	Outs  int          `json:"outs"` // outs per inning; the matrix has Outs columns
+	RE    [8][]float64 `json:"re"`   // [base state][outs]
+	N     [8][]int     `json:"n"`    // plate appearances seen in each state
+}
+
+// baseState returns f's index in baseStates.
+func baseState(f baseball.Field) int {
+	s := 0
+	if f.FirstBase != nil {
+		s |= 1
+	}
+	if f.SecondBase != nil {
+		s |= 2
+	}
+	if f.ThirdBase != nil {
+		s |= 4
+	}
+	return s
+}
+
+// measureRE24 plays lineup for games seeded games and averages, for every
+// plate appearance, the runs its half-inning went on to score from there.
+func measureRE24(lineup []baseball.Player, cfg baseball.GameConfig, games int, seed int64) runExpectancy {
+	re := runExpectancy{Games: games, Outs: cfg.OutsPerInning}
+	for _, p := range lineup {
+		re.Order = append(re.Order, p.LastName)
+	}
+	sums := make([][]float64, 8)
+	for b := range re.RE {
+		re.RE[b] = make([]float64, cfg.OutsPerInning)
+		re.N[b] = make([]int, cfg.OutsPerInning)
+		sums[b] = make([]float64, cfg.OutsPerInning)
+	}
+	// pending holds each plate appearance of the current half-inning with
+	// the runs scored before it.
+	type seen struct{ base, outs, runsBefore int }
+	var pending []seen
+	inning, runs := 0, 0
+	flush := func() {
+		for _, s := range pending {
+			sums[s.base][s.outs] += float64(runs - s.runsBefore)
+			re.N[s.base][s.outs]++
+		}
+		pending, runs = pending[:0], 0
+	}
+	cfg.Trace = func(p baseball.Play) {
+		if p.Inning != inning {
+			flush()
+			inning = p.Inning
+		}
+		pending = append(pending, seen{baseState(p.Before), p.OutsBefore, runs})
+		runs += p.Runs
+		if p.Outs >= cfg.OutsPerInning {
+			flush()
+		}
+	}
+	r := rand.New(rand.NewSource(seed))
+	for g := 0; g < games; g++ {
+		baseball.SimulateGame(lineup, cfg, r)
+		flush()
+		inning = 0
+	}
+	for b := range re.RE {
+		for o := range re.RE[b] {
+			if re.N[b][o] > 0 {
+				re.RE[b][o] = sums[b][o] / float64(re.N[b][o])
+			}
+		}
+	}
+	return re
+}
+
+// writeRE24 renders re as a "text" grid, a Graphviz "dot" graph with a
+// node per base-out state, or an "html" table.
+func writeRE24(w io.Writer, format string, re runExpectancy) error {
+	switch format {
+	case "text":
+		fmt.Fprintf(w, "Run expectancy over %d games, order=%v\n", re.Games, re.Order)
+		fmt.Fprintf(w, "%-5s", "Bases")
+		for o := 0; o < re.Outs; o++ {
+			fmt.Fprintf(w, " %6s", fmt.Sprintf("%d out", o))
+		}
+		fmt.Fprintln(w)
+		for b, name := range baseStates {
+			fmt.Fprintf(w, "%-5s", name)
+			for o := 0; o < re.Outs; o++ {
+				fmt.Fprintf(w, " %6.3f", re.RE[b][o])
+			}
+			fmt.Fprintln(w)
+		}
+	case "dot":
+		fmt.Fprintln(w, "digraph re24 {")
+		fmt.Fprintln(w, "  node [shape=box];")
+		for o := 0; o < re.Outs; o++ {
+			fmt.Fprintf(w, "  subgraph cluster_%d {\n    label=\"%d out\";\n", o, o)
+			for b, name := range baseStates {
+				fmt.Fprintf(w, "    s%d_%d [label=\"%s, %d out\\n%.3f\"];\n", b, o, name, o, re.RE[b][o])
+			}
+			fmt.Fprintln(w, "  }")
+		}
+		fmt.Fprintln(w, "}")
+	case "html":
+		fmt.Fprintln(w, "<table>")
+		fmt.Fprint(w, "<tr><th>Bases</th>")
+		for o := 0; o < re.Outs; o++ {
+			fmt.Fprintf(w, "<th>%d out</th>", o)
+		}
+		fmt.Fprintln(w, "</tr>")
+		for b, name := range baseStates {
+			fmt.Fprintf(w, "<tr><th>%s</th>", html.EscapeString(name))
+			for o := 0; o < re.Outs; o++ {
+				fmt.Fprintf(w, "<td>%.3f</td>", re.RE[b][o])
+			}
+			fmt.Fprintln(w, "</tr>")
+		}
+		fmt.Fprintln(w, "</table>")
+	default:
+		return fmt.Errorf(`-re24 must be "text", "dot" or "html", got %q`, format)
+	}
+	return nil
+}
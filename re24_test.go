@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestRE24DOTHasEveryState(t *testing.T) {
+	re := measureRE24(testRoster(9), baseball.DefaultGameConfig(), 200, 1)
+	var buf bytes.Buffer
+	if err := writeRE24(&buf, "dot", re); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "digraph re24 {") {
+		t.Errorf("not a digraph:\n%s", out)
+	}
+	nodes := 0
+	for b, name := range baseStates {
+		for o := 0; o < 3; o++ {
+			node := fmt.Sprintf("s%d_%d [label=\"%s, %d out\\n", b, o, name, o)
+			if strings.Contains(out, node) {
+				nodes++
+			} else {
+				t.Errorf("no node for %s, %d out", name, o)
+			}
+		}
+	}
+	if nodes != 24 || strings.Count(out, "[label=") != 24 {
+		t.Errorf("%d of 24 states found among %d nodes", nodes, strings.Count(out, "[label="))
+	}
+}
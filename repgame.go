@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// medianRuns returns the median of runs without modifying it.
+func medianRuns(runs []int) float64 {
+	s := append([]int(nil), runs...)
+	sort.Ints(s)
+	n := len(s)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return float64(s[n/2])
+	}
+	return float64(s[n/2-1]+s[n/2]) / 2
+}
+
+// representativeGame replays lineup for games games, each from its own seed
+// derived from baseSeed, and returns the seed of the first game whose run
+// total is closest to the median of all of them, along with that median.
+func representativeGame(lineup []baseball.Player, cfg baseball.GameConfig, games int, baseSeed int64) (seed int64, median float64) {
+	runs := make([]int, games)
+	for g := range runs {
+		r := rand.New(rand.NewSource(baseSeed + int64(g)))
+		runs[g] = baseball.SimulateGame(lineup, cfg, r).Runs
+	}
+	median = medianRuns(runs)
+	best := 0
+	for g, n := range runs {
+		if math.Abs(float64(n)-median) < math.Abs(float64(runs[best])-median) {
+			best = g
+		}
+	}
+	return baseSeed + int64(best), median
+}
+
+// highestGame replays lineup for games games, seeded the way
+// representativeGame seeds them, and returns the seed and run total of the
+// first highest-scoring one. The seed alone reproduces the game: it's the
+// whole of the random stream that game draws from.
+func highestGame(lineup []baseball.Player, cfg baseball.GameConfig, games int, baseSeed int64) (seed int64, runs int) {
+	seed, runs = baseSeed, -1
+	for g := 0; g < games; g++ {
+		r := rand.New(rand.NewSource(baseSeed + int64(g)))
+		if n := baseball.SimulateGame(lineup, cfg, r).Runs; n > runs {
+			seed, runs = baseSeed+int64(g), n
+		}
+	}
+	return seed, runs
+}
+
+// printRepresentativeGame replays the top lineup's median game and writes its
+// line score and box score to w, plus a play-by-play when pbp is set.
+func printRepresentativeGame(w io.Writer, res lineupResult, cfg baseball.GameConfig, games int, baseSeed int64, pbp bool) {
+	seed, median := representativeGame(res.lineup, cfg, games, baseSeed)
+	printGame(w, res, cfg, seed, pbp, fmt.Sprintf("Representative game for lineup ID=%s (median %.1f runs over %d replays, seed %d):\n", res.ID(), median, games, seed))
+}
+
+// printHighestGame is printRepresentativeGame for the top lineup's
+// highest-scoring game, with the -game-seed that replays it.
+func printHighestGame(w io.Writer, res lineupResult, cfg baseball.GameConfig, games int, baseSeed int64, pbp bool) {
+	seed, runs := highestGame(res.lineup, cfg, games, baseSeed)
+	printGame(w, res, cfg, seed, pbp, fmt.Sprintf("Highest-scoring game for lineup ID=%s (%d runs, the most in %d replays; replay it with -game-seed %d):\n", res.ID(), runs, games, seed))
+}
+
+// printGame plays res's lineup once from seed and writes header, its line
+// score and box score to w, after a play-by-play when pbp is set.
+func printGame(w io.Writer, res lineupResult, cfg baseball.GameConfig, seed int64, pbp bool, header string) {
+	cfg.TrackSlots = true
+	if pbp {
+		cfg.Trace = func(p baseball.Play) {
+			outcome := p.Outcome.String()
+			if p.DoublePlay() {
+				outcome += " (double play)"
+			}
+			if p.Strikeout {
+				outcome += " (strikeout)"
+			}
+			fmt.Fprintf(w, "  Inning %d, %d out: %-12s %-22s runs=%d  bases: %s -> %s\n",
+				p.Inning, p.OutsBefore, p.Batter.LastName, outcome, p.Runs, p.Before, p.After)
+		}
+		fmt.Fprintf(w, "Play-by-play for lineup ID=%s:\n", res.ID())
+	}
+	game := baseball.SimulateGame(res.lineup, cfg, rand.New(rand.NewSource(seed)))
+
+	io.WriteString(w, header)
+	var line strings.Builder
+	line.WriteString("Inning ")
+	for i := range game.LineScore {
+		fmt.Fprintf(&line, "%3d", i+1)
+	}
+	line.WriteString("    R   H  LOB\nRuns   ")
+	for _, n := range game.LineScore {
+		fmt.Fprintf(&line, "%3d", n)
+	}
+	fmt.Fprintf(&line, "  %3d %3d  %3d\n", game.Runs, game.Hits, game.LOB)
+	io.WriteString(w, line.String())
+
+	fmt.Fprintf(w, "%2s  %-22s %3s %3s %3s %3s %3s %3s\n", "#", "Player", "AB", "R", "H", "RBI", "BB", "HBP")
+	for i, s := range game.Slots {
+		p := res.lineup[i]
+		fmt.Fprintf(w, "%2d  %-22s %3d %3d %3d %3d %3d %3d\n",
+			i+1, p.FirstName+" "+p.LastName, s.PA-s.Walks-s.HBP, s.Runs, s.Hits, s.RBI, s.Walks, s.HBP)
+	}
+}
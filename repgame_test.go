@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestMedianRuns(t *testing.T) {
+	for _, tc := range []struct {
+		runs []int
+		want float64
+	}{
+		{nil, 0},
+		{[]int{4}, 4},
+		{[]int{9, 1, 3}, 3},
+		{[]int{6, 1, 3, 2}, 2.5},
+	} {
+		if got := medianRuns(tc.runs); got != tc.want {
+			t.Errorf("medianRuns(%v) = %v, want %v", tc.runs, got, tc.want)
+		}
+	}
+}
+
+func TestRepresentativeGameScoresTheMedian(t *testing.T) {
+	lineup := nineOf(testPlayer("Hitter", 0.330, 0.420))
+	cfg := baseball.DefaultGameConfig()
+	const games = 101
+	seed, median := representativeGame(lineup, cfg, games, 42)
+
+	runs := make([]int, games)
+	for g := range runs {
+		runs[g] = baseball.SimulateGame(lineup, cfg, rand.New(rand.NewSource(42+int64(g)))).Runs
+	}
+	if want := medianRuns(runs); median != want {
+		t.Errorf("median %v, want %v", median, want)
+	}
+	// With an odd number of games some game scores exactly the median.
+	got := baseball.SimulateGame(lineup, cfg, rand.New(rand.NewSource(seed))).Runs
+	if float64(got) != median {
+		t.Errorf("representative game from seed %d scored %d, median is %v", seed, got, median)
+	}
+}
+
+func TestHighestGameReplaysFromItsSeed(t *testing.T) {
+	lineup := nineOf(testPlayer("Hitter", 0.330, 0.420))
+	res := lineupResult{Hash: lineupHash(lineup), lineup: lineup}
+	cfg := baseball.DefaultGameConfig()
+	seed, runs := highestGame(lineup, cfg, 200, 7)
+
+	for g := int64(0); g < 200; g++ {
+		if n := baseball.SimulateGame(lineup, cfg, rand.New(rand.NewSource(7+g))).Runs; n > runs {
+			t.Fatalf("game %d scored %d, more than the highest %d", g, n, runs)
+		}
+	}
+	replay := baseball.SimulateGame(lineup, cfg, rand.New(rand.NewSource(seed)))
+	if replay.Runs != runs {
+		t.Errorf("replaying seed %d scored %d, want %d", seed, replay.Runs, runs)
+	}
+
+	var first, second bytes.Buffer
+	printGame(&first, res, cfg, seed, true, "")
+	printGame(&second, res, cfg, seed, true, "")
+	if first.Len() == 0 || first.String() != second.String() {
+		t.Errorf("two replays of seed %d printed\n%s\nand\n%s", seed, first.String(), second.String())
+	}
+}
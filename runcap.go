@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// maxRunCapLogs is how many innings over -run-cap are logged with their
+// plays; the rest are only counted.
+const maxRunCapLogs = 3
+
+// runCapHits counts the innings that went over -run-cap.
+var runCapHits int64
+
+// warnRunCap is GameConfig.OverRunCap for -run-cap: it logs the first few
+// innings over the cap, play by play, and counts the rest.
+func warnRunCap(inning int, plays []baseball.Play) {
+	if atomic.AddInt64(&runCapHits, 1) > maxRunCapLogs {
+		return
+	}
+	runs := 0
+	for _, p := range plays {
+		runs += p.Runs
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Warning: inning %d scored more than -run-cap %d runs (%d on plate appearances):", inning, *runCap, runs)
+	for _, p := range plays {
+		fmt.Fprintf(&b, "\n  %d out: %-12s %-8s runs=%d  bases: %s -> %s",
+			p.OutsBefore, p.Batter.LastName, p.Outcome, p.Runs, p.Before, p.After)
+	}
+	log.Print(b.String())
+}
+
+// reportRunCap logs how many innings went over -run-cap in all, when more
+// did than warnRunCap showed.
+func reportRunCap() {
+	if n := atomic.LoadInt64(&runCapHits); n > maxRunCapLogs {
+		log.Printf("%d innings in all scored more than -run-cap %d runs; the first %d are shown above", n, *runCap, maxRunCapLogs)
+	}
+}
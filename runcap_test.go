@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestRunCapWarnsOnARunawayInning(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	atomic.StoreInt64(&runCapHits, 0)
+	t.Cleanup(func() { atomic.StoreInt64(&runCapHits, 0) })
+	withInt(t, runCap, 10)
+
+	// homers plays an inning whose first n batters homer, standing in for
+	// base logic that has stopped recording outs.
+	lineup := nineOf(testPlayer("Hitter", 0.330, 0.420))
+	homers := func(n int) {
+		cfg := baseball.DefaultGameConfig()
+		cfg.RunCap, cfg.OverRunCap = *runCap, warnRunCap
+		pa := 0
+		cfg.OutcomeOverride = func(int, int) (baseball.PlateOutcome, bool) {
+			pa++
+			if pa <= n {
+				return baseball.HIT_HOMERUN, true
+			}
+			return baseball.HIT_OUT, true
+		}
+		g := baseball.Game{Inning: 1}
+		baseball.SimulateInning(&g, lineup, 0, cfg, rand.New(rand.NewSource(1)))
+	}
+
+	homers(10)
+	if runCapHits != 0 || buf.Len() != 0 {
+		t.Fatalf("a 10-run inning under a cap of 10 warned:\n%s", buf.String())
+	}
+	homers(12)
+	out := buf.String()
+	if runCapHits != 1 || !strings.Contains(out, "inning 1 scored more than -run-cap 10 runs (12 on plate appearances)") {
+		t.Fatalf("a 12-run inning logged %d warnings:\n%s", runCapHits, out)
+	}
+	// The warning lists every play: 12 homers and three outs.
+	if n := strings.Count(out, "\n  "); n != 15 {
+		t.Errorf("warning lists %d plays, want 15:\n%s", n, out)
+	}
+}
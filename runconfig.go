@@ -0,0 +1,202 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// runConfig records the settings behind a report so results can be
+// reproduced and -diff can tell when two runs aren't comparable.
+type runConfig struct {
+	// Seed is the run's base seed: -seed, or the clock's when that's 0.
+	Seed       int64  `json:"seed"`
+	LineupSeed bool   `json:"lineup_seed"`
+	SeedMode   string `json:"seed_mode"`
+	Games      int    `json:"games"`
+	Warmup     int    `json:"warmup,omitempty"`
+	Innings    int    `json:"innings"`
+	LineupSize int    `json:"lineup_size"`
+	Objective  string `json:"objective"` // "mean" or "win_pct"
+
+	OutsPerInning          int                  `json:"outs_per_inning"`
+	MaxPitcherChanges      int                  `json:"max_pitcher_changes"`
+	GIDPRate               float64              `json:"gidp_rate"`
+	HBPShare               float64              `json:"hbp_share"`
+	ScoreFromThirdOnSingle float64              `json:"score_from_third_on_single"`
+	ScoreFromFirstOnSingle float64              `json:"score_from_first_on_single,omitempty"`
+	SecondOnDouble         float64              `json:"score_from_second_on_double"`
+	OutStretching          float64              `json:"out_stretching,omitempty"`
+	ExtraBaseOnThrow       float64              `json:"extra_base_on_throw,omitempty"`
+	HomeFieldFactor        float64              `json:"home_field_factor"`
+	InfieldIn              bool                 `json:"infield_in,omitempty"`
+	IntentionalWalks       bool                 `json:"intentional_walks,omitempty"`
+	ProductiveOutRate      float64              `json:"productive_out_rate,omitempty"`
+	SecondToThirdOnOut     float64              `json:"second_to_third_on_out,omitempty"`
+	DroppedThirdStrike     float64              `json:"dropped_third_strike,omitempty"`
+	FirstInning            string               `json:"first_inning,omitempty"`
+	Steals                 baseball.StealModel  `json:"steals"`
+	ExtraInningRunner      int                  `json:"extra_inning_runner,omitempty"`
+	ExtraInningHalves      string               `json:"extra_inning_runner_halves,omitempty"`
+	MaxExtraInnings        int                  `json:"max_extra_innings"`
+	Park                   baseball.ParkFactors `json:"park"`
+	Model                  baseball.Model       `json:"model"`
+	PitcherHand            string               `json:"pitcher_hand,omitempty"`
+	PitcherHandByInning    []string             `json:"pitcher_hand_by_inning,omitempty"`
+	Platoon                bool                 `json:"platoon,omitempty"`
+	PinchHits              string               `json:"pinch_hits,omitempty"`
+	Backups                string               `json:"backups,omitempty"`
+	DoubleSwitches         string               `json:"double_switches,omitempty"`
+	PinchRunners           string               `json:"pinch_runners,omitempty"`
+	PinchRunSpeed          float64              `json:"pinch_run_speed,omitempty"`
+	Only                   string               `json:"only,omitempty"`
+	Fix                    string               `json:"fix,omitempty"`
+	RecentWeight           float64              `json:"recent_weight,omitempty"`
+	WildPitchRate          float64              `json:"wild_pitch_rate,omitempty"`
+	WPScoreFromThird       float64              `json:"wp_score_from_third,omitempty"`
+	RunEnvironment         float64              `json:"run_environment,omitempty"`
+	MinWalkRate            float64              `json:"min_walk_rate,omitempty"`
+	ShrinkPA               float64              `json:"shrink_pa,omitempty"`
+	OpponentDefense        float64              `json:"opponent_defense,omitempty"`
+	ShrinkTo               *baseball.Stats      `json:"shrink_to,omitempty"`
+	ClusterPenalty         float64              `json:"cluster_penalty,omitempty"`
+	ClusterOBP             float64              `json:"cluster_obp,omitempty"`
+	LHPShare               float64              `json:"lhp_share,omitempty"`
+
+	PlayersFile   string `json:"players_file"`
+	PlayersSHA256 string `json:"players_sha256"`
+	OpponentFile  string `json:"opponent_file,omitempty"`
+	OpponentHash  string `json:"opponent_sha256,omitempty"`
+	State         string `json:"state,omitempty"`
+	SeasonFile    string `json:"season_file,omitempty"`
+	SeasonHash    string `json:"season_sha256,omitempty"`
+}
+
+// newRunConfig captures cfg and the command-line settings of this run.
+func newRunConfig(cfg baseball.GameConfig) *runConfig {
+	rc := &runConfig{
+		Seed:                   baseSeed(),
+		LineupSeed:             *lineupSeed,
+		SeedMode:               *seedMode,
+		Games:                  *games,
+		Warmup:                 *warmup,
+		Innings:                9,
+		LineupSize:             *lineupSize,
+		Objective:              "mean",
+		OutsPerInning:          cfg.OutsPerInning,
+		MaxPitcherChanges:      cfg.MaxPitcherChanges,
+		GIDPRate:               cfg.GIDPRate,
+		HBPShare:               cfg.HBPShare,
+		ScoreFromThirdOnSingle: cfg.ScoreFromThirdOnSingle,
+		ScoreFromFirstOnSingle: cfg.ScoreFromFirstOnSingle,
+		SecondOnDouble:         cfg.ScoreFromSecondOnDouble,
+		OutStretching:          cfg.OutStretching,
+		ExtraBaseOnThrow:       cfg.ExtraBaseOnThrow,
+		HomeFieldFactor:        cfg.HomeFieldFactor,
+		InfieldIn:              cfg.InfieldIn.Enabled,
+		IntentionalWalks:       cfg.IntentionalWalk.Enabled,
+		ProductiveOutRate:      cfg.ProductiveOutRate,
+		SecondToThirdOnOut:     cfg.SecondToThirdOnOut,
+		DroppedThirdStrike:     cfg.DroppedThirdStrike,
+		FirstInning:            *firstInning,
+		Steals:                 cfg.Steals,
+		ExtraInningRunner:      cfg.ExtraInningRunner,
+		MaxExtraInnings:        cfg.MaxExtraInnings,
+		Park:                   cfg.Park,
+		Model:                  cfg.Model,
+		PitcherHand:            cfg.PitcherHand,
+		PitcherHandByInning:    cfg.PitcherHandByInning,
+		Platoon:                *platoon,
+		PinchHits:              *pinchHitSpec,
+		Backups:                *backupSpec,
+		DoubleSwitches:         *doubleSwitches,
+		PinchRunners:           *pinchRunSpec,
+		PinchRunSpeed:          cfg.PinchRunning.MaxSpeed,
+		Only:                   *onlyPlayers,
+		Fix:                    *fixSpec,
+		RecentWeight:           cfg.RecentWeight,
+		RunEnvironment:         cfg.RunEnvironment,
+		MinWalkRate:            cfg.MinWalkRate,
+		ShrinkPA:               cfg.ShrinkPA,
+		OpponentDefense:        cfg.OpponentDefense,
+		ShrinkTo:               cfg.ShrinkTo,
+		PlayersFile:            *playersPath,
+		PlayersSHA256:          fileSHA256(*playersPath),
+	}
+	if *platoon {
+		rc.LHPShare = *lhpShare
+	}
+	if cfg.WildPitchRate > 0 {
+		rc.WildPitchRate, rc.WPScoreFromThird = cfg.WildPitchRate, cfg.ScoreFromThirdOnWildPitch
+	}
+	if *clusterRuns > 0 {
+		rc.ClusterPenalty, rc.ClusterOBP = *clusterRuns, *clusterOBP
+	}
+	if cfg.ExtraInningRunner != 0 {
+		rc.ExtraInningHalves = cfg.ExtraInningRunnerHalves
+	}
+	if *opponentPath != "" {
+		rc.Objective = "win_pct"
+		rc.OpponentFile, rc.OpponentHash = *opponentPath, fileSHA256(*opponentPath)
+	}
+	rc.State = *gameState
+	if *seasonPath != "" {
+		rc.SeasonFile, rc.SeasonHash = *seasonPath, fileSHA256(*seasonPath)
+	}
+	return rc
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path, or "" if it can't
+// be read.
+func fileSHA256(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// configDiff lists, by JSON name, the settings that differ between a and b,
+// ignoring the seed, and separately the inputs: the files read and their
+// hashes. Inputs change with every projections update, while a settings
+// difference means the runs were set up differently.
+func configDiff(a, b *runConfig) (settings, inputs []string) {
+	flat := func(rc *runConfig) map[string]string {
+		data, _ := json.Marshal(rc)
+		var m map[string]json.RawMessage
+		json.Unmarshal(data, &m)
+		out := make(map[string]string, len(m))
+		for k, v := range m {
+			out[k] = string(v)
+		}
+		delete(out, "seed")
+		return out
+	}
+	fa, fb := flat(a), flat(b)
+	add := func(k string) {
+		if strings.HasSuffix(k, "_file") || strings.HasSuffix(k, "_sha256") {
+			inputs = append(inputs, k)
+		} else {
+			settings = append(settings, k)
+		}
+	}
+	for k, v := range fa {
+		if fb[k] != v {
+			add(k)
+		}
+	}
+	for k := range fb {
+		if _, ok := fa[k]; !ok {
+			add(k)
+		}
+	}
+	sort.Strings(settings)
+	sort.Strings(inputs)
+	return settings, inputs
+}
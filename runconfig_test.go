@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestRunConfigReflectsFlags(t *testing.T) {
+	withInt64(t, seed, 99)
+	withInt(t, games, 1234)
+	withInt(t, lineupSize, 8)
+	withBool(t, platoon, true)
+	withFloat(t, lhpShare, 0.4)
+	cfg := baseball.DefaultGameConfig()
+	cfg.GIDPRate = 0.05
+	cfg.WildPitchRate = 0.02
+
+	data, err := json.Marshal(newRunConfig(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{
+		"seed":            99.0,
+		"games":           1234.0,
+		"lineup_size":     8.0,
+		"platoon":         true,
+		"lhp_share":       0.4,
+		"gidp_rate":       0.05,
+		"wild_pitch_rate": 0.02,
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %v", key, got[key], want)
+		}
+	}
+	if _, ok := got["cluster_penalty"]; ok {
+		t.Error("cluster_penalty written without -cluster-runs")
+	}
+}
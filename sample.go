@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math/bits"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// feistel is a keyed pseudorandom permutation of [0, n): a balanced Feistel
+// network over the smallest even number of bits holding n-1, cycle-walked
+// until the value lands back under n. It permutes any index space without
+// materializing it, so -sample can draw from billions of lineups without
+// replacement.
+type feistel struct {
+	n    uint64
+	half uint // bits in each half
+	key  uint64
+}
+
+// feistelRounds is enough rounds for the walk to look random; -sample needs
+// spread, not cryptographic strength.
+const feistelRounds = 4
+
+func newFeistel(n uint64, key int64) feistel {
+	b := uint(bits.Len64(n - 1))
+	if n <= 1 {
+		b = 0
+	}
+	return feistel{n: n, half: (b + 1) / 2, key: uint64(key)}
+}
+
+// at returns the ith value of the permutation, for i in [0, n).
+func (f feistel) at(i uint64) uint64 {
+	x := f.encrypt(i)
+	// The domain is under four times n, so this walk is short.
+	for x >= f.n {
+		x = f.encrypt(x)
+	}
+	return x
+}
+
+func (f feistel) encrypt(x uint64) uint64 {
+	mask := uint64(1)<<f.half - 1
+	l, r := x>>f.half, x&mask
+	for round := uint64(0); round < feistelRounds; round++ {
+		l, r = r, l^(mix64(r^f.key^round*0x9e3779b97f4a7c15)&mask)
+	}
+	return l<<f.half | r
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(z uint64) uint64 {
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// unrankLineup returns the order of k of pool's entries numbered i, for i
+// in [0, n!/(n-k)!) where n is len(pool): each slot in turn takes one of
+// the entries not yet used, i read as a mixed-radix number choosing them.
+func unrankLineup(i uint64, pool []int, k int) []int {
+	left := append([]int(nil), pool...)
+	order := make([]int, k)
+	for j := range order {
+		m := uint64(len(left))
+		d := i % m
+		i /= m
+		order[j] = left[d]
+		left = append(left[:d], left[d+1:]...)
+	}
+	return order
+}
+
+// sampleLineups calls yield with up to s.sample distinct lineups drawn
+// without replacement from the whole search space, in an order fixed by
+// key, stopping early when yield returns false. Draws dropped by -positions
+// or -prefilter don't count toward the sample, so the search simulates
+// s.sample lineups whenever the space has them.
+func (s *search) sampleLineups(key int64, yield func(lineup []baseball.Player) bool) {
+	pool := s.pool()
+	space := uint64(s.space())
+	perm := newFeistel(space, key)
+	var taken uint64
+	for i := uint64(0); i < space && taken < s.sample; i++ {
+		lineup := s.lineupOf(s.place(unrankLineup(perm.at(i), pool, s.free())))
+		s.combos++
+		if *positions && !baseball.ValidAlignment(lineup) {
+			s.rejected++
+			continue
+		}
+		if s.hcorr != nil && s.heuristic(lineup) < s.heuristicFloor {
+			s.pruned++
+			continue
+		}
+		if !yield(lineup) {
+			return
+		}
+		taken++
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestFeistelPermutes(t *testing.T) {
+	for _, n := range []uint64{1, 2, 7, 100, 1000} {
+		f := newFeistel(n, 42)
+		seen := make(map[uint64]bool, n)
+		for i := uint64(0); i < n; i++ {
+			x := f.at(i)
+			if x >= n || seen[x] {
+				t.Fatalf("n=%d: index %d maps to %d, out of range or repeated", n, i, x)
+			}
+			seen[x] = true
+		}
+	}
+}
+
+func TestSampleNeverRepeatsALineup(t *testing.T) {
+	withInt(t, lineupSize, 4)
+	for _, n := range []int{200, 360} { // 360 is every order of 4 of 6
+		withInt(t, sampleSize, n)
+		s := newSearch(testRoster(6), nil, baseball.DefaultGameConfig(), 1, nil)
+		seen := map[uint64]bool{}
+		s.sampleLineups(7, func(lineup []baseball.Player) bool {
+			h := lineupHash(lineup)
+			if seen[h] {
+				t.Fatalf("sample of %d drew %x twice", n, h)
+			}
+			seen[h] = true
+			return true
+		})
+		if len(seen) != n {
+			t.Errorf("sample of %d drew %d lineups", n, len(seen))
+		}
+	}
+}
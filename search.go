@@ -0,0 +1,597 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// search is one exhaustive lineup search over a roster. Each search keeps its
+// own heaps and set summaries, so several can run at once in -players-dir
+// mode.
+type search struct {
+	players  []baseball.Player
+	opponent []baseball.Player
+	cfg      baseball.GameConfig
+	games    int
+	sinks    []ResultSink
+	// schedule lists the opposing starter for each game in -season mode,
+	// cycled when there are more games than entries.
+	schedule []baseball.Pitcher
+	// dump, when set, receives every evaluated lineup (-dump-all), under dmu.
+	dump ResultSink
+	dmu  sync.Mutex
+	// table, when set, collects every evaluated lineup's row for -format
+	// parquet.
+	table *lineupTable
+	// inFlight is the most lineups generated but not yet evaluated.
+	inFlight int
+	// fixed is the index of the player fixed in slot fixedSlot (0-based),
+	// or -1: the -leadoff-obp hitter or the -fix player.
+	fixed, fixedSlot int
+	// heuristicFloor is the heuristic score a lineup needs to be simulated
+	// under -prefilter; pruned counts those skipped and hcorr tracks how
+	// well the heuristic predicted the rest. slotWeights is -slot-weights.
+	heuristicFloor float64
+	slotWeights    []float64
+	pruned         uint64
+	hcorr          *heuristicCorr
+	// sample, when positive, is how many distinct lineups -sample draws
+	// instead of searching them all.
+	sample uint64
+	// state, when set, resumes every -opponent game from it (-state).
+	state *baseball.GameState
+
+	hmu    sync.Mutex
+	top    resultHeap
+	bmu    sync.Mutex
+	bottom maxResultHeap
+	smu    sync.Mutex
+	sets   map[uint64]*setAgg
+	// consistent keeps the lowest-stddev lineups with a mean of at least
+	// -consistent, under cmu.
+	cmu        sync.Mutex
+	consistent stdDevHeap
+
+	// topFloor and bottomCeil hold the Float64bits of the score a lineup
+	// must beat to enter each full heap, so workers can skip the lock for
+	// the common case of a lineup that won't make either list.
+	topFloor, bottomCeil uint64
+
+	// explained collects the lineups matching explainIDs, from -explain or
+	// -explain-diff, under smu.
+	explainIDs []string
+	explained  []lineupResult
+
+	// abort is closed to stop the search early; err says why.
+	abort     chan struct{}
+	abortOnce sync.Once
+	err       error
+	// panics counts lineups skipped under -on-panic skip.
+	panics uint64
+
+	// count is the number of lineups simulated so far, read atomically by
+	// the progress reporter.
+	count uint64
+	// combos and rejected count player combinations seen and dropped by the
+	// -positions filter; read them after run returns.
+	combos, rejected uint64
+}
+
+func newSearch(players, opponent []baseball.Player, cfg baseball.GameConfig, games int, sinks []ResultSink) *search {
+	return &search{
+		players:        players,
+		opponent:       opponent,
+		cfg:            cfg,
+		games:          games,
+		sinks:          sinks,
+		fixed:          -1,
+		heuristicFloor: math.Inf(-1),
+		inFlight:       *inFlight,
+		sample:         uint64(*sampleSize),
+		explainIDs:     explainIDs(),
+		sets:           map[uint64]*setAgg{},
+		abort:          make(chan struct{}),
+
+		topFloor:   math.Float64bits(math.Inf(-1)),
+		bottomCeil: math.Float64bits(math.Inf(1)),
+	}
+}
+
+// bestOBP returns the index of the player with the highest on-base
+// percentage, weighting the LHP split by lhpShare.
+func bestOBP(players []baseball.Player, lhpShare float64) int {
+	best, bestOBP := -1, -1.0
+	for i, p := range players {
+		obp := lhpShare*p.LHP.OBP + (1-lhpShare)*p.RHP.OBP
+		if obp > bestOBP {
+			best, bestOBP = i, obp
+		}
+	}
+	return best
+}
+
+// lineupCount is how many lineups the search will simulate before any
+// -positions filtering: the whole space, or at most -sample of it.
+func (s *search) lineupCount() float64 {
+	if s.sample > 0 {
+		return math.Min(float64(s.sample), s.space())
+	}
+	return s.space()
+}
+
+// space is how many lineups the roster can make around any fixed player.
+func (s *search) space() float64 {
+	if s.fixed >= 0 {
+		return lineupSpace(len(s.players)-1, *lineupSize-1)
+	}
+	return lineupSpace(len(s.players), *lineupSize)
+}
+
+// pool lists the roster indices of the players free to move: all but the
+// fixed player.
+func (s *search) pool() []int {
+	var pool []int
+	for i := range s.players {
+		if i != s.fixed {
+			pool = append(pool, i)
+		}
+	}
+	return pool
+}
+
+// free is how many slots the search fills from pool.
+func (s *search) free() int {
+	if s.fixed >= 0 {
+		return *lineupSize - 1
+	}
+	return *lineupSize
+}
+
+// place returns the lineup, as roster indices, that bats order in the free
+// slots around the fixed player.
+func (s *search) place(order []int) []int {
+	if s.fixed < 0 {
+		return append([]int(nil), order...)
+	}
+	idx := make([]int, 0, len(order)+1)
+	idx = append(idx, order[:s.fixedSlot]...)
+	idx = append(idx, s.fixed)
+	return append(idx, order[s.fixedSlot:]...)
+}
+
+// unplace is the inverse of place: the free slots of lineup idx, in order.
+func (s *search) unplace(idx []int) []int {
+	if s.fixed < 0 {
+		return append([]int(nil), idx...)
+	}
+	order := make([]int, 0, len(idx)-1)
+	order = append(order, idx[:s.fixedSlot]...)
+	return append(order, idx[s.fixedSlot+1:]...)
+}
+
+// run simulates every lineup with the given number of workers and returns
+// when all of them are done. A panic while simulating a lineup is logged and,
+// under -on-panic abort, stops the search and is returned as an error.
+func (s *search) run(workers int) error {
+	// slots caps the lineups generated but not yet evaluated, whether
+	// queued or in a worker's hands, so the generator blocks instead of
+	// allocating ahead when workers fall behind.
+	slots := make(chan struct{}, s.inFlight)
+	lineupCh := make(chan []baseball.Player, s.inFlight)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(workerID int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(baseSeed() + int64(workerID)*9973))
+			for lineup := range lineupCh {
+				if err := s.evaluateSafely(lineup, r); err != nil {
+					if *onPanic == "skip" {
+						log.Printf("Skipping lineup: %v", err)
+						atomic.AddUint64(&s.panics, 1)
+					} else {
+						s.stop(err)
+					}
+				}
+				atomic.AddUint64(&s.count, 1)
+				<-slots
+			}
+		}(w)
+	}
+
+	send := func(lineup []baseball.Player) bool {
+		select {
+		case slots <- struct{}{}:
+		case <-s.abort:
+			return false
+		}
+		select {
+		case lineupCh <- lineup:
+			return true
+		case <-s.abort:
+			return false
+		}
+	}
+	if s.sample > 0 {
+		key := baseSeed()
+		go func() {
+			s.sampleLineups(key, send)
+			close(lineupCh)
+		}()
+		wg.Wait()
+		return s.err
+	}
+
+	// Loop over all possible -lineup-size lineups (generator feeding
+	// workers). A fixed player keeps their slot in every lineup and only the
+	// other slots are drawn from the rest of the roster.
+	pool := s.pool()
+	go func() {
+		combinations(len(pool), s.free(), func(ci []int) bool {
+			s.combos++
+			idx := make([]int, 0, len(ci))
+			for _, c := range ci {
+				idx = append(idx, pool[c])
+			}
+			if *positions && !baseball.ValidAlignment(s.lineupOf(s.place(idx))) {
+				s.rejected++
+				return true
+			}
+			permutations(idx, func(order []int) bool {
+				lineup := s.lineupOf(s.place(order))
+				if s.hcorr != nil && s.heuristic(lineup) < s.heuristicFloor {
+					s.pruned++
+					return true
+				}
+				return send(lineup)
+			})
+			return !s.stopped()
+		})
+		close(lineupCh)
+	}()
+	wg.Wait()
+	return s.err
+}
+
+// stop ends the search early with err; only the first call has effect.
+func (s *search) stop(err error) {
+	s.abortOnce.Do(func() {
+		s.err = err
+		close(s.abort)
+	})
+}
+
+func (s *search) stopped() bool {
+	select {
+	case <-s.abort:
+		return true
+	default:
+		return false
+	}
+}
+
+// evaluateSafely is evaluate with a panic turned into an error naming the
+// lineup.
+func (s *search) evaluateSafely(lineup []baseball.Player, r *rand.Rand) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			names := make([]string, len(lineup))
+			for i := range lineup {
+				names[i] = lineup[i].LastName
+			}
+			err = fmt.Errorf("panic simulating %v: %v", names, p)
+		}
+	}()
+	if s.stopped() {
+		return nil
+	}
+	s.evaluate(lineup, r)
+	return nil
+}
+
+// evaluate simulates one lineup and folds the result into the search.
+func (s *search) evaluate(lineup []baseball.Player, r *rand.Rand) {
+	// Compute unique key for this ordered lineup
+	hash := lineupHash(lineup)
+	orderNames := make([]string, len(lineup))
+	for i := range lineup {
+		orderNames[i] = lineup[i].LastName
+	}
+	if *lineupSeed {
+		r.Seed(lineupRandSeed(hash))
+	}
+	// -warmup games are played and thrown away, moving the stream on before
+	// the games that count.
+	for g := 0; g < *warmup; g++ {
+		baseball.SimulateGame(lineup, s.cfg, r)
+	}
+	var tally runTally
+	var runsSum, hitsSum, paSum, outsSum, walksSum int64
+	var hist runsHistogram
+	add := func(runs int) {
+		tally.Add(runs)
+		if keepHistogram() {
+			hist.add(runs)
+		}
+	}
+	play := func(cfg baseball.GameConfig) float64 {
+		var sum int64
+		for g := 0; g < s.games; g++ {
+			game := baseball.SimulateGame(lineup, cfg, r)
+			add(game.Runs)
+			sum += int64(game.Runs)
+			hitsSum += int64(game.Hits)
+			paSum += int64(game.PA)
+			outsSum += int64(game.TotalOuts)
+			walksSum += int64(game.Walks + game.HBP)
+		}
+		runsSum += sum
+		return float64(sum) / float64(s.games)
+	}
+
+	res := lineupResult{Order: orderNames, Hash: hash, lineup: lineup}
+	switch {
+	case *platoon:
+		lhpCfg, rhpCfg := s.cfg, s.cfg
+		lhpCfg.PitcherHand, rhpCfg.PitcherHand = "left", "right"
+		res.LHPMean = play(lhpCfg)
+		res.RHPMean = play(rhpCfg)
+		res.Mean = *lhpShare*res.LHPMean + (1-*lhpShare)*res.RHPMean
+	case s.opponent != nil:
+		var wins, ties, diff int64
+		for g := 0; g < s.games; g++ {
+			var starter *baseball.Pitcher
+			if len(s.schedule) > 0 {
+				starter = &s.schedule[g%len(s.schedule)]
+			}
+			var us, them baseball.Game
+			if s.state != nil {
+				us, them = playFrom(lineup, s.opponent, *s.state, starter, s.cfg, r)
+			} else {
+				us, them = playMatchup(lineup, s.opponent, g%2 == 0, starter, s.cfg, r)
+			}
+			add(us.Runs)
+			runsSum += int64(us.Runs)
+			hitsSum += int64(us.Hits)
+			paSum += int64(us.PA)
+			outsSum += int64(us.TotalOuts)
+			walksSum += int64(us.Walks + us.HBP)
+			diff += int64(us.Runs - them.Runs)
+			switch {
+			case us.Runs > them.Runs:
+				wins++
+			case us.Runs == them.Runs:
+				ties++
+			}
+		}
+		res.Mean = tally.Mean()
+		res.Wins, res.Ties = int(wins), int(ties)
+		res.WinPct = float64(wins) / float64(s.games)
+		res.RunDiff = float64(diff) / float64(s.games)
+	default:
+		res.Mean = play(s.cfg)
+	}
+	res.Score = res.Mean - clusterPenalty(lineup, s.cfg)
+	if s.opponent != nil {
+		res.Score = res.WinPct
+	}
+	res.tally, res.hist = tally, hist
+	res.runs, res.pa = runsSum, paSum
+	res.summarize()
+
+	if s.hcorr != nil {
+		s.hcorr.add(s.heuristic(lineup), res.Mean)
+	}
+	s.offerTop(res)
+	s.offerBottom(res)
+	if *consistentMin > 0 && res.Mean >= *consistentMin {
+		s.offerConsistent(res)
+	}
+	if s.table != nil {
+		s.table.add(tableRow{Hash: hash, Order: orderNames, Games: tally.N, Runs: runsSum, Hits: hitsSum, Mean: res.Mean, StdDev: res.StdDev})
+	}
+	if s.dump != nil {
+		s.dmu.Lock()
+		if err := s.dump.Record(res); err != nil {
+			log.Printf("dump: %v", err)
+		}
+		s.dmu.Unlock()
+	}
+
+	for _, id := range s.explainIDs {
+		if matchesID(hash, id) {
+			s.smu.Lock()
+			s.explained = append(s.explained, res)
+			s.smu.Unlock()
+			break
+		}
+	}
+
+	if *rankSets != "" {
+		s.recordSet(lineup, res)
+	}
+
+	// Update global aggregates once per lineup
+	val, _ := lineupStats.LoadOrStore(hash, &Agg{})
+	agg := val.(*Agg)
+	agg.add(tally.N, runsSum, hitsSum, paSum, outsSum, walksSum)
+}
+
+// keepHistogram reports whether a lineup's games need keeping run by run,
+// for -floor-ceiling, -median-mode or -tiebreak.
+func keepHistogram() bool {
+	return *floorCeiling || *medianMode || tiebreaker.Kind != ""
+}
+
+// summarize fills in res's game count, spread, range, center, tiebreak
+// value and runs per PA from the games in its tally, histogram and totals.
+// Mean and Score depend on the mode, so the caller sets those.
+func (res *lineupResult) summarize() {
+	res.Games = int(res.tally.N)
+	res.StdDev = res.tally.StdDev()
+	if *floorCeiling {
+		res.Range = &runRange{Floor: res.hist.percentile(10), Ceiling: res.hist.percentile(90)}
+	}
+	if *medianMode {
+		res.Center = &runCenter{Median: res.hist.median(), Mode: res.hist.mode()}
+	}
+	res.Tiebreak = tiebreaker.value(res.hist, res.tally)
+	if res.pa > 0 {
+		res.RunsPerPA = float64(res.runs) / float64(res.pa)
+	}
+}
+
+// offerTop pushes res onto the top-K heap if it ranks above the weakest kept
+// result, per ranksAbove.
+func (s *search) offerTop(res lineupResult) {
+	// The floor only rises, so a stale read can let a loser through to the
+	// locked check but never drop a winner. A score equal to the floor may
+	// still win its tie on hash, and one within -tiebreak's epsilon below it
+	// on the tiebreak, so only lower ones than that are skipped.
+	if res.Score < math.Float64frombits(atomic.LoadUint64(&s.topFloor))-tiebreaker.slack() {
+		return
+	}
+	s.hmu.Lock()
+	defer s.hmu.Unlock()
+	if len(s.top) < topK {
+		heap.Push(&s.top, res)
+	} else if ranksAbove(res, s.top[0]) {
+		heap.Pop(&s.top)
+		heap.Push(&s.top, res)
+	} else {
+		return
+	}
+	if len(s.top) == topK {
+		atomic.StoreUint64(&s.topFloor, math.Float64bits(s.top[0].Score))
+	}
+	recordAll(s.sinks, res)
+}
+
+// offerBottom pushes res onto the bottom-K heap if it ranks below the best
+// kept result, per ranksAbove.
+func (s *search) offerBottom(res lineupResult) {
+	if res.Score > math.Float64frombits(atomic.LoadUint64(&s.bottomCeil))+tiebreaker.slack() {
+		return
+	}
+	s.bmu.Lock()
+	defer s.bmu.Unlock()
+	if len(s.bottom) < bottomK {
+		heap.Push(&s.bottom, res)
+	} else if ranksAbove(s.bottom[0], res) {
+		heap.Pop(&s.bottom)
+		heap.Push(&s.bottom, res)
+	}
+	if len(s.bottom) == bottomK {
+		atomic.StoreUint64(&s.bottomCeil, math.Float64bits(s.bottom[0].Score))
+	}
+}
+
+// offerConsistent keeps res if it's steadier than the least consistent
+// kept result.
+func (s *search) offerConsistent(res lineupResult) {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	if len(s.consistent) < consistentK {
+		heap.Push(&s.consistent, res)
+	} else if h := s.consistent; res.StdDev < h[0].StdDev || (res.StdDev == h[0].StdDev && ranksAbove(res, h[0])) {
+		heap.Pop(&s.consistent)
+		heap.Push(&s.consistent, res)
+	}
+}
+
+// consistentResults returns the kept -consistent lineups, steadiest first.
+func (s *search) consistentResults() []lineupResult {
+	s.cmu.Lock()
+	results := append([]lineupResult(nil), s.consistent...)
+	s.cmu.Unlock()
+	h := stdDevHeap(results)
+	sort.Slice(results, func(i, j int) bool { return h.Less(j, i) })
+	return results
+}
+
+// recordSet folds one ordering's result into its player set's summary.
+func (s *search) recordSet(lineup []baseball.Player, res lineupResult) {
+	key := lineupSetHash(lineup)
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	a, ok := s.sets[key]
+	if !ok {
+		names := append([]string(nil), res.Order...)
+		sort.Strings(names)
+		a = &setAgg{Hash: key, Players: names, BestMean: math.Inf(-1)}
+		s.sets[key] = a
+	}
+	a.Orders++
+	a.SumMean += res.Mean
+	if res.Mean > a.BestMean {
+		a.BestMean = res.Mean
+		a.BestOrder = res.Order
+	}
+}
+
+// topResults returns the top-K lineups, best first.
+func (s *search) topResults() []lineupResult {
+	s.hmu.Lock()
+	results := make([]lineupResult, len(s.top))
+	copy(results, s.top)
+	s.hmu.Unlock()
+	sort.Slice(results, func(i, j int) bool { return ranksAbove(results[i], results[j]) })
+	return results
+}
+
+// bottomResults returns the bottom-K lineups, worst first.
+func (s *search) bottomResults() []lineupResult {
+	s.bmu.Lock()
+	results := make([]lineupResult, len(s.bottom))
+	copy(results, s.bottom)
+	s.bmu.Unlock()
+	sort.Slice(results, func(i, j int) bool { return ranksAbove(results[j], results[i]) })
+	return results
+}
+
+// rankedSets returns up to topK player sets ordered by their best or average
+// ordering mean, per by.
+func (s *search) rankedSets(by string) []*setAgg {
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	sets := make([]*setAgg, 0, len(s.sets))
+	for _, a := range s.sets {
+		sets = append(sets, a)
+	}
+	key := func(a *setAgg) float64 { return a.BestMean }
+	if by == "avg" {
+		key = (*setAgg).AvgMean
+	}
+	sort.Slice(sets, func(i, j int) bool { return key(sets[i]) > key(sets[j]) })
+	if len(sets) > topK {
+		sets = sets[:topK]
+	}
+	return sets
+}
+
+// explainedLineup returns the one searched lineup matching id, exiting with
+// an error when none or several do.
+func (s *search) explainedLineup(id string) lineupResult {
+	var found []lineupResult
+	for _, res := range s.explained {
+		if matchesID(res.Hash, id) {
+			found = append(found, res)
+		}
+	}
+	switch len(found) {
+	case 0:
+		fatalf("No lineup with ID %q was searched", id)
+	case 1:
+	default:
+		fatalf("ID %q matches %d lineups; give more of the hash", id, len(found))
+	}
+	return found[0]
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// means maps each lineup the search kept in its top K to its mean.
+func means(s *search) map[uint64]float64 {
+	m := make(map[uint64]float64)
+	for _, r := range s.topResults() {
+		m[r.Hash] = r.Mean
+	}
+	return m
+}
+
+func TestLineupSeedReproducesAcrossRuns(t *testing.T) {
+	withBool(t, lineupSeed, true)
+	players := testRoster(6)
+	cfg := baseball.DefaultGameConfig()
+	first := means(runSearch(t, players, 6, cfg, 30))
+
+	// A second run on more workers hands the lineups out differently.
+	s := newSearch(players, nil, cfg, 30, nil)
+	if err := s.run(7); err != nil {
+		t.Fatal(err)
+	}
+	second := means(s)
+	if len(first) != topK || len(second) != len(first) {
+		t.Fatalf("kept %d and %d lineups, want %d", len(first), len(second), topK)
+	}
+	for h, m := range first {
+		if second[h] != m {
+			t.Errorf("lineup %x: mean %v, then %v", h, m, second[h])
+		}
+	}
+}
+
+func TestPlatoonBlendsSplitMeans(t *testing.T) {
+	withBool(t, platoon, true)
+	withFloat(t, lhpShare, 0.3)
+	players := testRoster(6)
+	for i := range players {
+		// Strong against lefties, weak against righties.
+		players[i].RHP = baseball.Stats{AVG: 0.200, OBP: 0.260, SLUG: 0.300}
+	}
+	s := runSearch(t, players, 6, baseball.DefaultGameConfig(), 40)
+	for _, r := range s.topResults() {
+		if want := 0.3*r.LHPMean + 0.7*r.RHPMean; math.Abs(r.Mean-want) > 1e-12 {
+			t.Fatalf("lineup %s: mean %v, want 0.3*%v + 0.7*%v = %v", r.ID(), r.Mean, r.LHPMean, r.RHPMean, want)
+		}
+		if r.LHPMean <= r.RHPMean {
+			t.Errorf("lineup %s: %v runs against lefties, %v against righties", r.ID(), r.LHPMean, r.RHPMean)
+		}
+	}
+}
+
+func TestPositionsRejectsRosterWithoutCatcher(t *testing.T) {
+	withBool(t, positions, true)
+	var players []baseball.Player
+	for _, pos := range []string{"1B", "2B", "SS", "3B", "LF", "CF", "RF", "OF", "2B/SS", "1B"} {
+		p := testPlayer(pos, 0.330, 0.420)
+		p.LastName, p.Position = pos+string(rune('A'+len(players))), pos
+		players = append(players, p)
+	}
+	s := runSearch(t, players, 9, baseball.DefaultGameConfig(), 1)
+	if s.combos != 10 || s.rejected != s.combos {
+		t.Errorf("rejected %d of %d combinations, want all 10", s.rejected, s.combos)
+	}
+	if n := len(s.topResults()); n != 0 {
+		t.Errorf("%d lineups simulated without a catcher", n)
+	}
+}
+
+// scored returns n results with coarse, often tied scores and distinct
+// hashes.
+func scored(n int) []lineupResult {
+	r := rand.New(rand.NewSource(1))
+	results := make([]lineupResult, n)
+	for i := range results {
+		results[i] = lineupResult{Hash: r.Uint64(), Score: float64(r.Intn(300)) / 100}
+	}
+	return results
+}
+
+func TestOfferTopKeepsTheBest(t *testing.T) {
+	withInt(t, &topK, 25)
+	results := scored(5000)
+	s := newSearch(testRoster(5), nil, baseball.DefaultGameConfig(), 1, nil)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(results); i += 8 {
+				s.offerTop(results[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return ranksAbove(results[i], results[j]) })
+	got := s.topResults()
+	if len(got) != topK {
+		t.Fatalf("kept %d, want %d", len(got), topK)
+	}
+	for i := range got {
+		if got[i].Hash != results[i].Hash {
+			t.Errorf("#%d: kept %x (%v), want %x (%v)", i+1, got[i].Hash, got[i].Score, results[i].Hash, results[i].Score)
+		}
+	}
+}
+
+func BenchmarkOfferTop(b *testing.B) {
+	results := scored(1 << 16)
+	s := newSearch(testRoster(5), nil, baseball.DefaultGameConfig(), 1, nil)
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Int()
+		for pb.Next() {
+			s.offerTop(results[i&(len(results)-1)])
+			i++
+		}
+	})
+}
+
+func TestLeadoffOBPPinsBestOnBase(t *testing.T) {
+	players := testRoster(6)
+	players[4].LHP.OBP, players[4].RHP.OBP = 0.500, 0.300
+	players[5].LHP.OBP, players[5].RHP.OBP = 0.300, 0.450
+	if got := bestOBP(players, 0.3); got != 5 {
+		t.Errorf("mostly facing righties, picked %s", players[got].LastName)
+	}
+	if got := bestOBP(players, 0.8); got != 4 {
+		t.Errorf("mostly facing lefties, picked %s", players[got].LastName)
+	}
+
+	withInt(t, lineupSize, 6)
+	withInt64(t, seed, 1)
+	s := newSearch(players, nil, baseball.DefaultGameConfig(), 10, nil)
+	s.fixed = bestOBP(players, 0.3)
+	if err := s.run(2); err != nil {
+		t.Fatal(err)
+	}
+	results := s.topResults()
+	if len(results) != 120 {
+		t.Errorf("searched %d lineups, want the 120 orders of five behind the leadoff", len(results))
+	}
+	for _, r := range results {
+		if r.Order[0] != "P6" {
+			t.Errorf("lineup %v doesn't lead off with P6", r.Order)
+		}
+	}
+}
+
+func TestLineupRandSeedSeparatesNeighbours(t *testing.T) {
+	withInt64(t, seed, 7)
+	a := testRoster(9)
+	b := append([]baseball.Player(nil), a...)
+	b[7], b[8] = b[8], b[7] // the next order in the enumeration
+	draws := func(lineup []baseball.Player) []float64 {
+		r := rand.New(rand.NewSource(lineupRandSeed(lineupHash(lineup))))
+		d := make([]float64, 5)
+		for i := range d {
+			d[i] = r.Float64()
+		}
+		return d
+	}
+	da, db := draws(a), draws(b)
+	for i := range da {
+		if da[i] == db[i] {
+			t.Errorf("draw %d is %v for both neighbouring lineups", i, da[i])
+		}
+	}
+
+	again := draws(a)
+	for i := range da {
+		if again[i] != da[i] {
+			t.Fatalf("draw %d: %v, then %v with the same seed", i, da[i], again[i])
+		}
+	}
+	withInt64(t, seed, 8)
+	if lineupRandSeed(lineupHash(a)) == lineupRandSeed(lineupHash(b)) {
+		t.Error("neighbouring lineups share a seed")
+	}
+	if d := draws(a); d[0] == da[0] {
+		t.Error("-seed doesn't change the lineup's stream")
+	}
+}
+
+// generatorParked reports whether the search's lineup generator is blocked
+// waiting to hand over a lineup.
+func generatorParked() bool {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		header := strings.SplitN(g, "\n", 2)[0]
+		if strings.Contains(header, "[select") && strings.Contains(g, "battinglineup.combinations(") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInFlightBlocksTheGenerator(t *testing.T) {
+	withInt(t, inFlight, 2)
+	withInt(t, lineupSize, 6)
+	withInt64(t, seed, 1)
+	held, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	cfg := baseball.DefaultGameConfig()
+	cfg.Trace = func(baseball.Play) {
+		once.Do(func() {
+			close(held)
+			<-release
+		})
+	}
+	s := newSearch(testRoster(6), nil, cfg, 5, nil)
+	done := make(chan error)
+	go func() { done <- s.run(1) }()
+
+	<-held
+	deadline := time.Now().Add(5 * time.Second)
+	for !generatorParked() {
+		if time.Now().After(deadline) {
+			close(release)
+			t.Fatal("generator never blocked with the only worker held")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := atomic.LoadUint64(&s.count); n != 0 {
+		t.Errorf("%d lineups finished while the worker was held", n)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if s.count != 720 {
+		t.Errorf("simulated %d lineups after the worker was released, want 720", s.count)
+	}
+}
+
+func TestRunsPerPAFavorsFewerTrips(t *testing.T) {
+	var tally runTally
+	for _, runs := range []int{3, 5, 4, 6, 2} {
+		tally.Add(runs)
+	}
+	busy := lineupResult{tally: tally, runs: 20, pa: 200}
+	lean := lineupResult{tally: tally, runs: 20, pa: 180}
+	busy.summarize()
+	lean.summarize()
+	if busy.tally.Mean() != lean.tally.Mean() || busy.Games != 5 {
+		t.Fatalf("means %v and %v over %d games", busy.tally.Mean(), lean.tally.Mean(), busy.Games)
+	}
+	if busy.RunsPerPA != 0.1 || lean.RunsPerPA <= busy.RunsPerPA {
+		t.Errorf("runs per PA %.4f in 200 trips, %.4f in 180", busy.RunsPerPA, lean.RunsPerPA)
+	}
+}
+
+// hashesOf lists the hashes of results in order.
+func hashesOf(results []lineupResult) []uint64 {
+	h := make([]uint64, len(results))
+	for i, r := range results {
+		h[i] = r.Hash
+	}
+	return h
+}
+
+func TestTiesAtTheThresholdBreakOnHash(t *testing.T) {
+	withInt(t, &topK, 2)
+	withInt(t, &bottomK, 2)
+	for _, order := range [][]lineupResult{
+		{{Hash: 3, Score: 5}, {Hash: 5, Score: 4}, {Hash: 2, Score: 4}, {Hash: 9, Score: 4}},
+		{{Hash: 9, Score: 4}, {Hash: 2, Score: 4}, {Hash: 5, Score: 4}, {Hash: 3, Score: 5}},
+	} {
+		s := newSearch(testRoster(5), nil, baseball.DefaultGameConfig(), 1, nil)
+		for _, r := range order {
+			s.offerTop(r)
+			s.offerBottom(r)
+		}
+		// At score 4 the lower hash ranks higher: 2, then 5, then 9.
+		if got := fmt.Sprint(hashesOf(s.topResults())); got != "[3 2]" {
+			t.Errorf("offered %v: top %s, want [3 2]", hashesOf(order), got)
+		}
+		if got := fmt.Sprint(hashesOf(s.bottomResults())); got != "[9 5]" {
+			t.Errorf("offered %v: bottom %s, want [9 5]", hashesOf(order), got)
+		}
+	}
+}
+
+func TestConsistentPrefersTheSteadierLineup(t *testing.T) {
+	withFloat(t, consistentMin, 4)
+	wild := lineupResult{Hash: 1, Mean: 4.6, Score: 4.6, StdDev: 3.4}
+	steady := lineupResult{Hash: 2, Mean: 4.5, Score: 4.5, StdDev: 2.1}
+	s := newSearch(testRoster(5), nil, baseball.DefaultGameConfig(), 1, nil)
+	for _, res := range []lineupResult{wild, steady} {
+		s.offerTop(res)
+		s.offerConsistent(res)
+	}
+	if top := s.topResults(); top[0].Hash != wild.Hash {
+		t.Errorf("top list leads with %x, want the higher mean", top[0].Hash)
+	}
+	got := s.consistentResults()
+	if len(got) != 2 || got[0].Hash != steady.Hash || got[1].Hash != wild.Hash {
+		t.Errorf("consistent list %+v, want the steady lineup first", got)
+	}
+}
+
+func TestAggPAsReconcile(t *testing.T) {
+	clearLineupStats()
+	t.Cleanup(clearLineupStats)
+	cfg := baseball.DefaultGameConfig()
+	cfg.GIDPRate = 0 // every out is the batter's
+	s := runSearch(t, testRoster(6), 6, cfg, 100)
+
+	for _, res := range s.topResults() {
+		v, ok := lineupStats.Load(res.Hash)
+		if !ok {
+			t.Fatalf("no aggregate for %s", res.ID())
+		}
+		a := v.(*Agg)
+		if a.Outs != 27*a.Games {
+			t.Errorf("%s: %d outs in %d games", res.ID(), a.Outs, a.Games)
+		}
+		if a.PAs != a.Outs+a.Hits+a.Walks {
+			t.Errorf("%s: %d PAs, but %d outs + %d hits + %d walks", res.ID(), a.PAs, a.Outs, a.Hits, a.Walks)
+		}
+	}
+}
+
+func TestWarmupResultsIgnoreWorkerOrder(t *testing.T) {
+	withBool(t, lineupSeed, true)
+	withInt64(t, seed, 1)
+	withInt(t, lineupSize, 6)
+	players := testRoster(6)
+	cfg := baseball.DefaultGameConfig()
+	search := func(workers int) map[uint64]float64 {
+		s := newSearch(players, nil, cfg, 30, nil)
+		if err := s.run(workers); err != nil {
+			t.Fatal(err)
+		}
+		return means(s)
+	}
+	cold := search(2)
+
+	withInt(t, warmup, 20)
+	first, second := search(2), search(7)
+	changed := 0
+	for h, m := range first {
+		if second[h] != m {
+			t.Errorf("lineup %x: mean %v on 2 workers, %v on 7", h, m, second[h])
+		}
+		if cold[h] != m {
+			changed++
+		}
+	}
+	if changed == 0 {
+		t.Error("warmup games didn't move any lineup's games along")
+	}
+}
+
+func TestFixKeepsPlayerInSlot(t *testing.T) {
+	withInt(t, lineupSize, 6)
+	withInt64(t, seed, 1)
+	players := testRoster(7)
+	s := newSearch(players, nil, baseball.DefaultGameConfig(), 10, nil)
+	var err error
+	if s.fixed, s.fixedSlot, err = parseFix("6:p3", players); err != nil {
+		t.Fatal(err)
+	}
+	dump := &captureSink{}
+	s.dump = dump
+	if err := s.run(2); err != nil {
+		t.Fatal(err)
+	}
+	// The other six fill the first five slots: 6*5*4*3*2 orders.
+	if len(dump.results) != 720 {
+		t.Errorf("evaluated %d lineups, want 720", len(dump.results))
+	}
+	for _, r := range dump.results {
+		if r.Order[5] != "P3" {
+			t.Errorf("lineup %v doesn't bat P3 sixth", r.Order)
+		}
+	}
+}
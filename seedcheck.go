@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// seedCheck is the Monte Carlo error of the top lineup's mean, measured by
+// replaying it under independent seeds.
+type seedCheck struct {
+	Seeds int       `json:"seeds"`
+	Means []float64 `json:"means"`
+	// StdErr is the standard deviation of the per-seed means: how far one
+	// run's reported mean typically lands from the truth.
+	StdErr float64 `json:"stderr"`
+	// RunnerUpStdErr is the same for #2, and GapStdErr the standard error
+	// of the difference of the two means, √(StdErr²+RunnerUpStdErr²).
+	RunnerUpStdErr float64 `json:"runner_up_stderr"`
+	GapStdErr      float64 `json:"gap_stderr"`
+	// Gap is the search's margin of #1 over #2. Significant is false when
+	// it's within two standard errors of the difference, so a rerun could
+	// swap them.
+	Gap         float64 `json:"gap"`
+	Significant bool    `json:"significant"`
+}
+
+// checkSeeds replays top and second for games games under each of seeds
+// independent seeds and compares gap, top's lead, with the spread of the
+// difference of their means.
+func checkSeeds(top, second []baseball.Player, gap float64, cfg baseball.GameConfig, games, seeds int, base int64) seedCheck {
+	c := seedCheck{Seeds: seeds, Gap: gap}
+	c.Means = seedMeans(top, cfg, games, seeds, base)
+	_, c.StdErr = meanSpread(c.Means)
+	_, c.RunnerUpStdErr = meanSpread(seedMeans(second, cfg, games, seeds, base))
+	c.GapStdErr = math.Hypot(c.StdErr, c.RunnerUpStdErr)
+	c.Significant = gap > 2*c.GapStdErr
+	return c
+}
+
+// seedMeans plays lineup for games games under each of seeds independent
+// seeds derived from base and returns the means.
+func seedMeans(lineup []baseball.Player, cfg baseball.GameConfig, games, seeds int, base int64) []float64 {
+	means := make([]float64, seeds)
+	for i := range means {
+		means[i] = EvaluateLineup(lineup, cfg, games, base+int64(i+1)*7919).Mean
+	}
+	return means
+}
+
+// meanSpread returns the average of means and their sample standard
+// deviation, zero for fewer than two.
+func meanSpread(means []float64) (avg, sd float64) {
+	for _, m := range means {
+		avg += m
+	}
+	avg /= float64(len(means))
+	if len(means) > 1 {
+		var ss float64
+		for _, m := range means {
+			ss += (m - avg) * (m - avg)
+		}
+		sd = math.Sqrt(ss / float64(len(means)-1))
+	}
+	return avg, sd
+}
+
+// finalist is a top lineup re-scored as the average of its means under
+// several independent seeds.
+type finalist struct {
+	ID         string   `json:"id"`
+	Order      []string `json:"order"`
+	SearchMean float64  `json:"search_mean"`
+	Mean       float64  `json:"mean"`
+	// StdErr is the standard error of Mean across the seeds: the per-seed
+	// means' standard deviation over the square root of their count.
+	StdErr float64 `json:"stderr"`
+}
+
+// rescoreFinalists replays each of results under seeds seeds and returns
+// them re-ranked by their cross-seed average, best first.
+func rescoreFinalists(results []lineupResult, cfg baseball.GameConfig, games, seeds int, base int64) []finalist {
+	out := make([]finalist, len(results))
+	var wg sync.WaitGroup
+	for i, res := range results {
+		wg.Add(1)
+		go func(i int, res lineupResult) {
+			defer wg.Done()
+			avg, sd := meanSpread(seedMeans(res.lineup, cfg, games, seeds, base))
+			out[i] = finalist{ID: res.ID(), Order: res.Order, SearchMean: res.Mean, Mean: avg, StdErr: sd / math.Sqrt(float64(seeds))}
+		}(i, res)
+	}
+	wg.Wait()
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Mean > out[j].Mean })
+	return out
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestCheckSeedsFlagsNearTies(t *testing.T) {
+	top := testRoster(9)
+	second := append([]baseball.Player(nil), top...)
+	second[7], second[8] = second[8], second[7]
+	cfg := baseball.DefaultGameConfig()
+
+	c := checkSeeds(top, second, 0.01, cfg, 200, 8, 1)
+	if c.Significant {
+		t.Errorf("a %.3f gap counted as significant against a standard error of %.3f", c.Gap, c.GapStdErr)
+	}
+	if len(c.Means) != 8 || c.StdErr <= 0 || c.GapStdErr < c.StdErr {
+		t.Errorf("%d means, stderr %.3f, gap stderr %.3f", len(c.Means), c.StdErr, c.GapStdErr)
+	}
+	if !checkSeeds(top, second, 2, cfg, 200, 8, 1).Significant {
+		t.Error("a two-run gap wasn't significant")
+	}
+}
+
+func TestMoreFinalistSeedsShrinkStdErr(t *testing.T) {
+	res := lineupResult{Hash: lineupHash(testRoster(9)), lineup: testRoster(9)}
+	cfg := baseball.DefaultGameConfig()
+	few := rescoreFinalists([]lineupResult{res}, cfg, 100, 4, 1)[0]
+	many := rescoreFinalists([]lineupResult{res}, cfg, 100, 40, 1)[0]
+	if many.StdErr >= few.StdErr {
+		t.Errorf("standard error %.4f over 40 seeds, %.4f over 4", many.StdErr, few.StdErr)
+	}
+	if few.ID != res.ID() || many.Mean <= 0 {
+		t.Errorf("finalists %+v and %+v", few, many)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestSeedModesReproduce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roster.json")
+	data, _ := json.Marshal(testRoster(6))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range []string{"lineup", "shared"} {
+		args := []string{"-players", path, "-lineup-size", "6", "-games", "30", "-seed", "3", "-seed-mode", mode, "-quiet"}
+		first, _ := runMain(t, args...)
+		second, _ := runMain(t, args...)
+		if first == "" || first != second {
+			t.Errorf("-seed-mode %s gave\n%s\nthen\n%s", mode, first, second)
+		}
+	}
+
+	// Worker streams reproduce when the same workers get the same lineups,
+	// which one worker guarantees.
+	withInt64(t, seed, 3)
+	withInt(t, lineupSize, 6)
+	players := testRoster(6)
+	cfg := baseball.DefaultGameConfig()
+	var runs [2]map[uint64]float64
+	for i := range runs {
+		s := newSearch(players, nil, cfg, 30, nil)
+		if err := s.run(1); err != nil {
+			t.Fatal(err)
+		}
+		runs[i] = means(s)
+	}
+	for h, m := range runs[0] {
+		if runs[1][h] != m {
+			t.Errorf("worker mode, lineup %x: mean %v, then %v", h, m, runs[1][h])
+		}
+	}
+}
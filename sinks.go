@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResultSink receives each lineup promoted into the top-K while the search
+// runs. Record is called with the search's heap lock held, so implementations
+// must not block for long. An error from Record is logged and the search carries on.
+type ResultSink interface {
+	Record(lineupResult) error
+	Close() error
+}
+
+// sinkFlags collects repeated -sink values.
+type sinkFlags []string
+
+func (s *sinkFlags) String() string     { return strings.Join(*s, ",") }
+func (s *sinkFlags) Set(v string) error { *s = append(*s, v); return nil }
+
+// openSink builds a sink from a -sink value: "stdout", "file:PATH", or an
+// http:// or https:// webhook URL.
+func openSink(spec string) (ResultSink, error) {
+	switch {
+	case spec == "stdout":
+		return newJSONLinesSink(os.Stdout, nil), nil
+	case strings.HasPrefix(spec, "file:"):
+		f, err := createOutput(strings.TrimPrefix(spec, "file:"))
+		if err != nil {
+			return nil, err
+		}
+		return newJSONLinesSink(f, f), nil
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return newWebhookSink(spec), nil
+	}
+	return nil, fmt.Errorf("unknown sink %q (want stdout, file:PATH or an http(s) URL)", spec)
+}
+
+// recordAll passes r to every sink, logging failures. Callers must hold the
+// search's heap lock.
+func recordAll(sinks []ResultSink, r lineupResult) {
+	for _, s := range sinks {
+		if err := s.Record(r); err != nil {
+			log.Printf("sink: %v", err)
+		}
+	}
+}
+
+// closeAll closes every sink, logging failures.
+func closeAll(sinks []ResultSink) {
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			log.Printf("sink: %v", err)
+		}
+	}
+}
+
+// jsonLinesSink writes each result as one JSON object per line.
+type jsonLinesSink struct {
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+func newJSONLinesSink(w io.Writer, closer io.Closer) *jsonLinesSink {
+	return &jsonLinesSink{enc: json.NewEncoder(w), closer: closer}
+}
+
+func (s *jsonLinesSink) Record(r lineupResult) error {
+	return s.enc.Encode(r)
+}
+
+func (s *jsonLinesSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// webhookSink POSTs each result as JSON to a URL. Requests are sent from a
+// background goroutine so a slow endpoint can't stall the search; results
+// arriving while the queue is full are dropped with a warning.
+type webhookSink struct {
+	url    string
+	client *http.Client
+	queue  chan lineupResult
+	wg     sync.WaitGroup
+}
+
+func newWebhookSink(url string) *webhookSink {
+	s := &webhookSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan lineupResult, 256),
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for r := range s.queue {
+			if err := s.post(r); err != nil {
+				log.Printf("sink: %v", err)
+			}
+		}
+	}()
+	return s
+}
+
+func (s *webhookSink) Record(r lineupResult) error {
+	select {
+	case s.queue <- r:
+		return nil
+	default:
+		return fmt.Errorf("webhook %s: queue full, dropped lineup %s", s.url, r.ID())
+	}
+}
+
+func (s *webhookSink) post(r lineupResult) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", s.url, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+// Close sends any queued results before returning.
+func (s *webhookSink) Close() error {
+	close(s.queue)
+	s.wg.Wait()
+	return nil
+}
+
+// bestSink forwards only results that beat every score it has forwarded so
+// far, and at most one per interval. A throttled result is held back until the
+// next forward or Close, so the output always ends on the true best.
+type bestSink struct {
+	next     ResultSink
+	interval time.Duration
+	best     float64
+	last     time.Time
+	pending  *lineupResult
+}
+
+func newBestSink(next ResultSink, interval time.Duration) *bestSink {
+	return &bestSink{next: next, interval: interval, best: math.Inf(-1)}
+}
+
+func (s *bestSink) Record(r lineupResult) error {
+	if r.Score <= s.best {
+		return nil
+	}
+	s.best = r.Score
+	if time.Since(s.last) < s.interval {
+		s.pending = &r
+		return nil
+	}
+	return s.forward(r)
+}
+
+func (s *bestSink) forward(r lineupResult) error {
+	s.pending = nil
+	s.last = time.Now()
+	return s.next.Record(r)
+}
+
+func (s *bestSink) Close() error {
+	if s.pending != nil {
+		if err := s.forward(*s.pending); err != nil {
+			log.Printf("sink: %v", err)
+		}
+	}
+	return s.next.Close()
+}
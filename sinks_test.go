@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestStreamWritesRisingBests(t *testing.T) {
+	var buf bytes.Buffer
+	sink := newBestSink(newJSONLinesSink(&buf, nil), 0)
+	s := runSearch(t, testRoster(6), 6, baseball.DefaultGameConfig(), 20, sink)
+	closeAll([]ResultSink{sink})
+
+	lines := 0
+	best := -1.0
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var r lineupResult
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("line %d isn't JSON: %v: %s", lines+1, err, sc.Bytes())
+		}
+		if r.Score <= best {
+			t.Errorf("line %d score %v doesn't beat %v", lines+1, r.Score, best)
+		}
+		best = r.Score
+		lines++
+	}
+	if lines == 0 {
+		t.Fatal("nothing was streamed")
+	}
+	if top := s.topResults()[0].Score; best != top {
+		t.Errorf("stream ended on %v, want the top score %v", best, top)
+	}
+}
+
+// captureSink keeps every result recorded to it.
+type captureSink struct {
+	results []lineupResult
+	closed  bool
+}
+
+func (c *captureSink) Record(r lineupResult) error {
+	c.results = append(c.results, r)
+	return nil
+}
+
+func (c *captureSink) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestSinkSeesEveryPromotion(t *testing.T) {
+	withInt(t, &topK, 10)
+	sink := &captureSink{}
+	s := runSearch(t, testRoster(6), 6, baseball.DefaultGameConfig(), 20, sink)
+	closeAll(s.sinks)
+	if !sink.closed {
+		t.Error("sink wasn't closed")
+	}
+	seen := map[uint64]bool{}
+	for _, r := range sink.results {
+		seen[r.Hash] = true
+	}
+	for _, r := range s.topResults() {
+		if !seen[r.Hash] {
+			t.Errorf("top lineup %s never reached the sink", r.ID())
+		}
+	}
+	// Lineups that were promoted and later pushed out were sent too.
+	if len(sink.results) <= topK {
+		t.Errorf("sink got %d results, want more than the %d kept", len(sink.results), topK)
+	}
+}
+
+func TestSkippedPanicsLeaveSinkClosed(t *testing.T) {
+	withString(t, onPanic, "skip")
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	path := filepath.Join(t.TempDir(), "promoted.jsonl")
+	sink, err := openSink("file:" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := baseball.DefaultGameConfig()
+	cfg.Trace = func(p baseball.Play) {
+		if p.Slot == 0 && p.Batter.LastName == "P3" {
+			panic("P3 can't lead off")
+		}
+	}
+	s := runSearch(t, testRoster(6), 6, cfg, 10, sink)
+	closeAll(s.sinks)
+
+	// Every order of the six with P3 leading off: 5*4*3*2.
+	if s.panics != 120 || s.count != 720 {
+		t.Errorf("skipped %d of %d lineups, want 120 of 720", s.panics, s.count)
+	}
+	if err := sink.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("closing the sink again: %v, want it already closed", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	lines := 0
+	for sc.Scan() {
+		var r lineupResult
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("line %d isn't JSON: %v: %s", lines+1, err, sc.Bytes())
+		}
+		if r.Order[0] == "P3" {
+			t.Errorf("line %d: a lineup that panicked was recorded: %v", lines+1, r.Order)
+		}
+		lines++
+	}
+	if lines == 0 {
+		t.Error("the sink recorded nothing")
+	}
+}
+
+func TestDumpAllRecordsEveryLineupOnce(t *testing.T) {
+	withInt(t, lineupSize, 6)
+	withInt64(t, seed, 1)
+	dump := &captureSink{}
+	s := newSearch(testRoster(6), nil, baseball.DefaultGameConfig(), 10, nil)
+	s.dump = dump
+	if err := s.run(3); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[uint64]bool{}
+	for _, r := range dump.results {
+		if seen[r.Hash] {
+			t.Errorf("lineup %v dumped twice", r.Order)
+		}
+		seen[r.Hash] = true
+	}
+	if len(seen) != 720 || len(dump.results) != 720 {
+		t.Errorf("dumped %d rows of %d lineups, want all 720", len(dump.results), len(seen))
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// slotMatrix is how often each player bats in each slot across a set of
+// lineups, as a fraction of them.
+type slotMatrix struct {
+	Lineups int           `json:"lineups"`
+	Rows    []slotFreqRow `json:"rows"`
+}
+
+type slotFreqRow struct {
+	Player string    `json:"player"`
+	Slots  []float64 `json:"slots"`
+}
+
+// newSlotMatrix tallies results' orders. Rows are sorted by each player's
+// average slot, so the grid reads top to bottom like a lineup card.
+func newSlotMatrix(results []lineupResult) *slotMatrix {
+	m := &slotMatrix{Lineups: len(results)}
+	rows := map[string]*slotFreqRow{}
+	avg := map[string]float64{}
+	for _, r := range results {
+		for slot, name := range r.Order {
+			row, ok := rows[name]
+			if !ok {
+				row = &slotFreqRow{Player: name, Slots: make([]float64, *lineupSize)}
+				rows[name] = row
+			}
+			row.Slots[slot] += 1 / float64(len(results))
+			avg[name] += float64(slot) / float64(len(results))
+		}
+	}
+	for _, row := range rows {
+		// A player in only some of the lineups sorts by where they'd bat
+		// when they play.
+		var in float64
+		for _, f := range row.Slots {
+			in += f
+		}
+		avg[row.Player] /= in
+		m.Rows = append(m.Rows, *row)
+	}
+	sort.Slice(m.Rows, func(i, j int) bool {
+		a, b := m.Rows[i].Player, m.Rows[j].Player
+		if avg[a] != avg[b] {
+			return avg[a] < avg[b]
+		}
+		return a < b
+	})
+	return m
+}
+
+func writeSlotMatrix(w io.Writer, m *slotMatrix) {
+	fmt.Fprintf(w, "Slot frequency across the top %d lineups:\n", m.Lineups)
+	fmt.Fprintf(w, "%-14s", "")
+	for i := range m.Rows[0].Slots {
+		fmt.Fprintf(w, " %5d", i+1)
+	}
+	fmt.Fprintln(w)
+	for _, row := range m.Rows {
+		fmt.Fprintf(w, "%-14s", row.Player)
+		for _, f := range row.Slots {
+			if f == 0 {
+				fmt.Fprintf(w, " %5s", ".")
+				continue
+			}
+			fmt.Fprintf(w, " %5.2f", f)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSlotMatrixLeadoffHitter(t *testing.T) {
+	withInt(t, lineupSize, 3)
+	results := []lineupResult{
+		{Order: []string{"Speed", "Bat", "Glove"}},
+		{Order: []string{"Speed", "Glove", "Bat"}},
+		{Order: []string{"Speed", "Bat", "Glove"}},
+		{Order: []string{"Bat", "Speed", "Glove"}},
+	}
+	m := newSlotMatrix(results)
+	if m.Lineups != 4 || len(m.Rows) != 3 {
+		t.Fatalf("matrix of %d lineups with %d rows", m.Lineups, len(m.Rows))
+	}
+	// Rows run by average slot: Speed, then Bat, then Glove.
+	want := "[{Speed [0.75 0.25 0]} {Bat [0.25 0.5 0.25]} {Glove [0 0.25 0.75]}]"
+	if got := fmt.Sprint(m.Rows); got != want {
+		t.Errorf("rows %s, want %s", got, want)
+	}
+	lead := m.Rows[0]
+	for _, row := range m.Rows[1:] {
+		if row.Slots[0] >= lead.Slots[0] {
+			t.Errorf("%s leads off %.2f of the lineups, %s %.2f", row.Player, row.Slots[0], lead.Player, lead.Slots[0])
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// slotSplit is one slot of the top lineup with the batter's splits from
+// the players file. LHP or RHP is left out when the config fixes the
+// pitcher hand simulated.
+type slotSplit struct {
+	Slot int             `json:"slot"` // 1-based
+	Name string          `json:"name"`
+	LHP  *baseball.Stats `json:"lhp,omitempty"`
+	RHP  *baseball.Stats `json:"rhp,omitempty"`
+}
+
+// newSlotSplits pairs each slot of lineup with its batter's splits against
+// the pitcher hands cfg's games use, per pitcherHands.
+func newSlotSplits(lineup []baseball.Player, cfg baseball.GameConfig) []slotSplit {
+	splits := make([]slotSplit, len(lineup))
+	for i, p := range lineup {
+		s := slotSplit{Slot: i + 1, Name: p.FirstName + " " + p.LastName}
+		for _, hand := range pitcherHands(cfg) {
+			split := p.Split(hand)
+			if hand == "left" {
+				s.LHP = &split
+			} else {
+				s.RHP = &split
+			}
+		}
+		splits[i] = s
+	}
+	return splits
+}
+
+// writeSlotSplits prints the top lineup slot by slot with each batter's
+// AVG, OBP and SLUG against the hands in splits.
+func writeSlotSplits(w io.Writer, splits []slotSplit) {
+	if len(splits) == 0 {
+		return
+	}
+	lhp, rhp := splits[0].LHP != nil, splits[0].RHP != nil
+	fmt.Fprintln(w, "Top lineup by slot with each batter's splits:")
+	header := fmt.Sprintf("%-26s", "")
+	cols := fmt.Sprintf("%-26s", "Slot")
+	if lhp {
+		header += fmt.Sprintf("  %-17s", "vs LHP")
+		cols += fmt.Sprintf("  %5s %5s %5s", "AVG", "OBP", "SLG")
+	}
+	if rhp {
+		header += fmt.Sprintf("  %-17s", "vs RHP")
+		cols += fmt.Sprintf("  %5s %5s %5s", "AVG", "OBP", "SLG")
+	}
+	fmt.Fprintln(w, strings.TrimRight(header, " "))
+	fmt.Fprintln(w, cols)
+	for _, s := range splits {
+		line := fmt.Sprintf("%2d) %-22s", s.Slot, s.Name)
+		if s.LHP != nil {
+			line += "  " + slashLine(*s.LHP)
+		}
+		if s.RHP != nil {
+			line += "  " + slashLine(*s.RHP)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
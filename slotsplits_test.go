@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestSlotSplitsPairEachBatterWithTheirSplits(t *testing.T) {
+	withInt(t, lineupSize, 4)
+	lineup := testRoster(4)
+	for i := range lineup {
+		lineup[i].LHP.OBP -= 0.050
+	}
+	cfg := baseball.DefaultGameConfig()
+	splits := newSlotSplits(lineup, cfg)
+	if len(splits) != *lineupSize {
+		t.Fatalf("%d rows for a %d-player lineup", len(splits), *lineupSize)
+	}
+	for i, s := range splits {
+		p := lineup[i]
+		if s.Slot != i+1 || s.Name != "Test "+p.LastName || s.LHP == nil || s.RHP == nil || *s.LHP != p.LHP || *s.RHP != p.RHP {
+			t.Errorf("slot %d: %+v, want %s with %+v and %+v", i+1, s, p.LastName, p.LHP, p.RHP)
+		}
+	}
+
+	var buf bytes.Buffer
+	writeSlotSplits(&buf, splits)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3+len(lineup) || !strings.HasPrefix(lines[3], " 1) Test P1") || !strings.HasSuffix(lines[3], slashLine(lineup[0].LHP)+"  "+slashLine(lineup[0].RHP)) {
+		t.Errorf("printed:\n%s", buf.String())
+	}
+
+	// Facing only righties, only the RHP split is listed.
+	cfg.PitcherHand = "right"
+	for _, s := range newSlotSplits(lineup, cfg) {
+		if s.LHP != nil || s.RHP == nil {
+			t.Errorf("slot %d against righties: %+v", s.Slot, s)
+		}
+	}
+}
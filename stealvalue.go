@@ -0,0 +1,42 @@
+package main
+
+import (
+	"math/rand"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// stealValue is what the running game is worth to a lineup, in runs per
+// game.
+type stealValue struct {
+	With    float64 `json:"with"`
+	Without float64 `json:"without"`
+	Net     float64 `json:"net"`
+	// SB and CS are stolen bases and caught stealing per game with steals on.
+	SB float64 `json:"sb"`
+	CS float64 `json:"cs"`
+}
+
+// measureSteals replays lineup over the same seeded games with cfg's steal
+// model (baseball.DefaultSteals when it has none) and with steals off. Net
+// is positive when the roster's speed earns more bases than the outs its
+// caught runners give away.
+func measureSteals(lineup []baseball.Player, cfg baseball.GameConfig, games int, seed int64) stealValue {
+	on, off := cfg, cfg
+	if on.Steals.AttemptRate <= 0 {
+		on.Steals = baseball.DefaultSteals
+	}
+	off.Steals = baseball.StealModel{}
+	var v stealValue
+	r := rand.New(rand.NewSource(seed))
+	var runs, sb, cs int
+	for g := 0; g < games; g++ {
+		game := baseball.SimulateGame(lineup, on, r)
+		runs, sb, cs = runs+game.Runs, sb+game.SB, cs+game.CS
+	}
+	n := float64(games)
+	v.With, v.SB, v.CS = float64(runs)/n, float64(sb)/n, float64(cs)/n
+	v.Without = EvaluateLineup(lineup, off, games, seed).Mean
+	v.Net = v.With - v.Without
+	return v
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestStealValueFollowsSpeed(t *testing.T) {
+	cfg := baseball.DefaultGameConfig()
+	cfg.Steals = baseball.StealModel{AttemptRate: 0.4, SuccessRate: baseball.DefaultSteals.SuccessRate}
+	net := func(speed float64) stealValue {
+		p := testPlayer("Runner", 0.340, 0.420)
+		p.Speed = speed
+		return measureSteals(nineOf(p), cfg, 2000, 1)
+	}
+	fast, slow := net(30), net(23)
+	if fast.Net <= 0 {
+		t.Errorf("fast roster: %.2f SB and %.2f CS a game for %+.3f runs", fast.SB, fast.CS, fast.Net)
+	}
+	if slow.Net >= 0 {
+		t.Errorf("slow roster: %.2f SB and %.2f CS a game for %+.3f runs", slow.SB, slow.CS, slow.Net)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// streakBucket is how often a scoring streak of Length plate appearances
+// happens per game and the runs those streaks score per game.
+type streakBucket struct {
+	Length      int     `json:"length"`
+	PerGame     float64 `json:"per_game"`
+	RunsPerGame float64 `json:"runs_per_game"`
+}
+
+// streakSummary describes a lineup's rallies. A streak is a run of
+// consecutive plate appearances in a half-inning without an out that
+// scores at least once; ShareOfRuns is the fraction of all runs they score,
+// the rest coming on outs or in ones and twos around them.
+type streakSummary struct {
+	Games       int            `json:"games"`
+	Buckets     []streakBucket `json:"buckets"`
+	ShareOfRuns float64        `json:"share_of_runs"`
+}
+
+// measureStreaks replays lineup for games seeded games and collects its
+// scoring streaks from the play-by-play.
+func measureStreaks(lineup []baseball.Player, cfg baseball.GameConfig, games int, seed int64) streakSummary {
+	var count, runsBy []int
+	var length, streakRuns, inning, allRuns, inStreaks int
+	end := func() {
+		if length > 0 && streakRuns > 0 {
+			for len(count) <= length {
+				count, runsBy = append(count, 0), append(runsBy, 0)
+			}
+			count[length]++
+			runsBy[length] += streakRuns
+			inStreaks += streakRuns
+		}
+		length, streakRuns = 0, 0
+	}
+	cfg.Trace = func(p baseball.Play) {
+		allRuns += p.Runs
+		if p.Inning != inning {
+			end()
+			inning = p.Inning
+		}
+		if p.Outs > p.OutsBefore {
+			end()
+			return
+		}
+		length++
+		streakRuns += p.Runs
+	}
+	r := rand.New(rand.NewSource(seed))
+	for g := 0; g < games; g++ {
+		baseball.SimulateGame(lineup, cfg, r)
+		end()
+		inning = 0
+	}
+	sum := streakSummary{Games: games}
+	n := float64(games)
+	for l := range count {
+		if count[l] > 0 {
+			sum.Buckets = append(sum.Buckets, streakBucket{Length: l, PerGame: float64(count[l]) / n, RunsPerGame: float64(runsBy[l]) / n})
+		}
+	}
+	if allRuns > 0 {
+		sum.ShareOfRuns = float64(inStreaks) / float64(allRuns)
+	}
+	return sum
+}
+
+func writeStreaks(w io.Writer, s streakSummary) {
+	fmt.Fprintf(w, "Scoring streaks for the top lineup over %d games (%.1f%% of runs):\n", s.Games, 100*s.ShareOfRuns)
+	fmt.Fprintf(w, "%6s %9s %9s\n", "PAs", "per game", "runs/g")
+	for _, b := range s.Buckets {
+		fmt.Fprintf(w, "%6d %9.3f %9.3f\n", b.Length, b.PerGame, b.RunsPerGame)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestMeasureStreaksScriptedRally(t *testing.T) {
+	cfg := baseball.DefaultGameConfig()
+	// Walk, walk, three-run homer to open the first; outs otherwise.
+	cfg.OutcomeOverride = func(slot, inning int) (baseball.PlateOutcome, bool) {
+		switch {
+		case inning == 1 && slot < 2:
+			return baseball.HIT_WALK, true
+		case inning == 1 && slot == 2:
+			return baseball.HIT_HOMERUN, true
+		}
+		return baseball.HIT_OUT, true
+	}
+	st := measureStreaks(testRoster(9), cfg, 10, 1)
+	want := streakBucket{Length: 3, PerGame: 1, RunsPerGame: 3}
+	if len(st.Buckets) != 1 || st.Buckets[0] != want || st.ShareOfRuns != 1 {
+		t.Errorf("streaks %+v, want one %+v a game scoring every run", st, want)
+	}
+}
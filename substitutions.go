@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// parsePinchHits parses -pinch-hit's comma-separated inning:slot:last-name
+// entries, with 1-based slots, looking each player up in players.
+func parsePinchHits(spec string, players []baseball.Player) ([]baseball.PinchHit, error) {
+	var phs []baseball.PinchHit
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%q isn't inning:slot:name", entry)
+		}
+		inning, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("%q: bad inning: %v", entry, err)
+		}
+		slot, err := parseSlot(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", entry, err)
+		}
+		ph := baseball.PinchHit{Inning: inning, Slot: slot}
+		if ph.Player, err = findPlayer(players, parts[2]); err != nil {
+			return nil, fmt.Errorf("%q: %v", entry, err)
+		}
+		phs = append(phs, ph)
+	}
+	return phs, nil
+}
+
+// parseDoubleSwitches parses -double-switch's comma-separated
+// inning:out-slot:last-name:bat-slot entries, with 1-based slots: the named
+// player replaces the batter in out-slot and bats in bat-slot, whose batter
+// moves to out-slot.
+func parseDoubleSwitches(spec string, players []baseball.Player) ([]baseball.DoubleSwitch, error) {
+	var dss []baseball.DoubleSwitch
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("%q isn't inning:out-slot:name:bat-slot", entry)
+		}
+		var ds baseball.DoubleSwitch
+		var err error
+		if ds.Inning, err = strconv.Atoi(parts[0]); err != nil {
+			return nil, fmt.Errorf("%q: bad inning: %v", entry, err)
+		}
+		if ds.Out, err = parseSlot(parts[1]); err != nil {
+			return nil, fmt.Errorf("%q: %v", entry, err)
+		}
+		if ds.Player, err = findPlayer(players, parts[2]); err != nil {
+			return nil, fmt.Errorf("%q: %v", entry, err)
+		}
+		if ds.Slot, err = parseSlot(parts[3]); err != nil {
+			return nil, fmt.Errorf("%q: %v", entry, err)
+		}
+		dss = append(dss, ds)
+	}
+	return dss, nil
+}
+
+// parsePinchRunners parses -pinch-run's comma-separated last names,
+// looking each player up in players.
+func parsePinchRunners(spec string, players []baseball.Player) ([]baseball.Player, error) {
+	var runners []baseball.Player
+	for _, name := range strings.Split(spec, ",") {
+		p, err := findPlayer(players, strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if p.Speed <= 0 {
+			return nil, fmt.Errorf("%s has no speed to pinch-run with", p.LastName)
+		}
+		runners = append(runners, p)
+	}
+	return runners, nil
+}
+
+// parseSlot reads a 1-based batting slot and returns it 0-based.
+func parseSlot(s string) (int, error) {
+	slot, err := strconv.Atoi(s)
+	if err != nil || slot < 1 || slot > *lineupSize {
+		return 0, fmt.Errorf("slot must be 1-%d", *lineupSize)
+	}
+	return slot - 1, nil
+}
+
+// parseFix reads a -fix spec, slot:last-name, and returns the player's
+// roster index and 0-based slot.
+func parseFix(spec string, players []baseball.Player) (idx, slot int, err error) {
+	slotStr, name, ok := strings.Cut(spec, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("%q isn't slot:name", spec)
+	}
+	if slot, err = parseSlot(slotStr); err != nil {
+		return 0, 0, err
+	}
+	for i, p := range players {
+		if strings.EqualFold(p.LastName, name) {
+			return i, slot, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no player named %s", name)
+}
+
+// parseBackups reads a -backup spec, comma-separated starter:backup last
+// names from players, into GameConfig.Backups.
+func parseBackups(spec string, players []baseball.Player) (map[string]baseball.Player, error) {
+	backups := make(map[string]baseball.Player)
+	for _, entry := range strings.Split(spec, ",") {
+		starter, backup, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("%q isn't starter:backup", entry)
+		}
+		s, err := findPlayer(players, starter)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", entry, err)
+		}
+		b, err := findPlayer(players, backup)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", entry, err)
+		}
+		backups[s.LastName] = b
+	}
+	return backups, nil
+}
+
+// findPlayer looks up a player in players by last name, ignoring case.
+func findPlayer(players []baseball.Player, name string) (baseball.Player, error) {
+	for _, p := range players {
+		if strings.EqualFold(p.LastName, name) {
+			return p, nil
+		}
+	}
+	return baseball.Player{}, fmt.Errorf("no player named %s", name)
+}
+
+// subReport summarizes the substitutions in replays of the top lineup.
+type subReport struct {
+	Games int `json:"games"`
+	// Used is how many games each player appeared in, most first.
+	Used []playerUse `json:"players_used"`
+	// Log counts each distinct substitution made.
+	Log []subCount `json:"log"`
+}
+
+type playerUse struct {
+	Name  string `json:"name"`
+	Games int    `json:"games"`
+}
+
+type subCount struct {
+	baseball.Substitution
+	Games int `json:"games"`
+}
+
+// substitutions replays lineup for games seeded games under cfg and tallies
+// its substitution logs.
+func substitutions(lineup []baseball.Player, cfg baseball.GameConfig, games int, seed int64) subReport {
+	rep := subReport{Games: games}
+	used := make(map[string]int)
+	logged := make(map[baseball.Substitution]int)
+	r := rand.New(rand.NewSource(seed))
+	for g := 0; g < games; g++ {
+		game := baseball.SimulateGame(lineup, cfg, r)
+		for _, p := range lineup {
+			used[p.LastName]++
+		}
+		for _, sub := range game.Subs {
+			used[sub.In]++
+			logged[sub]++
+			if sub.Inning == 0 {
+				used[sub.Out]--
+			}
+		}
+	}
+	for name, n := range used {
+		rep.Used = append(rep.Used, playerUse{name, n})
+	}
+	sort.Slice(rep.Used, func(i, j int) bool {
+		if rep.Used[i].Games != rep.Used[j].Games {
+			return rep.Used[i].Games > rep.Used[j].Games
+		}
+		return rep.Used[i].Name < rep.Used[j].Name
+	})
+	for sub, n := range logged {
+		rep.Log = append(rep.Log, subCount{sub, n})
+	}
+	sort.Slice(rep.Log, func(i, j int) bool {
+		a, b := rep.Log[i], rep.Log[j]
+		if a.Inning != b.Inning {
+			return a.Inning < b.Inning
+		}
+		if a.Slot != b.Slot {
+			return a.Slot < b.Slot
+		}
+		return a.In < b.In
+	})
+	return rep
+}
+
+// sitsOut reports whether the top of results has a player who may miss a
+// game by their availability, so its replays log substitutions.
+func sitsOut(results []lineupResult) bool {
+	if len(results) == 0 {
+		return false
+	}
+	for _, p := range results[0].lineup {
+		if p.Availability > 0 && p.Availability < 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// writeSummary prints each player's LHP and RHP slash lines and the team's
+// per-player averages, for eyeballing the data before a search.
+func writeSummary(w io.Writer, players []baseball.Player) {
+	fmt.Fprintf(w, "%-22s  %-17s  %s\n", "", "vs LHP", "vs RHP")
+	fmt.Fprintf(w, "%-22s  %5s %5s %5s  %5s %5s %5s\n", "Player", "AVG", "OBP", "SLG", "AVG", "OBP", "SLG")
+	var lhp, rhp baseball.Stats
+	for _, p := range players {
+		fmt.Fprintf(w, "%-22s  %s  %s\n", p.FirstName+" "+p.LastName, slashLine(p.LHP), slashLine(p.RHP))
+		lhp.AVG += p.LHP.AVG
+		lhp.OBP += p.LHP.OBP
+		lhp.SLUG += p.LHP.SLUG
+		rhp.AVG += p.RHP.AVG
+		rhp.OBP += p.RHP.OBP
+		rhp.SLUG += p.RHP.SLUG
+	}
+	n := float64(len(players))
+	lhp = baseball.Stats{AVG: lhp.AVG / n, OBP: lhp.OBP / n, SLUG: lhp.SLUG / n}
+	rhp = baseball.Stats{AVG: rhp.AVG / n, OBP: rhp.OBP / n, SLUG: rhp.SLUG / n}
+	fmt.Fprintf(w, "%-22s  %s  %s\n", fmt.Sprintf("Team average (%d)", len(players)), slashLine(lhp), slashLine(rhp))
+}
+
+// slashLine formats s as AVG OBP SLUG in the usual .xxx style.
+func slashLine(s baseball.Stats) string {
+	f := func(v float64) string {
+		str := fmt.Sprintf("%5.3f", v)
+		if v < 1 {
+			str = " " + str[1:]
+		}
+		return str
+	}
+	return f(s.AVG) + " " + f(s.OBP) + " " + f(s.SLUG)
+}
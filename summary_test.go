@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestWriteSummary(t *testing.T) {
+	players := []baseball.Player{
+		{FirstName: "Ann", LastName: "Lefty", LHP: baseball.Stats{AVG: 0.300, OBP: 0.400, SLUG: 0.500}, RHP: baseball.Stats{AVG: 0.200, OBP: 0.300, SLUG: 0.400}},
+		{FirstName: "Bo", LastName: "Even", LHP: baseball.Stats{AVG: 0.250, OBP: 0.350, SLUG: 0.450}, RHP: baseball.Stats{AVG: 0.260, OBP: 0.320, SLUG: 0.380}},
+	}
+	var buf bytes.Buffer
+	writeSummary(&buf, players)
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want a header, two players and the team:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{
+		"Ann Lefty                .300  .400  .500   .200  .300  .400",
+		"Bo Even                  .250  .350  .450   .260  .320  .380",
+		"Team average (2)         .275  .375  .475   .230  .310  .390",
+	} {
+		if lines[i+2] != want {
+			t.Errorf("line %d = %q, want %q", i+3, lines[i+2], want)
+		}
+	}
+}
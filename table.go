@@ -0,0 +1,39 @@
+package main
+
+import (
+	"sort"
+	"sync"
+)
+
+// tableRow is one searched lineup's line in the -format parquet table.
+type tableRow struct {
+	Hash   uint64
+	Order  []string
+	Games  int64
+	Runs   int64
+	Hits   int64
+	Mean   float64
+	StdDev float64
+}
+
+// lineupTable collects a row for every lineup the search evaluates, safe
+// for concurrent use. Like lineupStats it grows with the search space.
+type lineupTable struct {
+	mu   sync.Mutex
+	rows []tableRow
+}
+
+func (t *lineupTable) add(r tableRow) {
+	t.mu.Lock()
+	t.rows = append(t.rows, r)
+	t.mu.Unlock()
+}
+
+// sorted returns the rows by hash, so a table doesn't depend on the order
+// workers finished in.
+func (t *lineupTable) sorted() []tableRow {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sort.Slice(t.rows, func(i, j int) bool { return t.rows[i].Hash < t.rows[j].Hash })
+	return t.rows
+}
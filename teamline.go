@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sync/atomic"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// teamLine is a lineup's simulated batting line from the search's games
+// next to what its players' splits put in, so a user can check the engine
+// reproduces the hitting it was given.
+type teamLine struct {
+	Games       int64   `json:"games"`
+	HitsPerGame float64 `json:"hits_per_game"`
+	AVG         float64 `json:"avg"` // hits per at-bat (plate appearances less walks and HBP)
+	OBP         float64 `json:"obp"`
+	InputAVG    float64 `json:"input_avg"` // the players' mean split AVG
+	InputOBP    float64 `json:"input_obp"`
+}
+
+// newTeamLine builds res's team line from its search aggregate, or returns
+// nil if it has none. Input rates weight the splits by -lhp-share under
+// -platoon, use the fixed pitcher hand when there is one, and otherwise
+// average the two.
+func newTeamLine(res lineupResult, cfg baseball.GameConfig) *teamLine {
+	v, ok := lineupStats.Load(res.Hash)
+	if !ok {
+		return nil
+	}
+	a := v.(*Agg)
+	games, hits := atomic.LoadInt64(&a.Games), atomic.LoadInt64(&a.Hits)
+	pas, walks := atomic.LoadInt64(&a.PAs), atomic.LoadInt64(&a.Walks)
+	if games == 0 || pas <= walks {
+		return nil
+	}
+	t := &teamLine{
+		Games:       games,
+		HitsPerGame: float64(hits) / float64(games),
+		AVG:         float64(hits) / float64(pas-walks),
+		OBP:         float64(hits+walks) / float64(pas),
+	}
+	share := 0.5
+	switch {
+	case *platoon:
+		share = *lhpShare
+	case cfg.PitcherHand == "left":
+		share = 1
+	case cfg.PitcherHand == "right":
+		share = 0
+	}
+	for _, p := range res.lineup {
+		t.InputAVG += share*p.LHP.AVG + (1-share)*p.RHP.AVG
+		t.InputOBP += share*p.LHP.OBP + (1-share)*p.RHP.OBP
+	}
+	n := float64(len(res.lineup))
+	t.InputAVG /= n
+	t.InputOBP /= n
+	return t
+}
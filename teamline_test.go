@@ -0,0 +1,32 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestTeamLineMatchesInputAVG(t *testing.T) {
+	clearLineupStats()
+	t.Cleanup(clearLineupStats)
+	players := make([]baseball.Player, 6)
+	for i := range players {
+		players[i] = testPlayer(string(rune('A'+i)), 0.370, 0.450)
+	}
+	cfg := baseball.DefaultGameConfig()
+	s := runSearch(t, players, 6, cfg, 500)
+
+	line := newTeamLine(s.topResults()[0], cfg)
+	if line == nil {
+		t.Fatal("no team line for the top lineup")
+	}
+	if math.Abs(line.InputAVG-0.300) > 1e-9 {
+		t.Errorf("input AVG %.3f, want .300", line.InputAVG)
+	}
+	// The engine draws a hit with chance AVG per plate appearance, so per
+	// at-bat, with the .070 of walks and HBP taken out, it's .300/.930.
+	if want := 0.300 / 0.930; math.Abs(line.AVG-want) > 0.01 || math.Abs(line.OBP-0.370) > 0.01 {
+		t.Errorf("simulated AVG %.3f OBP %.3f over %d games, want about %.3f and .370", line.AVG, line.OBP, line.Games, want)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// teamResult is one roster's best lineup in -players-dir mode.
+type teamResult struct {
+	File    string       `json:"file"`
+	Players int          `json:"players"`
+	Best    lineupResult `json:"best"`
+}
+
+// searchDir runs the lineup search for every *.json roster in dir, at most
+// jobs at a time, and returns the teams ranked by their best lineup's score.
+// Rosters that fail to load or validate are skipped with a warning.
+func searchDir(dir string, jobs int, opponent []baseball.Player, schedule []baseball.Pitcher, cfg baseball.GameConfig, games int) ([]teamResult, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.json files in %s", dir)
+	}
+
+	// Split the CPUs between the concurrent searches.
+	workers := runtime.NumCPU() / jobs
+	if workers < 1 {
+		workers = 1
+	}
+
+	var mu sync.Mutex
+	var teams []teamResult
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for _, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(file string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			t, err := searchTeam(file, opponent, schedule, cfg, games, workers)
+			if err != nil {
+				log.Printf("Skipping %s: %v", file, err)
+				return
+			}
+			infof("Searched %s: best mean %.3f", file, t.Best.Mean)
+			mu.Lock()
+			teams = append(teams, t)
+			mu.Unlock()
+		}(file)
+	}
+	wg.Wait()
+
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Best.Score > teams[j].Best.Score })
+	return teams, nil
+}
+
+// searchTeam loads and validates one roster and returns its best lineup.
+func searchTeam(file string, opponent []baseball.Player, schedule []baseball.Pitcher, cfg baseball.GameConfig, games, workers int) (teamResult, error) {
+	players, err := loadPlayersFromFile(file)
+	if err != nil {
+		return teamResult{}, err
+	}
+	if err := checkSplits(players, *imputeStats, *strictData); err != nil {
+		return teamResult{}, err
+	}
+	s := newSearch(players, opponent, cfg, games, nil)
+	s.schedule = schedule
+	if *leadoffOBP {
+		s.fixed = bestOBP(players, *lhpShare)
+	}
+	if total := s.lineupCount(); total > *maxLineups && !*force {
+		return teamResult{}, fmt.Errorf("%.3g lineups exceeds -max-lineups %.3g", total, *maxLineups)
+	}
+	if err := s.run(workers); err != nil {
+		return teamResult{}, err
+	}
+	results := s.topResults()
+	if len(results) == 0 {
+		return teamResult{}, fmt.Errorf("no lineup can field %v", baseball.FieldingPositions)
+	}
+	if *minGamesCI > 0 {
+		results = refineToCI(results, cfg, *minGamesCI, *ciStep, *ciMaxGames, workers)
+	}
+	return teamResult{File: filepath.Base(file), Players: len(players), Best: results[0]}, nil
+}
+
+// writeTeams renders the -players-dir ranking to w as "text", "json" or "csv".
+func writeTeams(w io.Writer, format string, teams []teamResult) error {
+	switch format {
+	case "text":
+		fmt.Fprintln(w, "Teams by best lineup:")
+		for i, t := range teams {
+			fmt.Fprintf(w, "%2d) %s ID=%s score=%.3f mean=%.3f  order=%v\n", i+1, t.File, t.Best.ID(), t.Best.Score, t.Best.Mean, t.Best.Order)
+		}
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(teams)
+	case "csv":
+		cw := csv.NewWriter(w)
+		header := []string{"rank", "file", "id", "score", "mean", "stddev", "games"}
+		for i := 1; i <= *lineupSize; i++ {
+			header = append(header, "slot"+strconv.Itoa(i))
+		}
+		cw.Write(header)
+		for i, t := range teams {
+			row := []string{
+				strconv.Itoa(i + 1),
+				t.File,
+				t.Best.ID(),
+				strconv.FormatFloat(t.Best.Score, 'f', 4, 64),
+				strconv.FormatFloat(t.Best.Mean, 'f', 4, 64),
+				strconv.FormatFloat(t.Best.StdDev, 'f', 4, 64),
+				strconv.Itoa(t.Best.Games),
+			}
+			row = append(row, t.Best.Order...)
+			cw.Write(row)
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestSearchDirSkipsBadRosters(t *testing.T) {
+	withInt(t, lineupSize, 6)
+	withInt64(t, seed, 1)
+	withBool(t, quiet, true)
+	dir := t.TempDir()
+	write := func(name string, body []byte) {
+		if err := os.WriteFile(filepath.Join(dir, name), body, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	strong, _ := json.Marshal(testRoster(6))
+	weak := testRoster(6)
+	for i := range weak {
+		weak[i] = testPlayer(weak[i].LastName, 0.250, 0.300)
+	}
+	weakJSON, _ := json.Marshal(weak)
+	write("strong.json", strong)
+	write("weak.json", weakJSON)
+	write("broken.json", []byte(`[{"last_name": `))
+
+	teams, err := searchDir(dir, 2, nil, nil, baseball.DefaultGameConfig(), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(teams) != 2 {
+		t.Fatalf("got %d teams, want strong and weak: %+v", len(teams), teams)
+	}
+	if teams[0].File != "strong.json" || teams[1].File != "weak.json" {
+		t.Errorf("ranked %s above %s", teams[0].File, teams[1].File)
+	}
+	for _, team := range teams {
+		if team.Players != 6 || len(team.Best.Order) != 6 {
+			t.Errorf("%s: %d players, best order %v", team.File, team.Players, team.Best.Order)
+		}
+	}
+}
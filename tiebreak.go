@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// tiebreak is the -tiebreak secondary objective: scores are grouped into
+// bands Eps wide, and between two lineups in the same band the one with the
+// higher Tiebreak value ranks ahead. Kind is "" (off), "floor", "stddev" or
+// "atleast" with N. Banding, unlike judging each pair by whether their
+// scores are within Eps, keeps the ranking a consistent order for the heaps
+// and sorts, at the cost of two close scores either side of a band's edge
+// going by score alone.
+type tiebreak struct {
+	Kind string
+	N    int
+	Eps  float64
+}
+
+// tiebreaker is the run's -tiebreak, consulted by ranksAbove.
+var tiebreaker tiebreak
+
+// parseTiebreak reads a -tiebreak spec: "floor" (higher 10th-percentile
+// runs), "stddev" (steadier games) or "atleast:N" (more games of N or more
+// runs).
+func parseTiebreak(spec string, eps float64) (tiebreak, error) {
+	t := tiebreak{Kind: spec, Eps: eps}
+	switch {
+	case spec == "floor", spec == "stddev":
+	case strings.HasPrefix(spec, "atleast:"):
+		n, err := strconv.Atoi(strings.TrimPrefix(spec, "atleast:"))
+		if err != nil || n < 1 {
+			return t, fmt.Errorf("%q needs a positive run count, e.g. atleast:5", spec)
+		}
+		t.Kind, t.N = "atleast", n
+	default:
+		return t, fmt.Errorf(`%q isn't "floor", "stddev" or "atleast:N"`, spec)
+	}
+	if eps < 0 {
+		return t, fmt.Errorf("epsilon must not be negative, got %v", eps)
+	}
+	return t, nil
+}
+
+// value is a lineup's tiebreak value, higher better, from the runs of its
+// games in hist and their tally.
+func (t tiebreak) value(hist runsHistogram, tally runTally) float64 {
+	switch t.Kind {
+	case "floor":
+		return float64(hist.percentile(10))
+	case "stddev":
+		return -tally.StdDev()
+	case "atleast":
+		n, at := 0, 0
+		for runs, c := range hist {
+			n += c
+			if runs >= t.N {
+				at += c
+			}
+		}
+		if n == 0 {
+			return 0
+		}
+		return float64(at) / float64(n)
+	}
+	return 0
+}
+
+// band is the Eps-wide band of scores s falls in, or s itself when Eps is
+// 0 and only exact ties are broken.
+func (t tiebreak) band(s float64) float64 {
+	if t.Eps == 0 {
+		return s
+	}
+	return math.Floor(s / t.Eps)
+}
+
+// order decides whether a ranks ahead of b by the tiebreak: a higher band,
+// or in the same band a higher value. ok is false when the tiebreak is off
+// or a and b share a band and a value, leaving them to Score and Hash.
+func (t tiebreak) order(a, b lineupResult) (above, ok bool) {
+	if t.Kind == "" {
+		return false, false
+	}
+	if ba, bb := t.band(a.Score), t.band(b.Score); ba != bb {
+		return ba > bb, true
+	}
+	if a.Tiebreak != b.Tiebreak {
+		return a.Tiebreak > b.Tiebreak, true
+	}
+	return false, false
+}
+
+// slack is how far below another's score a lineup can be and still rank
+// ahead of it: under Eps, in the same band, when the tiebreak is on.
+func (t tiebreak) slack() float64 {
+	if t.Kind == "" {
+		return 0
+	}
+	return t.Eps
+}
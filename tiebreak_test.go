@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestTiebreakDecidesNearTies(t *testing.T) {
+	tb, err := parseTiebreak("stddev", 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := tiebreaker
+	tiebreaker = tb
+	t.Cleanup(func() { tiebreaker = old })
+
+	result := func(hash uint64, score float64, games ...int) lineupResult {
+		var tally runTally
+		var hist runsHistogram
+		for _, runs := range games {
+			tally.Add(runs)
+			hist.add(runs)
+		}
+		return lineupResult{Hash: hash, Score: score, Tiebreak: tb.value(hist, tally)}
+	}
+	// The noisy lineup scores a hair better, within epsilon.
+	steady := result(1<<60, 4.5012, 4, 5, 4, 5)
+	noisy := result(2<<60, 4.5051, 0, 9, 1, 8)
+	if !ranksAbove(steady, noisy) || ranksAbove(noisy, steady) {
+		t.Errorf("stddev tiebreak ranked %.4f (stddev %.2f) below %.4f (stddev %.2f)", steady.Score, -steady.Tiebreak, noisy.Score, -noisy.Tiebreak)
+	}
+	// A clear gap in score isn't a tie.
+	noisy.Score = 4.6
+	if ranksAbove(steady, noisy) {
+		t.Error("tiebreak overrode a score 0.1 better")
+	}
+
+	for _, spec := range []string{"median", "atleast:0", "atleast:x"} {
+		if _, err := parseTiebreak(spec, 0.01); err == nil {
+			t.Errorf("-tiebreak %q accepted", spec)
+		}
+	}
+}
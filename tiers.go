@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// tier is a run of top lineups, in rank order, whose means no pair of
+// which differ significantly.
+type tier struct {
+	Tier int      `json:"tier"`
+	IDs  []string `json:"ids"`
+	High float64  `json:"high"` // best mean in the tier
+	Low  float64  `json:"low"`  // worst mean in the tier
+}
+
+// significantlyDifferent reports whether a's and b's means differ at 95%,
+// treating their games as independent: the gap must exceed the combined
+// half-width of the two intervals in quadrature.
+func significantlyDifferent(a, b lineupResult) bool {
+	ha, hb := a.tally.HalfWidth95(), b.tally.HalfWidth95()
+	return math.Abs(a.Mean-b.Mean) > math.Sqrt(ha*ha+hb*hb)
+}
+
+// groupTiers splits results, best first, into tiers: each lineup joins the
+// current tier unless it differs significantly from any lineup already in
+// it, and otherwise starts the next one.
+func groupTiers(results []lineupResult) []tier {
+	var tiers []tier
+	start := 0
+	for i := range results {
+		for j := start; j < i; j++ {
+			if significantlyDifferent(results[j], results[i]) {
+				start = i
+				break
+			}
+		}
+		if i == start {
+			tiers = append(tiers, tier{Tier: len(tiers) + 1, High: results[i].Mean, Low: results[i].Mean})
+		}
+		t := &tiers[len(tiers)-1]
+		t.IDs = append(t.IDs, results[i].ID())
+		t.High = math.Max(t.High, results[i].Mean)
+		t.Low = math.Min(t.Low, results[i].Mean)
+	}
+	return tiers
+}
+
+func writeTiers(w io.Writer, tiers []tier) {
+	fmt.Fprintln(w, "Top lineups in tiers of statistically equivalent means (95%):")
+	for _, t := range tiers {
+		what := fmt.Sprintf("these %d lineups are statistically equivalent", len(t.IDs))
+		if len(t.IDs) == 1 {
+			what = "this lineup stands alone"
+		}
+		ids := strings.Join(t.IDs, " ")
+		if len(t.IDs) > 10 {
+			ids = fmt.Sprintf("%s and %d more", strings.Join(t.IDs[:10], " "), len(t.IDs)-10)
+		}
+		fmt.Fprintf(w, "Tier %d: %s (mean %.3f to %.3f): %s\n", t.Tier, what, t.High, t.Low, ids)
+	}
+}
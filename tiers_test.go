@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestGroupTiers(t *testing.T) {
+	// Each mean is over 1000 games with a stddev of 3, a 95% half-width
+	// of about 0.19.
+	result := func(hash uint64, mean float64) lineupResult {
+		return lineupResult{Hash: hash, Mean: mean, tally: runTally{N: 1000, Sum: 1000 * mean, M2: 9 * 999}}
+	}
+	results := []lineupResult{result(1<<60, 5.00), result(2<<60, 4.98), result(3<<60, 4.96), result(4<<60, 4.40), result(5<<60, 4.38)}
+	tiers := groupTiers(results)
+	if len(tiers) != 2 {
+		t.Fatalf("%d tiers: %+v", len(tiers), tiers)
+	}
+	first, second := tiers[0], tiers[1]
+	if len(first.IDs) != 3 || first.IDs[2] != results[2].ID() || first.High != 5.00 || first.Low != 4.96 {
+		t.Errorf("first tier %+v", first)
+	}
+	if second.Tier != 2 || len(second.IDs) != 2 || second.IDs[0] != results[3].ID() {
+		t.Errorf("second tier %+v", second)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// playTimed plays up to games games of lineup from r and returns each
+// game's runs and the total plate appearances. With a positive limit it
+// gives up once limit has passed, returning the games finished so far and
+// timedOut set. The games run on their own goroutine so that even a single
+// game that never ends can't stall the caller; such a goroutine is stopped
+// between games but can't be interrupted within one, so it's left behind.
+func playTimed(lineup []baseball.Player, cfg baseball.GameConfig, games int, r *rand.Rand, limit time.Duration) (runs []int, pa int64, timedOut bool) {
+	runs = make([]int, games)
+	if limit <= 0 {
+		for g := range runs {
+			game := baseball.SimulateGame(lineup, cfg, r)
+			runs[g] = game.Runs
+			pa += int64(game.PA)
+		}
+		return runs, pa, false
+	}
+
+	var (
+		mu      sync.Mutex
+		played  int
+		pas     int64
+		stopped bool
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for g := range runs {
+			game := baseball.SimulateGame(lineup, cfg, r)
+			mu.Lock()
+			if stopped {
+				mu.Unlock()
+				return
+			}
+			runs[g] = game.Runs
+			pas += int64(game.PA)
+			played = g + 1
+			mu.Unlock()
+		}
+	}()
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return runs, pas, false
+	case <-timer.C:
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		return runs[:played], pas, true
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// standing is one team's record in a -round-robin tournament.
+type standing struct {
+	File   string   `json:"file"`
+	ID     string   `json:"id"`
+	Order  []string `json:"order"`
+	Wins   int      `json:"wins"`
+	Losses int      `json:"losses"`
+	Ties   int      `json:"ties,omitempty"`
+	// RunsFor and RunsAgainst are totals over all the team's games.
+	RunsFor     int `json:"runs_for"`
+	RunsAgainst int `json:"runs_against"`
+}
+
+// Pct is the team's winning percentage, ties counting half.
+func (s standing) Pct() float64 {
+	n := s.Wins + s.Losses + s.Ties
+	if n == 0 {
+		return 0
+	}
+	return (float64(s.Wins) + float64(s.Ties)/2) / float64(n)
+}
+
+// Diff is the team's run differential.
+func (s standing) Diff() int {
+	return s.RunsFor - s.RunsAgainst
+}
+
+// roundRobin plays every pair of teams' best lineups against each other
+// for games games, alternating home and away, and returns the standings
+// best first, by winning percentage and then run differential. Pairings run
+// in parallel, each from its own seed derived from seed.
+func roundRobin(teams []teamResult, cfg baseball.GameConfig, games int, seed int64) []standing {
+	table := make([]standing, len(teams))
+	for i, t := range teams {
+		table[i] = standing{File: t.File, ID: t.Best.ID(), Order: t.Best.Order}
+	}
+	type pairing struct{ a, b int }
+	var pairings []pairing
+	for a := range teams {
+		for b := a + 1; b < len(teams); b++ {
+			pairings = append(pairings, pairing{a, b})
+		}
+	}
+
+	var mu sync.Mutex
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for k, p := range pairings {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(k int, p pairing) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			r := rand.New(rand.NewSource(seed + int64(k)*7919))
+			var a standing
+			for g := 0; g < games; g++ {
+				us, them := playMatchup(teams[p.a].Best.lineup, teams[p.b].Best.lineup, g%2 == 0, nil, cfg, r)
+				a.RunsFor += us.Runs
+				a.RunsAgainst += them.Runs
+				switch {
+				case us.Runs > them.Runs:
+					a.Wins++
+				case us.Runs < them.Runs:
+					a.Losses++
+				default:
+					a.Ties++
+				}
+			}
+			b := standing{Wins: a.Losses, Losses: a.Wins, Ties: a.Ties, RunsFor: a.RunsAgainst, RunsAgainst: a.RunsFor}
+			mu.Lock()
+			table[p.a].add(a)
+			table[p.b].add(b)
+			mu.Unlock()
+		}(k, p)
+	}
+	wg.Wait()
+
+	sort.SliceStable(table, func(i, j int) bool {
+		if pi, pj := table[i].Pct(), table[j].Pct(); pi != pj {
+			return pi > pj
+		}
+		return table[i].Diff() > table[j].Diff()
+	})
+	return table
+}
+
+// add adds r's record to s.
+func (s *standing) add(r standing) {
+	s.Wins += r.Wins
+	s.Losses += r.Losses
+	s.Ties += r.Ties
+	s.RunsFor += r.RunsFor
+	s.RunsAgainst += r.RunsAgainst
+}
+
+// writeStandings renders -round-robin standings to w as "text", "json" or
+// "csv".
+func writeStandings(w io.Writer, format string, table []standing, games int) error {
+	switch format {
+	case "text":
+		fmt.Fprintf(w, "Round-robin standings, %d games per pairing:\n", games)
+		fmt.Fprintf(w, "%2s  %-20s %-6s %4s %4s %3s %5s %5s %5s %6s\n", "#", "Team", "ID", "W", "L", "T", "Pct", "RS", "RA", "Diff")
+		for i, s := range table {
+			fmt.Fprintf(w, "%2d) %-20s %-6s %4d %4d %3d %5.3f %5d %5d %+6d  order=%v\n",
+				i+1, s.File, s.ID, s.Wins, s.Losses, s.Ties, s.Pct(), s.RunsFor, s.RunsAgainst, s.Diff(), s.Order)
+		}
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(table)
+	case "csv":
+		cw := csv.NewWriter(w)
+		header := []string{"rank", "file", "id", "wins", "losses", "ties", "pct", "runs_for", "runs_against", "diff"}
+		for i := 1; i <= *lineupSize; i++ {
+			header = append(header, "slot"+strconv.Itoa(i))
+		}
+		cw.Write(header)
+		for i, s := range table {
+			row := []string{
+				strconv.Itoa(i + 1),
+				s.File,
+				s.ID,
+				strconv.Itoa(s.Wins),
+				strconv.Itoa(s.Losses),
+				strconv.Itoa(s.Ties),
+				strconv.FormatFloat(s.Pct(), 'f', 4, 64),
+				strconv.Itoa(s.RunsFor),
+				strconv.Itoa(s.RunsAgainst),
+				strconv.Itoa(s.Diff()),
+			}
+			row = append(row, s.Order...)
+			cw.Write(row)
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestRoundRobinStandingsBalance(t *testing.T) {
+	team := func(file string, obp, slug float64) teamResult {
+		lineup := nineOf(testPlayer(file, obp, slug))
+		return teamResult{File: file, Players: 9, Best: lineupResult{Hash: lineupHash(lineup), lineup: lineup}}
+	}
+	teams := []teamResult{team("weak", 0.260, 0.300), team("strong", 0.400, 0.560), team("middle", 0.330, 0.420)}
+	const games = 40
+	table := roundRobin(teams, baseball.DefaultGameConfig(), games, 1)
+	if len(table) != 3 {
+		t.Fatalf("%d teams in the standings", len(table))
+	}
+
+	// Each team plays both others games times, and every game has one
+	// winner and one loser or two ties.
+	wins, losses, ties, runsFor, runsAgainst := 0, 0, 0, 0, 0
+	for _, s := range table {
+		if n := s.Wins + s.Losses + s.Ties; n != 2*games {
+			t.Errorf("%s played %d games, want %d", s.File, n, 2*games)
+		}
+		wins += s.Wins
+		losses += s.Losses
+		ties += s.Ties
+		runsFor += s.RunsFor
+		runsAgainst += s.RunsAgainst
+	}
+	if wins != losses || wins+ties/2 != 3*games || runsFor != runsAgainst {
+		t.Errorf("standings sum to %d-%d-%d, %d runs for and %d against over %d games", wins, losses, ties, runsFor, runsAgainst, 3*games)
+	}
+	if table[0].File != "strong" || table[2].File != "weak" {
+		t.Errorf("standings order %s, %s, %s", table[0].File, table[1].File, table[2].File)
+	}
+	for i := 1; i < len(table); i++ {
+		if table[i].Pct() > table[i-1].Pct() {
+			t.Errorf("%s (%.3f) ranked below %s (%.3f)", table[i].File, table[i].Pct(), table[i-1].File, table[i-1].Pct())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+// swapDistance is the number of adjacent swaps that turn order a into order
+// b, or -1 when they don't hold the same players.
+func swapDistance(a, b []string) int {
+	if len(a) != len(b) {
+		return -1
+	}
+	pos := make(map[string]int, len(b))
+	for i, name := range b {
+		pos[name] = i
+	}
+	seq := make([]int, len(a))
+	for i, name := range a {
+		j, ok := pos[name]
+		if !ok {
+			return -1
+		}
+		seq[i] = j
+	}
+	// Count inversions; n is nine, so the quadratic loop is fine.
+	d := 0
+	for i := range seq {
+		for j := i + 1; j < len(seq); j++ {
+			if seq[i] > seq[j] {
+				d++
+			}
+		}
+	}
+	return d
+}
+
+// uniqueResults drops every lineup within maxDist adjacent swaps of a better
+// one already kept. results must be sorted best first.
+func uniqueResults(results []lineupResult, maxDist int) []lineupResult {
+	var kept []lineupResult
+	for _, r := range results {
+		dup := false
+		for _, k := range kept {
+			if d := swapDistance(k.Order, r.Order); d >= 0 && d <= maxDist {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestUniqueResultsCollapsesOneSwap(t *testing.T) {
+	results := []lineupResult{
+		{Hash: 1, Score: 5.0, Order: []string{"A", "B", "C", "D"}},
+		{Hash: 2, Score: 4.9, Order: []string{"A", "C", "B", "D"}}, // B and C swapped
+		{Hash: 3, Score: 4.8, Order: []string{"D", "C", "B", "A"}},
+		{Hash: 4, Score: 4.7, Order: []string{"A", "B", "C", "E"}}, // another player
+	}
+	var hashes []uint64
+	for _, r := range uniqueResults(results, 1) {
+		hashes = append(hashes, r.Hash)
+	}
+	if len(hashes) != 3 || hashes[0] != 1 || hashes[1] != 3 || hashes[2] != 4 {
+		t.Errorf("kept %v, want [1 3 4]", hashes)
+	}
+	if got := uniqueResults(results, 0); len(got) != len(results) {
+		t.Errorf("distance 0 kept %d of %d distinct orders", len(got), len(results))
+	}
+	if d := swapDistance(results[0].Order, results[2].Order); d != 6 {
+		t.Errorf("reversing four players is %d swaps, want 6", d)
+	}
+}
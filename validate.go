@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// validateResults checks that every lineup in rep could have come from a
+// search over players with the given lineup size: the right number of
+// distinct players, all on the roster, finite stats, and a hash that
+// matches the order. It returns the first inconsistency found.
+func validateResults(rep report, players []baseball.Player, size int) error {
+	byName := make(map[string]baseball.Player, len(players))
+	dup := make(map[string]bool)
+	for _, p := range players {
+		if _, ok := byName[p.LastName]; ok {
+			dup[p.LastName] = true
+		}
+		byName[p.LastName] = p
+	}
+	for _, list := range []struct {
+		name    string
+		results []lineupResult
+	}{{"top", rep.Top}, {"bottom", rep.Bottom}, {"consistent", rep.Consistent}} {
+		for i, r := range list.results {
+			where := fmt.Sprintf("%s #%d (hash %d)", list.name, i+1, r.Hash)
+			if len(r.Order) != size {
+				return fmt.Errorf("%s has %d players, want %d", where, len(r.Order), size)
+			}
+			lineup := make([]baseball.Player, len(r.Order))
+			seen := make(map[string]bool, len(r.Order))
+			for j, name := range r.Order {
+				if seen[name] {
+					return fmt.Errorf("%s lists %s twice", where, name)
+				}
+				seen[name] = true
+				p, ok := byName[name]
+				if !ok {
+					return fmt.Errorf("%s has %s, who isn't in the players file", where, name)
+				}
+				if dup[name] {
+					return fmt.Errorf("%s has %s, which names more than one player in the players file", where, name)
+				}
+				lineup[j] = p
+			}
+			for _, v := range []struct {
+				name string
+				x    float64
+			}{{"score", r.Score}, {"mean", r.Mean}, {"stddev", r.StdDev}, {"runs_per_pa", r.RunsPerPA}} {
+				if math.IsNaN(v.x) || math.IsInf(v.x, 0) {
+					return fmt.Errorf("%s has a non-finite %s", where, v.name)
+				}
+			}
+			if h := lineupHash(lineup); h != r.Hash {
+				return fmt.Errorf("%s doesn't match its order %v, which hashes to %d", where, r.Order, h)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateResultsFlagsHashMismatch(t *testing.T) {
+	players := testRoster(6)
+	rep := smallReport(t)
+	if err := validateResults(rep, players, 6); err != nil {
+		t.Fatalf("a search's own report failed validation: %v", err)
+	}
+
+	// Swap two names in the second lineup's order but keep its hash.
+	r := &rep.Top[1]
+	r.Order = append([]string(nil), r.Order...)
+	r.Order[0], r.Order[1] = r.Order[1], r.Order[0]
+	err := validateResults(rep, players, 6)
+	if err == nil || !strings.Contains(err.Error(), "top #2") || !strings.Contains(err.Error(), "doesn't match its order") {
+		t.Errorf("mismatched hash and order gave %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+// versusResult compares the top lineup with a named alternative order
+// (-vs) game by game.
+type versusResult struct {
+	Source  string   `json:"source"`
+	ID      string   `json:"id"`
+	Order   []string `json:"order"`
+	TopMean float64  `json:"top_mean"`
+	AltMean float64  `json:"alt_mean"`
+	Games   int      `json:"games"`
+	// Outscore is the chance a random game of the top lineup scores more
+	// than a random game of the alternative, and Tie that they score the
+	// same, comparing every pair of the two run distributions.
+	Outscore float64 `json:"outscore"`
+	Tie      float64 `json:"tie"`
+}
+
+// compareVersus plays top and alt over the same games games from seed and
+// compares their per-game runs directly: every top game against every
+// alternative game.
+func compareVersus(source string, top, alt []baseball.Player, cfg baseball.GameConfig, games int, seed int64) versusResult {
+	v := versusResult{Source: source, ID: fmt.Sprintf("%x", lineupHash(alt))[:6], Games: games}
+	for _, p := range alt {
+		v.Order = append(v.Order, p.LastName)
+	}
+	topRuns, _, _ := playTimed(top, cfg, games, rand.New(rand.NewSource(seed)), 0)
+	altRuns, _, _ := playTimed(alt, cfg, games, rand.New(rand.NewSource(seed)), 0)
+	var topTally, altTally runTally
+	for _, n := range topRuns {
+		topTally.Add(n)
+	}
+	for _, n := range altRuns {
+		altTally.Add(n)
+	}
+	v.TopMean, v.AltMean = topTally.Mean(), altTally.Mean()
+	v.Outscore, v.Tie = outscoreChance(topRuns, altRuns)
+	return v
+}
+
+// outscoreChance returns the share of (a, b) pairs, one game from each,
+// in which a's game scores more and the share in which they tie.
+func outscoreChance(a, b []int) (win, tie float64) {
+	if len(a) == 0 || len(b) == 0 {
+		return 0, 0
+	}
+	sorted := append([]int(nil), b...)
+	sort.Ints(sorted)
+	var wins, ties int
+	for _, n := range a {
+		below := sort.SearchInts(sorted, n)
+		wins += below
+		ties += sort.SearchInts(sorted, n+1) - below
+	}
+	pairs := float64(len(a)) * float64(len(b))
+	return float64(wins) / pairs, float64(ties) / pairs
+}
+
+// writeVersus prints the -vs comparison.
+func writeVersus(w io.Writer, v *versusResult) {
+	fmt.Fprintf(w, "Top lineup vs %s (ID=%s, order=%v) over %d games: %.3f vs %.3f runs; it outscores the alternative in %.1f%% of random game pairs, ties in %.1f%%, and scores less in %.1f%%\n",
+		v.Source, v.ID, v.Order, v.Games, v.TopMean, v.AltMean, 100*v.Outscore, 100*v.Tie, 100*(1-v.Outscore-v.Tie))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	baseball "github.com/genghisjahn/battinglineup/batting"
+)
+
+func TestOutscoreChance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     []int
+		win, tie float64
+	}{
+		{nil, []int{1}, 0, 0},
+		{[]int{3}, []int{1, 3}, 0.5, 0.5},
+		{[]int{0, 5}, []int{1, 2}, 0.5, 0},
+		{[]int{2, 2}, []int{2, 2}, 0, 1},
+	} {
+		if win, tie := outscoreChance(tc.a, tc.b); win != tc.win || tie != tc.tie {
+			t.Errorf("outscoreChance(%v, %v) = %v, %v; want %v, %v", tc.a, tc.b, win, tie, tc.win, tc.tie)
+		}
+	}
+}
+
+func TestDominantLineupUsuallyOutscores(t *testing.T) {
+	strong := nineOf(testPlayer("Slugger", 0.420, 0.600))
+	weak := nineOf(testPlayer("Weak", 0.250, 0.280))
+	v := compareVersus("weak.json", strong, weak, baseball.DefaultGameConfig(), 500, 1)
+	if v.TopMean <= v.AltMean || v.Outscore < 0.8 {
+		t.Errorf("strong lineup (%.2f runs) outscored weak one (%.2f) in %.0f%% of pairs", v.TopMean, v.AltMean, 100*v.Outscore)
+	}
+	if v.Outscore+v.Tie > 1 || v.Games != 500 || len(v.Order) != 9 {
+		t.Errorf("versus result %+v", v)
+	}
+}
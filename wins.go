@@ -0,0 +1,21 @@
+package main
+
+import "fmt"
+
+// seasonWins converts a gain of runs per game into approximate wins over a
+// season of -season-games at -runs-per-win, or 0 when -runs-per-win is off.
+func seasonWins(runsPerGame float64) float64 {
+	if *runsPerWin <= 0 {
+		return 0
+	}
+	return runsPerGame * float64(*seasonGames) / *runsPerWin
+}
+
+// winsText is a run gain's season wins for text output, e.g. " (+1.3 wins
+// over 162 games)", or "" when -runs-per-win is off.
+func winsText(runsPerGame float64) string {
+	if *runsPerWin <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.1f wins over %d games)", seasonWins(runsPerGame), *seasonGames)
+}
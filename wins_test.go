@@ -0,0 +1,24 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSeasonWins(t *testing.T) {
+	if w, s := seasonWins(0.1), winsText(0.1); w != 0 || s != "" {
+		t.Errorf("without -runs-per-win: %v wins, %q", w, s)
+	}
+	withFloat(t, runsPerWin, 10)
+	// +0.1 runs a game over 162 games is 16.2 runs, 1.62 wins.
+	if w := seasonWins(0.1); math.Abs(w-1.62) > 1e-9 {
+		t.Errorf("+0.1 runs a game = %v wins, want 1.62", w)
+	}
+	if s := winsText(-0.1); s != " (-1.6 wins over 162 games)" {
+		t.Errorf("winsText(-0.1) = %q", s)
+	}
+	withInt(t, seasonGames, 60)
+	if w := seasonWins(0.5); math.Abs(w-3) > 1e-9 {
+		t.Errorf("+0.5 runs a game over 60 games = %v wins, want 3", w)
+	}
+}